@@ -0,0 +1,349 @@
+// Copyright (c) 2019, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package testdeep
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/maxatome/go-testdeep/internal/ctxerr"
+	"github.com/maxatome/go-testdeep/internal/util"
+)
+
+const (
+	contextDirRootName = "DIR"
+	envUpdateDir       = "TESTDEEP_UPDATE_DIR"
+)
+
+// DirFile allows to check the mode of a file in CmpDir expected
+// map, in addition to its contents.
+type DirFile struct {
+	Mode    interface{} // Mode is the expected permission bits (ignored if nil)
+	Content interface{} // Content is the expected file content (ignored if nil)
+}
+
+type dirFile struct {
+	mode    os.FileMode
+	content []byte
+}
+
+// readDirFiles returns all regular files under "dir", indexed by
+// their slash-separated path relative to "dir".
+func readDirFiles(dir string) (map[string]dirFile, error) {
+	files := map[string]dirFile{}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		files[filepath.ToSlash(rel)] = dirFile{
+			mode:    info.Mode().Perm(),
+			content: content,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// copyDir copies "src" directory files over "dst" ones. Files of
+// "dst" absent from "src" are never removed, so a misconfigured
+// "dst" cannot be wiped: an error listing them is returned instead.
+func copyDir(dst, src string) error {
+	files, err := readDirFiles(src)
+	if err != nil {
+		return err
+	}
+
+	var stale []string
+	if _, err = os.Stat(dst); err == nil {
+		var dstFiles map[string]dirFile
+		dstFiles, err = readDirFiles(dst)
+		if err != nil {
+			return err
+		}
+		for name := range dstFiles {
+			if _, ok := files[name]; !ok {
+				stale = append(stale, name)
+			}
+		}
+	}
+
+	for name, file := range files {
+		path := filepath.Join(dst, filepath.FromSlash(name))
+		err = os.MkdirAll(filepath.Dir(path), 0755)
+		if err != nil {
+			return err
+		}
+		err = ioutil.WriteFile(path, file.content, file.mode)
+		if err != nil {
+			return err
+		}
+		// WriteFile does not change the mode of an existing file
+		err = os.Chmod(path, file.mode)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(stale) > 0 {
+		sort.Strings(stale)
+		return fmt.Errorf("files absent from got directory, remove them manually: %s",
+			strings.Join(stale, ", "))
+	}
+	return nil
+}
+
+func isText(b []byte) bool {
+	return utf8.Valid(b) && bytes.IndexByte(b, 0) < 0
+}
+
+func cmpDirContent(ctx ctxerr.Context, got []byte, expected interface{}) *ctxerr.Error {
+	switch exp := expected.(type) {
+	case []byte:
+		return deepValueEqual(ctx, reflect.ValueOf(got), reflect.ValueOf(exp))
+
+	case string:
+		if string(got) == exp {
+			return nil
+		}
+		if isText(got) && isText([]byte(exp)) {
+			if diff, ok := util.DiffLines(exp, string(got)); ok {
+				if ctx.BooleanError {
+					return ctxerr.BooleanError
+				}
+				return ctx.CollectError(&ctxerr.Error{
+					Message: "file contents differ (-expected +got)",
+					Summary: ctxerr.NewSummary(diff),
+				})
+			}
+		}
+	}
+	return deepValueEqual(ctx, reflect.ValueOf(string(got)), reflect.ValueOf(expected))
+}
+
+func cmpDirFile(ctx ctxerr.Context, got dirFile, expected interface{}) *ctxerr.Error {
+	file, ok := expected.(DirFile)
+	if !ok {
+		return cmpDirContent(ctx, got.content, expected)
+	}
+
+	if file.Mode != nil {
+		err := deepValueEqual(ctx.AddField("Mode"),
+			reflect.ValueOf(got.mode), reflect.ValueOf(file.Mode))
+		if err != nil {
+			return err
+		}
+	}
+
+	if file.Content != nil {
+		return cmpDirContent(ctx, got.content, file.Content)
+	}
+	return nil
+}
+
+func dirMatch(ctx ctxerr.Context, gotDir string, expected interface{}) *ctxerr.Error {
+	var expectedFiles map[string]interface{}
+
+	switch exp := expected.(type) {
+	case string:
+		files, err := readDirFiles(exp)
+		if err != nil {
+			return &ctxerr.Error{
+				Context: ctx,
+				Message: "cannot read expected directory",
+				Summary: ctxerr.NewSummary(err.Error()),
+			}
+		}
+		expectedFiles = make(map[string]interface{}, len(files))
+		for name, file := range files {
+			expectedFiles[name] = DirFile{
+				Mode:    file.mode,
+				Content: string(file.content),
+			}
+		}
+
+	case map[string]interface{}:
+		expectedFiles = make(map[string]interface{}, len(exp))
+		for name, content := range exp {
+			expectedFiles[filepath.ToSlash(name)] = content
+		}
+
+	default:
+		panic("usage: CmpDir(t, DIRECTORY, DIRECTORY|map[string]interface{}, ...)")
+	}
+
+	gotFiles, err := readDirFiles(gotDir)
+	if err != nil {
+		return &ctxerr.Error{
+			Context: ctx,
+			Message: "cannot read directory",
+			Summary: ctxerr.NewSummary(err.Error()),
+		}
+	}
+
+	names := make([]string, 0, len(expectedFiles))
+	for name := range expectedFiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	res := tdSetResult{
+		Kind: filesSetResult,
+		Sort: true,
+	}
+
+	// Check presence/absence
+	for _, name := range names {
+		_, found := gotFiles[name]
+		if expectedFiles[name] == nil {
+			if found {
+				res.Extra = append(res.Extra, reflect.ValueOf(name))
+			}
+		} else if !found {
+			res.Missing = append(res.Missing, reflect.ValueOf(name))
+		}
+	}
+	for name := range gotFiles {
+		if _, ok := expectedFiles[name]; !ok {
+			res.Extra = append(res.Extra, reflect.ValueOf(name))
+		}
+	}
+
+	if !res.IsEmpty() {
+		err := ctx.CollectError(&ctxerr.Error{
+			Message: "comparing files of directory " + util.ToString(gotDir),
+			Summary: res.Summary(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Check modes and contents
+	for _, name := range names {
+		got, found := gotFiles[name]
+		if !found || expectedFiles[name] == nil {
+			continue
+		}
+
+		err := cmpDirFile(ctx.AddMapKey(name), got, expectedFiles[name])
+		if err != nil {
+			return err
+		}
+	}
+
+	return ctx.MergeErrors()
+}
+
+func cmpDir(ctx ctxerr.Context, t TestingT, gotDir string, expected interface{},
+	args ...interface{}) bool {
+	t.Helper()
+
+	if ctx.Path.Len() == 1 && ctx.Path.String() == contextDefaultRootName {
+		ctx.Path = ctxerr.NewPath(contextDirRootName)
+	}
+
+	// Update mode
+	if expectedDir, ok := expected.(string); ok && os.Getenv(envUpdateDir) != "" {
+		err := copyDir(expectedDir, gotDir)
+		if err == nil {
+			return true
+		}
+
+		formatError(t,
+			ctx.FailureIsFatal,
+			&ctxerr.Error{
+				Context: ctx,
+				Message: "cannot update expected directory " + util.ToString(expectedDir),
+				Summary: ctxerr.NewSummary(err.Error()),
+			},
+			args...)
+		return false
+	}
+
+	err := dirMatch(ctx, gotDir, expected)
+	if err == nil {
+		return true
+	}
+
+	formatError(t, ctx.FailureIsFatal, err, args...)
+	return false
+}
+
+// CmpDir checks the contents of "gotDir" directory against
+// "expected", which can be:
+//   - a string, the path of a directory containing the expected files;
+//   - a map[string]interface{} whose keys are the slash-separated
+//     paths of the expected files, relative to "gotDir".
+//
+// All regular files of "gotDir" are checked: files not expected are
+// reported as extra ones, expected but missing files are reported as
+// missing ones. Empty directories are ignored.
+//
+// When "expected" is a directory, each file content and permission
+// bits have to match. When "expected" is a map, each value can be:
+//   - a string or a []byte, the exact file content;
+//   - a TestDeep operator, applied to the file content as a string;
+//   - a DirFile, to also check the file permission bits;
+//   - nil, meaning that the file must not exist.
+//
+//   CmpDir(t, "out", "testdata/golden") // succeeds
+//
+//   CmpDir(t, "out", map[string]interface{}{
+//     "main.go":         HasPrefix("package main\n"),
+//     "lib/lib.go":      Contains("func Lib("),
+//     "bin/run.sh":      DirFile{Mode: os.FileMode(0755), Content: Ignore()},
+//     "lib/lib_test.go": nil,
+//   }) // succeeds
+//
+// When text contents differ, a line-by-line diff is displayed. Paths
+// are reported relative to DIR, as in:
+//
+//   DIR["lib/lib.go"]: file contents differ
+//
+// If the TESTDEEP_UPDATE_DIR environment variable is set and
+// "expected" is a directory, no comparison is done and the files of
+// "gotDir" are copied over the "expected" ones. It allows to easily
+// regenerate golden directories:
+//
+//   TESTDEEP_UPDATE_DIR=1 go test -run TestGenerator
+//
+// For safety, files of "expected" absent from "gotDir" are never
+// removed: the test fails listing them, so they can be removed by
+// hand.
+//
+// "args..." are optional and allow to name the test. This name is
+// logged as is in case of failure. If len(args) > 1 and the first
+// item of args is a string and contains a '%' rune then fmt.Fprintf
+// is used to compose the name, else args are passed to fmt.Fprint.
+func CmpDir(t TestingT, gotDir string, expected interface{}, args ...interface{}) bool {
+	t.Helper()
+	return cmpDir(newContext(), t, gotDir, expected, args...)
+}
@@ -0,0 +1,157 @@
+// Copyright (c) 2019, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package testdeep_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/maxatome/go-testdeep"
+	"github.com/maxatome/go-testdeep/internal/test"
+)
+
+func writeTree(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCmpDir(t *testing.T) {
+	root, err := ioutil.TempDir("", "testdeep")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root) // nolint: errcheck
+
+	gotDir := filepath.Join(root, "got")
+	writeTree(t, gotDir, map[string]string{
+		"main.go":    "package main\n\nfunc main() {\n}\n",
+		"lib/lib.go": "package lib\n",
+		"bin/data":   "\x00\x01\x02",
+	})
+	if err := os.Chmod(filepath.Join(gotDir, "bin/data"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	//
+	// Against a map
+	test.IsTrue(t, testdeep.CmpDir(t, gotDir, map[string]interface{}{
+		"main.go":     testdeep.HasPrefix("package main\n"),
+		"lib/lib.go":  "package lib\n",
+		"bin/data":    []byte{0, 1, 2},
+		"lib/missing": nil,
+	}))
+
+	test.IsTrue(t, testdeep.CmpDir(t, gotDir, map[string]interface{}{
+		"main.go":    testdeep.Ignore(),
+		"lib/lib.go": testdeep.Lines([]string{"package lib"}),
+		"bin/data": testdeep.DirFile{
+			Mode: testdeep.Between(os.FileMode(0400), os.FileMode(0700)),
+		},
+	}))
+
+	mockT := &test.TestingT{}
+	test.IsFalse(t, testdeep.CmpDir(mockT, gotDir, map[string]interface{}{
+		"main.go":    testdeep.Ignore(),
+		"lib/lib.go": testdeep.Ignore(),
+		"bin/other":  testdeep.Ignore(),
+	}))
+	test.IsTrue(t, strings.Contains(mockT.LastMessage, "DIR: comparing files of directory"))
+	test.IsTrue(t, strings.Contains(mockT.LastMessage, `("bin/other")`))
+	test.IsTrue(t, strings.Contains(mockT.LastMessage, `("bin/data")`))
+
+	mockT = &test.TestingT{}
+	test.IsFalse(t, testdeep.CmpDir(mockT, gotDir, map[string]interface{}{
+		"main.go":    testdeep.Ignore(),
+		"lib/lib.go": testdeep.Ignore(),
+		"bin/data":   nil,
+	}))
+	test.IsTrue(t, strings.Contains(mockT.LastMessage, `("bin/data")`))
+
+	mockT = &test.TestingT{}
+	test.IsFalse(t, testdeep.CmpDir(mockT, gotDir, map[string]interface{}{
+		"main.go":    "package main\n\nfunc main() {\n\tpanic(1)\n}\n",
+		"lib/lib.go": testdeep.Ignore(),
+		"bin/data":   testdeep.DirFile{Mode: os.FileMode(0644)},
+	}))
+	test.IsTrue(t, strings.Contains(mockT.LastMessage, `DIR["bin/data"].Mode: values differ`))
+	test.IsTrue(t, strings.Contains(mockT.LastMessage, `DIR["main.go"]: file contents differ (-expected +got)`))
+	test.IsTrue(t, strings.Contains(mockT.LastMessage, "-\tpanic(1)"))
+
+	mockT = &test.TestingT{}
+	test.IsFalse(t, testdeep.CmpDir(mockT, filepath.Join(root, "unknown"),
+		map[string]interface{}{}))
+	test.IsTrue(t, strings.Contains(mockT.LastMessage, "DIR: cannot read directory"))
+
+	//
+	// Against a directory
+	expectedDir := filepath.Join(root, "expected")
+	writeTree(t, expectedDir, map[string]string{
+		"main.go":    "package main\n\nfunc main() {\n}\n",
+		"lib/lib.go": "package lib\n",
+		"bin/data":   "\x00\x01\x02",
+	})
+
+	mockT = &test.TestingT{}
+	test.IsFalse(t, testdeep.CmpDir(mockT, gotDir, expectedDir))
+	test.IsTrue(t, strings.Contains(mockT.LastMessage, `DIR["bin/data"].Mode: values differ`))
+
+	if err := os.Chmod(filepath.Join(expectedDir, "bin/data"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	test.IsTrue(t, testdeep.CmpDir(t, gotDir, expectedDir))
+
+	mockT = &test.TestingT{}
+	test.IsFalse(t, testdeep.NewT(&test.TestingFT{}).
+		CmpDir(gotDir, filepath.Join(root, "unknown")))
+
+	//
+	// Update mode
+	os.Setenv("TESTDEEP_UPDATE_DIR", "1") // nolint: errcheck
+	updatedDir := filepath.Join(root, "updated")
+	writeTree(t, updatedDir, map[string]string{"main.go": "old", "old": "old"})
+	mockT = &test.TestingT{}
+	ok := testdeep.CmpDir(mockT, gotDir, updatedDir)
+	test.IsFalse(t, ok)
+	test.IsTrue(t, strings.Contains(mockT.LastMessage,
+		"files absent from got directory, remove them manually: old"))
+	// Stale files are kept, others are updated
+	_, err = os.Stat(filepath.Join(updatedDir, "old"))
+	test.IsTrue(t, err == nil)
+	if err = os.Remove(filepath.Join(updatedDir, "old")); err != nil {
+		t.Fatal(err)
+	}
+	ok = testdeep.CmpDir(t, gotDir, updatedDir)
+	os.Unsetenv("TESTDEEP_UPDATE_DIR") // nolint: errcheck
+	if test.IsTrue(t, ok) {
+		test.IsTrue(t, testdeep.CmpDir(t, gotDir, updatedDir))
+	}
+
+	// Missing expected directory is created
+	os.Setenv("TESTDEEP_UPDATE_DIR", "1") // nolint: errcheck
+	createdDir := filepath.Join(root, "created")
+	ok = testdeep.CmpDir(t, gotDir, createdDir)
+	os.Unsetenv("TESTDEEP_UPDATE_DIR") // nolint: errcheck
+	if test.IsTrue(t, ok) {
+		test.IsTrue(t, testdeep.CmpDir(t, gotDir, createdDir))
+	}
+
+	//
+	// Bad usage
+	test.CheckPanic(t, func() { testdeep.CmpDir(t, gotDir, 42) },
+		"usage: CmpDir(")
+}
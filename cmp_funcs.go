@@ -186,6 +186,40 @@ func CmpContainsKey(t TestingT, got interface{}, expectedValue interface{}, args
 	return Cmp(t, got, ContainsKey(expectedValue), args...)
 }
 
+// CmpContainsSeq is a shortcut for:
+//
+//   Cmp(t, got, ContainsSeq(expectedItems...), args...)
+//
+// See https://godoc.org/github.com/maxatome/go-testdeep#ContainsSeq for details.
+//
+// Returns true if the test is OK, false if it fails.
+//
+// "args..." are optional and allow to name the test. This name is
+// logged as is in case of failure. If len(args) > 1 and the first
+// item of args is a string and contains a '%' rune then fmt.Fprintf
+// is used to compose the name, else args are passed to fmt.Fprint.
+func CmpContainsSeq(t TestingT, got interface{}, expectedItems []interface{}, args ...interface{}) bool {
+	t.Helper()
+	return Cmp(t, got, ContainsSeq(expectedItems...), args...)
+}
+
+// CmpCount is a shortcut for:
+//
+//   Cmp(t, got, Count(filter, expectedCount), args...)
+//
+// See https://godoc.org/github.com/maxatome/go-testdeep#Count for details.
+//
+// Returns true if the test is OK, false if it fails.
+//
+// "args..." are optional and allow to name the test. This name is
+// logged as is in case of failure. If len(args) > 1 and the first
+// item of args is a string and contains a '%' rune then fmt.Fprintf
+// is used to compose the name, else args are passed to fmt.Fprint.
+func CmpCount(t TestingT, got interface{}, filter interface{}, expectedCount interface{}, args ...interface{}) bool {
+	t.Helper()
+	return Cmp(t, got, Count(filter, expectedCount), args...)
+}
+
 // CmpEmpty is a shortcut for:
 //
 //   Cmp(t, got, Empty(), args...)
@@ -203,6 +237,40 @@ func CmpEmpty(t TestingT, got interface{}, args ...interface{}) bool {
 	return Cmp(t, got, Empty(), args...)
 }
 
+// CmpFirst is a shortcut for:
+//
+//   Cmp(t, got, First(filter, expectedValue), args...)
+//
+// See https://godoc.org/github.com/maxatome/go-testdeep#First for details.
+//
+// Returns true if the test is OK, false if it fails.
+//
+// "args..." are optional and allow to name the test. This name is
+// logged as is in case of failure. If len(args) > 1 and the first
+// item of args is a string and contains a '%' rune then fmt.Fprintf
+// is used to compose the name, else args are passed to fmt.Fprint.
+func CmpFirst(t TestingT, got interface{}, filter interface{}, expectedValue interface{}, args ...interface{}) bool {
+	t.Helper()
+	return Cmp(t, got, First(filter, expectedValue), args...)
+}
+
+// CmpGrep is a shortcut for:
+//
+//   Cmp(t, got, Grep(filter, expectedValue), args...)
+//
+// See https://godoc.org/github.com/maxatome/go-testdeep#Grep for details.
+//
+// Returns true if the test is OK, false if it fails.
+//
+// "args..." are optional and allow to name the test. This name is
+// logged as is in case of failure. If len(args) > 1 and the first
+// item of args is a string and contains a '%' rune then fmt.Fprintf
+// is used to compose the name, else args are passed to fmt.Fprint.
+func CmpGrep(t TestingT, got interface{}, filter interface{}, expectedValue interface{}, args ...interface{}) bool {
+	t.Helper()
+	return Cmp(t, got, Grep(filter, expectedValue), args...)
+}
+
 // CmpGt is a shortcut for:
 //
 //   Cmp(t, got, Gt(val), args...)
@@ -288,6 +356,23 @@ func CmpIsa(t TestingT, got interface{}, model interface{}, args ...interface{})
 	return Cmp(t, got, Isa(model), args...)
 }
 
+// CmpJSONSchema is a shortcut for:
+//
+//   Cmp(t, got, JSONSchema(schema), args...)
+//
+// See https://godoc.org/github.com/maxatome/go-testdeep#JSONSchema for details.
+//
+// Returns true if the test is OK, false if it fails.
+//
+// "args..." are optional and allow to name the test. This name is
+// logged as is in case of failure. If len(args) > 1 and the first
+// item of args is a string and contains a '%' rune then fmt.Fprintf
+// is used to compose the name, else args are passed to fmt.Fprint.
+func CmpJSONSchema(t TestingT, got interface{}, schema interface{}, args ...interface{}) bool {
+	t.Helper()
+	return Cmp(t, got, JSONSchema(schema), args...)
+}
+
 // CmpKeys is a shortcut for:
 //
 //   Cmp(t, got, Keys(val), args...)
@@ -305,6 +390,23 @@ func CmpKeys(t TestingT, got interface{}, val interface{}, args ...interface{})
 	return Cmp(t, got, Keys(val), args...)
 }
 
+// CmpLast is a shortcut for:
+//
+//   Cmp(t, got, Last(filter, expectedValue), args...)
+//
+// See https://godoc.org/github.com/maxatome/go-testdeep#Last for details.
+//
+// Returns true if the test is OK, false if it fails.
+//
+// "args..." are optional and allow to name the test. This name is
+// logged as is in case of failure. If len(args) > 1 and the first
+// item of args is a string and contains a '%' rune then fmt.Fprintf
+// is used to compose the name, else args are passed to fmt.Fprint.
+func CmpLast(t TestingT, got interface{}, filter interface{}, expectedValue interface{}, args ...interface{}) bool {
+	t.Helper()
+	return Cmp(t, got, Last(filter, expectedValue), args...)
+}
+
 // CmpLen is a shortcut for:
 //
 //   Cmp(t, got, Len(val), args...)
@@ -322,6 +424,23 @@ func CmpLen(t TestingT, got interface{}, val interface{}, args ...interface{}) b
 	return Cmp(t, got, Len(val), args...)
 }
 
+// CmpLines is a shortcut for:
+//
+//   Cmp(t, got, Lines(val), args...)
+//
+// See https://godoc.org/github.com/maxatome/go-testdeep#Lines for details.
+//
+// Returns true if the test is OK, false if it fails.
+//
+// "args..." are optional and allow to name the test. This name is
+// logged as is in case of failure. If len(args) > 1 and the first
+// item of args is a string and contains a '%' rune then fmt.Fprintf
+// is used to compose the name, else args are passed to fmt.Fprint.
+func CmpLines(t TestingT, got interface{}, val interface{}, args ...interface{}) bool {
+	t.Helper()
+	return Cmp(t, got, Lines(val), args...)
+}
+
 // CmpLt is a shortcut for:
 //
 //   Cmp(t, got, Lt(val), args...)
@@ -390,6 +509,40 @@ func CmpMapEach(t TestingT, got interface{}, expectedValue interface{}, args ...
 	return Cmp(t, got, MapEach(expectedValue), args...)
 }
 
+// CmpMax is a shortcut for:
+//
+//   Cmp(t, got, Max(expectedValue, fieldsPath...), args...)
+//
+// See https://godoc.org/github.com/maxatome/go-testdeep#Max for details.
+//
+// Returns true if the test is OK, false if it fails.
+//
+// "args..." are optional and allow to name the test. This name is
+// logged as is in case of failure. If len(args) > 1 and the first
+// item of args is a string and contains a '%' rune then fmt.Fprintf
+// is used to compose the name, else args are passed to fmt.Fprint.
+func CmpMax(t TestingT, got interface{}, expectedValue interface{}, fieldsPath []string, args ...interface{}) bool {
+	t.Helper()
+	return Cmp(t, got, Max(expectedValue, fieldsPath...), args...)
+}
+
+// CmpMin is a shortcut for:
+//
+//   Cmp(t, got, Min(expectedValue, fieldsPath...), args...)
+//
+// See https://godoc.org/github.com/maxatome/go-testdeep#Min for details.
+//
+// Returns true if the test is OK, false if it fails.
+//
+// "args..." are optional and allow to name the test. This name is
+// logged as is in case of failure. If len(args) > 1 and the first
+// item of args is a string and contains a '%' rune then fmt.Fprintf
+// is used to compose the name, else args are passed to fmt.Fprint.
+func CmpMin(t TestingT, got interface{}, expectedValue interface{}, fieldsPath []string, args ...interface{}) bool {
+	t.Helper()
+	return Cmp(t, got, Min(expectedValue, fieldsPath...), args...)
+}
+
 // CmpN is a shortcut for:
 //
 //   Cmp(t, got, N(num, tolerance), args...)
@@ -636,6 +789,23 @@ func CmpReAll(t TestingT, got interface{}, reg interface{}, capture interface{},
 	return Cmp(t, got, ReAll(reg, capture), args...)
 }
 
+// CmpReduce is a shortcut for:
+//
+//   Cmp(t, got, Reduce(fn, init, expectedValue), args...)
+//
+// See https://godoc.org/github.com/maxatome/go-testdeep#Reduce for details.
+//
+// Returns true if the test is OK, false if it fails.
+//
+// "args..." are optional and allow to name the test. This name is
+// logged as is in case of failure. If len(args) > 1 and the first
+// item of args is a string and contains a '%' rune then fmt.Fprintf
+// is used to compose the name, else args are passed to fmt.Fprint.
+func CmpReduce(t TestingT, got interface{}, fn interface{}, init interface{}, expectedValue interface{}, args ...interface{}) bool {
+	t.Helper()
+	return Cmp(t, got, Reduce(fn, init, expectedValue), args...)
+}
+
 // CmpSet is a shortcut for:
 //
 //   Cmp(t, got, Set(expectedItems...), args...)
@@ -704,6 +874,40 @@ func CmpSmuggle(t TestingT, got interface{}, fn interface{}, expectedValue inter
 	return Cmp(t, got, Smuggle(fn, expectedValue), args...)
 }
 
+// CmpSort is a shortcut for:
+//
+//   Cmp(t, got, Sort(keys, expectedValue), args...)
+//
+// See https://godoc.org/github.com/maxatome/go-testdeep#Sort for details.
+//
+// Returns true if the test is OK, false if it fails.
+//
+// "args..." are optional and allow to name the test. This name is
+// logged as is in case of failure. If len(args) > 1 and the first
+// item of args is a string and contains a '%' rune then fmt.Fprintf
+// is used to compose the name, else args are passed to fmt.Fprint.
+func CmpSort(t TestingT, got interface{}, keys interface{}, expectedValue interface{}, args ...interface{}) bool {
+	t.Helper()
+	return Cmp(t, got, Sort(keys, expectedValue), args...)
+}
+
+// CmpSorted is a shortcut for:
+//
+//   Cmp(t, got, Sorted(keys...), args...)
+//
+// See https://godoc.org/github.com/maxatome/go-testdeep#Sorted for details.
+//
+// Returns true if the test is OK, false if it fails.
+//
+// "args..." are optional and allow to name the test. This name is
+// logged as is in case of failure. If len(args) > 1 and the first
+// item of args is a string and contains a '%' rune then fmt.Fprintf
+// is used to compose the name, else args are passed to fmt.Fprint.
+func CmpSorted(t TestingT, got interface{}, keys []string, args ...interface{}) bool {
+	t.Helper()
+	return Cmp(t, got, Sorted(keys...), args...)
+}
+
 // CmpString is a shortcut for:
 //
 //   Cmp(t, got, String(expected), args...)
@@ -789,6 +993,40 @@ func CmpSubSetOf(t TestingT, got interface{}, expectedItems []interface{}, args
 	return Cmp(t, got, SubSetOf(expectedItems...), args...)
 }
 
+// CmpSubsequence is a shortcut for:
+//
+//   Cmp(t, got, Subsequence(expectedItems...), args...)
+//
+// See https://godoc.org/github.com/maxatome/go-testdeep#Subsequence for details.
+//
+// Returns true if the test is OK, false if it fails.
+//
+// "args..." are optional and allow to name the test. This name is
+// logged as is in case of failure. If len(args) > 1 and the first
+// item of args is a string and contains a '%' rune then fmt.Fprintf
+// is used to compose the name, else args are passed to fmt.Fprint.
+func CmpSubsequence(t TestingT, got interface{}, expectedItems []interface{}, args ...interface{}) bool {
+	t.Helper()
+	return Cmp(t, got, Subsequence(expectedItems...), args...)
+}
+
+// CmpSum is a shortcut for:
+//
+//   Cmp(t, got, Sum(expectedValue, fieldsPath...), args...)
+//
+// See https://godoc.org/github.com/maxatome/go-testdeep#Sum for details.
+//
+// Returns true if the test is OK, false if it fails.
+//
+// "args..." are optional and allow to name the test. This name is
+// logged as is in case of failure. If len(args) > 1 and the first
+// item of args is a string and contains a '%' rune then fmt.Fprintf
+// is used to compose the name, else args are passed to fmt.Fprint.
+func CmpSum(t TestingT, got interface{}, expectedValue interface{}, fieldsPath []string, args ...interface{}) bool {
+	t.Helper()
+	return Cmp(t, got, Sum(expectedValue, fieldsPath...), args...)
+}
+
 // CmpSuperBagOf is a shortcut for:
 //
 //   Cmp(t, got, SuperBagOf(expectedItems...), args...)
@@ -840,6 +1078,23 @@ func CmpSuperSetOf(t TestingT, got interface{}, expectedItems []interface{}, arg
 	return Cmp(t, got, SuperSetOf(expectedItems...), args...)
 }
 
+// CmpSuperSliceOf is a shortcut for:
+//
+//   Cmp(t, got, SuperSliceOf(model, expectedEntries), args...)
+//
+// See https://godoc.org/github.com/maxatome/go-testdeep#SuperSliceOf for details.
+//
+// Returns true if the test is OK, false if it fails.
+//
+// "args..." are optional and allow to name the test. This name is
+// logged as is in case of failure. If len(args) > 1 and the first
+// item of args is a string and contains a '%' rune then fmt.Fprintf
+// is used to compose the name, else args are passed to fmt.Fprint.
+func CmpSuperSliceOf(t TestingT, got interface{}, model interface{}, expectedEntries ArrayEntries, args ...interface{}) bool {
+	t.Helper()
+	return Cmp(t, got, SuperSliceOf(model, expectedEntries), args...)
+}
+
 // CmpTruncTime is a shortcut for:
 //
 //   Cmp(t, got, TruncTime(expectedTime, trunc), args...)
@@ -861,6 +1116,23 @@ func CmpTruncTime(t TestingT, got interface{}, expectedTime interface{}, trunc t
 	return Cmp(t, got, TruncTime(expectedTime, trunc), args...)
 }
 
+// CmpUnique is a shortcut for:
+//
+//   Cmp(t, got, Unique(keyFn...), args...)
+//
+// See https://godoc.org/github.com/maxatome/go-testdeep#Unique for details.
+//
+// Returns true if the test is OK, false if it fails.
+//
+// "args..." are optional and allow to name the test. This name is
+// logged as is in case of failure. If len(args) > 1 and the first
+// item of args is a string and contains a '%' rune then fmt.Fprintf
+// is used to compose the name, else args are passed to fmt.Fprint.
+func CmpUnique(t TestingT, got interface{}, keyFn []interface{}, args ...interface{}) bool {
+	t.Helper()
+	return Cmp(t, got, Unique(keyFn...), args...)
+}
+
 // CmpValues is a shortcut for:
 //
 //   Cmp(t, got, Values(val), args...)
@@ -7,7 +7,10 @@
 package testdeep
 
 import (
+	"fmt"
 	"runtime"
+	"strings"
+	"sync"
 
 	"github.com/maxatome/go-testdeep/internal/ctxerr"
 	"github.com/maxatome/go-testdeep/internal/types"
@@ -114,24 +117,115 @@ func CmpNoError(t TestingT, got error, args ...interface{}) bool {
 	return cmpNoError(newContext(), t, got, args...)
 }
 
-func cmpPanic(ctx ctxerr.Context, t TestingT, fn func(), expected interface{}, args ...interface{}) bool {
-	t.Helper()
+// Frame is a stack frame, as recorded in PanicInfo.Stack.
+type Frame struct {
+	Func string // Func is the function name, including its package path
+	File string // File is the source file name, without its directory
+	Line int    // Line is the line number inside File
+}
 
-	if ctx.Path.Len() == 1 && ctx.Path.String() == contextDefaultRootName {
-		ctx.Path = ctxerr.NewPath(contextPanicRootName)
+// PanicInfo describes a panic() caught by CmpPanicInfo and
+// CmpPanicInGoroutine functions.
+type PanicInfo struct {
+	// Value is the panic() parameter, or the runtime.Error raised by
+	// the runtime. It is always nil when panic(nil) has been called.
+	Value interface{}
+	// Nil is true if panic(nil) has been called.
+	Nil bool
+	// Runtime is true if Value is a runtime.Error (out of range index,
+	// nil pointer dereference, integer divide by zero, etc.)
+	Runtime bool
+	// Stack contains the stack frames from the function which
+	// panicked (at index 0) to the function passed to Cmp* function.
+	Stack []Frame
+}
+
+// callers returns the program counters of the function invocations
+// on the calling goroutine's stack, "skip" being the number of stack
+// frames to skip, 0 identifying the caller of callers.
+func callers(skip int) []uintptr {
+	pcs := make([]uintptr, 64)
+	for {
+		n := runtime.Callers(skip+2, pcs)
+		if n < len(pcs) {
+			return pcs[:n]
+		}
+		pcs = make([]uintptr, 2*len(pcs))
 	}
+}
 
-	var (
-		panicked   bool
-		panicParam interface{}
-	)
+func newPanicInfo(panicParam interface{}, pcs []uintptr) PanicInfo {
+	var info PanicInfo
+
+	if isPanicNil(panicParam) {
+		info.Nil = true
+	} else {
+		info.Value = panicParam
+		_, info.Runtime = panicParam.(runtime.Error)
+	}
+
+	frames := runtime.CallersFrames(pcs)
+
+	// First frame is always the deferred function which recovered
+	frames.Next()
 
+	inRuntime := true
+	for {
+		frame, more := frames.Next()
+
+		// Skip runtime.gopanic & co
+		if !inRuntime || !strings.HasPrefix(frame.Function, "runtime.") {
+			inRuntime = false
+
+			file := frame.File
+			if index := strings.LastIndexAny(file, `/\`); index >= 0 {
+				file = file[index+1:]
+			}
+			info.Stack = append(info.Stack, Frame{
+				Func: frame.Function,
+				File: file,
+				Line: frame.Line,
+			})
+		}
+
+		if !more {
+			break
+		}
+	}
+	return info
+}
+
+// catchPanic calls "fn" and returns true if it panicked, with the
+// panic details.
+func catchPanic(fn func()) (panicked bool, info PanicInfo) {
 	func() {
-		defer func() { panicParam = recover() }()
+		// This function + all its callers
+		baseDepth := len(callers(0))
+
+		defer func() {
+			panicParam := recover()
+			if panicked {
+				pcs := callers(0)
+				info = newPanicInfo(panicParam, pcs[:len(pcs)-baseDepth])
+			}
+		}()
 		panicked = true
 		fn()
 		panicked = false
 	}()
+	return
+}
+
+// cmpPanicked reports a failure if "panicked" is false, else compares
+// "got" (the panic() parameter or the whole PanicInfo) against
+// "expected".
+func cmpPanicked(ctx ctxerr.Context, t TestingT, panicked bool,
+	got, expected interface{}, args ...interface{}) bool {
+	t.Helper()
+
+	if ctx.Path.Len() == 1 && ctx.Path.String() == contextDefaultRootName {
+		ctx.Path = ctxerr.NewPath(contextPanicRootName)
+	}
 
 	if !panicked {
 		formatError(t,
@@ -145,7 +239,98 @@ func cmpPanic(ctx ctxerr.Context, t TestingT, fn func(), expected interface{}, a
 		return false
 	}
 
-	return cmpDeeply(ctx.AddCustomLevel("→panic()"), t, panicParam, expected, args...)
+	return cmpDeeply(ctx.AddCustomLevel("→panic()"), t, got, expected, args...)
+}
+
+func cmpPanic(ctx ctxerr.Context, t TestingT, fn func(), expected interface{}, args ...interface{}) bool {
+	t.Helper()
+
+	panicked, info := catchPanic(fn)
+	return cmpPanicked(ctx, t, panicked, info.Value, expected, args...)
+}
+
+func cmpPanicInfo(ctx ctxerr.Context, t TestingT, fn func(), expected interface{}, args ...interface{}) bool {
+	t.Helper()
+
+	panicked, info := catchPanic(fn)
+	return cmpPanicked(ctx, t, panicked, info, expected, args...)
+}
+
+func cmpPanicInGoroutine(ctx ctxerr.Context, t TestingT, fn func(goFn func(func())),
+	expected interface{}, args ...interface{}) bool {
+	t.Helper()
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		panicked bool
+		info     PanicInfo
+	)
+
+	record := func(p bool, i PanicInfo) {
+		if p {
+			mu.Lock()
+			if !panicked {
+				panicked, info = true, i
+			}
+			mu.Unlock()
+		}
+	}
+
+	record(catchPanic(func() {
+		fn(func(goroutine func()) {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				record(catchPanic(goroutine))
+			}()
+		})
+	}))
+	wg.Wait()
+
+	return cmpPanicked(ctx, t, panicked, info.Value, expected, args...)
+}
+
+func cmpNoLeak(ctx ctxerr.Context, t TestingT, snapshot goroutinesSnapshot, args ...interface{}) bool {
+	t.Helper()
+
+	if ctx.Path.Len() == 1 && ctx.Path.String() == contextDefaultRootName {
+		ctx.Path = ctxerr.NewPath(contextPanicRootName)
+	}
+
+	if snapshot.err != nil {
+		formatError(t,
+			ctx.FailureIsFatal,
+			&ctxerr.Error{
+				Context: ctx,
+				Message: "cannot check goroutine leaks",
+				Summary: ctxerr.NewSummary(snapshot.err.Error()),
+			},
+			args...)
+		return false
+	}
+
+	leaked := snapshot.waitLeaked()
+	if len(leaked) == 0 {
+		return true
+	}
+
+	stacks := make([]string, len(leaked))
+	for i, g := range leaked {
+		stacks[i] = g.stack
+	}
+
+	formatError(t,
+		ctx.FailureIsFatal,
+		&ctxerr.Error{
+			Context: ctx,
+			Message: fmt.Sprintf("should NOT leak goroutines (%d still running after %s)",
+				len(leaked), snapshot.gracePeriod),
+			Got:      types.RawString(strings.Join(stacks, "\n\n")),
+			Expected: types.RawString("no goroutine left running"),
+		},
+		args...)
+	return false
 }
 
 func cmpNotPanic(ctx ctxerr.Context, t TestingT, fn func(), args ...interface{}) bool {
@@ -165,6 +350,9 @@ func cmpNotPanic(ctx ctxerr.Context, t TestingT, fn func(), args ...interface{})
 						break
 					}
 				}
+				if isPanicNil(panicParam) {
+					panicParam = nil
+				}
 				stackTrace = types.RawString("panic: " + util.ToString(panicParam) + "\n\n" +
 					string(buf[:n]))
 			}
@@ -200,7 +388,8 @@ func cmpNotPanic(ctx ctxerr.Context, t TestingT, fn func(), args ...interface{})
 // are fulfilled.
 //
 // Note that calling panic(nil) in "fn" body is detected as a panic
-// (in this case "expectedPanic" has to be nil.)
+// (in this case "expectedPanic" has to be nil.) Use CmpPanicInfo to
+// distinguish panic(nil) from other panics.
 //
 // "args..." are optional and allow to name the test. This name is
 // logged as is in case of failure. If len(args) > 1 and the first
@@ -212,6 +401,92 @@ func CmpPanic(t TestingT, fn func(), expectedPanic interface{},
 	return cmpPanic(newContext(), t, fn, expectedPanic, args...)
 }
 
+// CmpPanicInfo calls "fn" and checks a panic() occurred. It then
+// compares the PanicInfo describing this panic against
+// "expectedInfo". It returns true only if both conditions are
+// fulfilled.
+//
+// It allows to check where the panic occurred, thanks to the Stack
+// field of PanicInfo:
+//
+//   CmpPanicInfo(t, func() { MyFunc(-1) },
+//     Struct(PanicInfo{}, StructFields{
+//       "Value": Contains("negative value"),
+//       "Stack": Smuggle(func(s []Frame) string { return s[0].Func },
+//         HasSuffix(".MyFunc")),
+//     }))
+//
+// A panic(nil) call leads to a nil Value field and a true Nil field,
+// so it can be distinguished from any other panic. Panics raised by
+// the runtime (out of range index, nil map write, etc.) have their
+// Runtime field set to true.
+//
+// "args..." are optional and allow to name the test. This name is
+// logged as is in case of failure. If len(args) > 1 and the first
+// item of args is a string and contains a '%' rune then fmt.Fprintf
+// is used to compose the name, else args are passed to fmt.Fprint.
+func CmpPanicInfo(t TestingT, fn func(), expectedInfo interface{},
+	args ...interface{}) bool {
+	t.Helper()
+	return cmpPanicInfo(newContext(), t, fn, expectedInfo, args...)
+}
+
+// CmpPanicInGoroutine calls "fn" and checks a panic() occurred in
+// "fn" or in one of the goroutines it started using "goFn", with the
+// "expectedPanic" parameter. It returns true only if both conditions
+// are fulfilled.
+//
+// A panic occurring in a goroutine cannot be recovered by another
+// one, so "fn" has to start its goroutines using the "goFn"
+// function it receives instead of the go statement. CmpPanicInGoroutine
+// waits for all these goroutines to finish before checking for a
+// panic. If several goroutines panic, only the first recovered panic
+// is compared to "expectedPanic".
+//
+//   CmpPanicInGoroutine(t,
+//     func(goFn func(func())) {
+//       goFn(func() { panic("boom!") })
+//     },
+//     "boom!")
+//
+// "args..." are optional and allow to name the test. This name is
+// logged as is in case of failure. If len(args) > 1 and the first
+// item of args is a string and contains a '%' rune then fmt.Fprintf
+// is used to compose the name, else args are passed to fmt.Fprint.
+func CmpPanicInGoroutine(t TestingT, fn func(goFn func(func())),
+	expectedPanic interface{}, args ...interface{}) bool {
+	t.Helper()
+	return cmpPanicInGoroutine(newContext(), t, fn, expectedPanic, args...)
+}
+
+// CmpNoLeak calls "fn" and checks that all goroutines it started
+// are terminated when it returns. As goroutines can take some time
+// to terminate, it retries during DefaultLeakConfig.GracePeriod
+// before reporting the goroutines still running as leaked, with
+// their stack trace (including where they were created.)
+//
+//   CmpNoLeak(t, func() {
+//     srv := StartServer()
+//     defer srv.Close()
+//     // ...
+//   })
+//
+// Standard runtime and testing goroutines, as well as goroutines
+// matching one of DefaultLeakConfig.Ignore regexps, are never
+// reported. See T.NoGoroutineLeak to use a specific configuration.
+//
+// "args..." are optional and allow to name the test. This name is
+// logged as is in case of failure. If len(args) > 1 and the first
+// item of args is a string and contains a '%' rune then fmt.Fprintf
+// is used to compose the name, else args are passed to fmt.Fprint.
+func CmpNoLeak(t TestingT, fn func(), args ...interface{}) bool {
+	t.Helper()
+
+	snapshot := newGoroutinesSnapshot(DefaultLeakConfig)
+	fn()
+	return cmpNoLeak(newContext(), t, snapshot, args...)
+}
+
 // CmpNotPanic calls "fn" and checks no panic() occurred. If a panic()
 // occurred false is returned then the panic() parameter and the stack
 // trace appear in the test report.
@@ -8,7 +8,15 @@ package testdeep
 
 import (
 	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/internal/test"
 )
 
 func ExampleCmpTrue() {
@@ -133,6 +141,200 @@ func ExampleCmpPanic() {
 	// checks a panic occurred: false
 }
 
+func ExampleCmpPanicInfo() {
+	t := &testing.T{}
+
+	ok := CmpPanicInfo(t,
+		func() { panic("I am panicking!") },
+		Struct(PanicInfo{Value: "I am panicking!"}, nil))
+	fmt.Println("checks panic() value:", ok)
+
+	// Can check panics raised by the runtime, and where they occurred
+	ok = CmpPanicInfo(t,
+		func() {
+			var m map[string]int
+			m["foo"] = 42
+		},
+		Struct(PanicInfo{Runtime: true}, StructFields{
+			"Value": Contains("nil map"),
+			"Stack": Smuggle(
+				func(stack []Frame) string { return stack[0].Func },
+				HasSuffix(".ExampleCmpPanicInfo.func2")),
+		}))
+	fmt.Println("checks runtime panic() and its location:", ok)
+
+	// Can distinguish panic(nil) from other panics
+	ok = CmpPanicInfo(t,
+		func() { panic(nil) },
+		Struct(PanicInfo{Nil: true}, nil))
+	fmt.Println("checks for panic(nil):", ok)
+
+	ok = CmpPanicInfo(t,
+		func() { panic((*int)(nil)) },
+		Struct(PanicInfo{Nil: true}, nil))
+	fmt.Println("checks for panic(nil) with a typed nil:", ok)
+
+	// Of course, do not panic = test failure
+	ok = CmpPanicInfo(t, func() {}, Ignore())
+	fmt.Println("checks a panic occurred:", ok)
+
+	// Output:
+	// checks panic() value: true
+	// checks runtime panic() and its location: true
+	// checks for panic(nil): true
+	// checks for panic(nil) with a typed nil: false
+	// checks a panic occurred: false
+}
+
+func ExampleCmpPanicInGoroutine() {
+	t := &testing.T{}
+
+	ok := CmpPanicInGoroutine(t,
+		func(goFn func(func())) {
+			goFn(func() { panic("I am panicking!") })
+		},
+		"I am panicking!")
+	fmt.Println("checks panic() in a goroutine:", ok)
+
+	// All goroutines are waited for
+	ok = CmpPanicInGoroutine(t,
+		func(goFn func(func())) {
+			goFn(func() {
+				time.Sleep(10 * time.Millisecond)
+				panic("I am late!")
+			})
+		},
+		"I am late!")
+	fmt.Println("checks late panic():", ok)
+
+	// Of course, no goroutine panicking = test failure
+	ok = CmpPanicInGoroutine(t,
+		func(goFn func(func())) {
+			goFn(func() {})
+		},
+		Ignore())
+	fmt.Println("checks a panic occurred:", ok)
+
+	// Output:
+	// checks panic() in a goroutine: true
+	// checks late panic(): true
+	// checks a panic occurred: false
+}
+
+func ExampleCmpNoLeak() {
+	t := &testing.T{}
+
+	ok := CmpNoLeak(t, func() {
+		done := make(chan struct{})
+		go func() { close(done) }()
+		<-done
+	})
+	fmt.Println("no goroutine leaked:", ok)
+
+	// Goroutines finishing a bit after fn returns are not leaked
+	ok = CmpNoLeak(t, func() {
+		go func() { time.Sleep(10 * time.Millisecond) }()
+	})
+	fmt.Println("no goroutine leaked during grace period:", ok)
+
+	// Output:
+	// no goroutine leaked: true
+	// no goroutine leaked during grace period: true
+}
+
+func ExampleCmpDir() {
+	t := &testing.T{}
+
+	dir, err := ioutil.TempDir("", "testdeep")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir) // nolint: errcheck
+
+	// Generates some files
+	os.Mkdir(filepath.Join(dir, "lib"), 0755)                                            // nolint: errcheck
+	ioutil.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0644)      // nolint: errcheck
+	ioutil.WriteFile(filepath.Join(dir, "lib", "lib.go"), []byte("package lib\n"), 0644) // nolint: errcheck
+
+	ok := CmpDir(t, dir, map[string]interface{}{
+		"main.go":    "package main\n",
+		"lib/lib.go": HasPrefix("package lib"),
+	})
+	fmt.Println("checks all files:", ok)
+
+	// nil means the file must not exist
+	ok = CmpDir(t, dir, map[string]interface{}{
+		"main.go":         Ignore(),
+		"lib/lib.go":      Ignore(),
+		"lib/lib_test.go": nil,
+	})
+	fmt.Println("checks lib_test.go does not exist:", ok)
+
+	// Missing files are reported, as well as extra ones
+	ok = CmpDir(t, dir, map[string]interface{}{
+		"main.go": Ignore(),
+	})
+	fmt.Println("checks only main.go exists:", ok)
+
+	// Output:
+	// checks all files: true
+	// checks lib_test.go does not exist: true
+	// checks only main.go exists: false
+}
+
+func ExampleCmpOutput() {
+	t := &testing.T{}
+
+	ok := CmpOutput(t,
+		func() {
+			fmt.Println("Hello!")
+			fmt.Fprintln(os.Stderr, "Beware!")
+		},
+		"Hello!\n", Contains("Beware"))
+	fmt.Println("checks stdout and stderr:", ok)
+
+	// Outputs can be checked line by line, nil expectation ignores output
+	ok = CmpOutput(t,
+		func() {
+			fmt.Println("2 errors")
+			fmt.Println("1 warning")
+		},
+		Lines(Bag("1 warning", Re(`^\d+ errors$`))), nil)
+	fmt.Println("checks stdout lines:", ok)
+
+	// Nothing written on stderr
+	ok = CmpOutput(t, func() { fmt.Println("Hello!") }, nil, "")
+	fmt.Println("checks stderr is empty:", ok)
+
+	// Output:
+	// checks stdout and stderr: true
+	// checks stdout lines: true
+	// checks stderr is empty: true
+}
+
+func ExampleCmpLog() {
+	t := &testing.T{}
+
+	logger := log.New(os.Stderr, "app: ", 0)
+
+	ok := CmpLog(t, logger,
+		func() {
+			logger.Println("start")
+			logger.Println("end")
+		},
+		Lines([]string{"app: start", "app: end"}))
+	fmt.Println("checks logged lines:", ok)
+
+	ok = CmpLog(t, logger,
+		func() { logger.Printf("%d items processed", 12) },
+		Re(`^app: \d+ items`))
+	fmt.Println("checks logs using Re operator:", ok)
+
+	// Output:
+	// checks logged lines: true
+	// checks logs using Re operator: true
+}
+
 func ExampleCmpNotPanic() {
 	t := &testing.T{}
 
@@ -153,3 +355,48 @@ func ExampleCmpNotPanic() {
 	// still no panic? false
 	// last no panic? false
 }
+
+func panicker() {
+	panic("boom!")
+}
+
+func TestCmpPanicInfo(t *testing.T) {
+	var info PanicInfo
+	ok := CmpPanicInfo(t, func() { panicker() },
+		Code(func(i PanicInfo) bool { info = i; return true }))
+	if !ok {
+		return
+	}
+
+	test.EqualStr(t, info.Value.(string), "boom!")
+	test.IsFalse(t, info.Nil)
+	test.IsFalse(t, info.Runtime)
+	if test.EqualInt(t, len(info.Stack), 2, "panicker + fn only") {
+		test.IsTrue(t, strings.HasSuffix(info.Stack[0].Func, ".panicker"))
+		test.EqualStr(t, info.Stack[0].File, "cmp_funcs_misc_test.go")
+		test.IsTrue(t, strings.HasSuffix(info.Stack[1].Func, ".TestCmpPanicInfo.func1"))
+	}
+
+	// Runtime error
+	ok = CmpPanicInfo(t, func() { _ = []int{}[len(info.Stack)] },
+		Code(func(i PanicInfo) bool { info = i; return true }))
+	if ok {
+		test.IsTrue(t, info.Runtime)
+		test.EqualInt(t, len(info.Stack), 1)
+	}
+
+	// Failure
+	mockT := &test.TestingT{}
+	test.IsFalse(t, CmpPanicInfo(mockT, func() {}, Ignore()))
+	test.IsTrue(t, strings.Contains(mockT.LastMessage, "FUNCTION: should have panicked"))
+
+	mockT = &test.TestingT{}
+	test.IsFalse(t, CmpPanicInfo(mockT, panicker, Struct(PanicInfo{Nil: true}, nil)))
+	test.IsTrue(t, strings.Contains(mockT.LastMessage, "FUNCTION→panic().Nil: values differ"))
+
+	mockT = &test.TestingT{}
+	test.IsFalse(t, CmpPanicInGoroutine(mockT,
+		func(goFn func(func())) { goFn(panicker) },
+		"bam!"))
+	test.IsTrue(t, strings.Contains(mockT.LastMessage, "FUNCTION→panic(): values differ"))
+}
@@ -0,0 +1,214 @@
+// Copyright (c) 2019, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package testdeep
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"os"
+
+	"github.com/maxatome/go-testdeep/internal/ctxerr"
+)
+
+const (
+	contextStdoutRootName = "STDOUT"
+	contextStderrRootName = "STDERR"
+	contextLogRootName    = "LOG"
+)
+
+// capturePipe redirects the writes done on *file to a pipe, whose
+// content is accumulated in a buffer.
+type capturePipe struct {
+	file *os.File
+	orig *os.File
+	r, w *os.File
+	buf  bytes.Buffer
+	done chan struct{}
+}
+
+func newCapturePipe(file **os.File) (*capturePipe, error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+
+	p := &capturePipe{
+		orig: *file,
+		r:    r,
+		w:    w,
+		done: make(chan struct{}),
+	}
+	go func() {
+		io.Copy(&p.buf, r) // nolint: errcheck
+		close(p.done)
+	}()
+
+	*file = w
+	return p, nil
+}
+
+// restore restores the original *file and returns all that was
+// written in the meantime.
+func (p *capturePipe) restore(file **os.File) string {
+	*file = p.orig
+	p.w.Close()
+	<-p.done
+	p.r.Close()
+	return p.buf.String()
+}
+
+// captureOutput calls "fn" and returns all that it wrote on os.Stdout
+// and os.Stderr. os.Stdout and os.Stderr are restored before
+// returning, even if "fn" panics.
+func captureOutput(fn func()) (stdout, stderr string, err error) {
+	outPipe, err := newCapturePipe(&os.Stdout)
+	if err != nil {
+		return
+	}
+	defer func() { stdout = outPipe.restore(&os.Stdout) }()
+
+	errPipe, err := newCapturePipe(&os.Stderr)
+	if err != nil {
+		return
+	}
+	defer func() { stderr = errPipe.restore(&os.Stderr) }()
+
+	fn()
+	return
+}
+
+// captureLog calls "fn" and returns all that was logged by "logger"
+// in the meantime. If "logger" is nil, the standard logger is used.
+// The original output of "logger" is restored before returning, even
+// if "fn" panics.
+func captureLog(logger *log.Logger, fn func()) string {
+	var buf bytes.Buffer
+
+	if logger == nil {
+		orig := log.Writer()
+		log.SetOutput(&buf)
+		defer log.SetOutput(orig)
+	} else {
+		orig := logger.Writer()
+		logger.SetOutput(&buf)
+		defer logger.SetOutput(orig)
+	}
+
+	fn()
+	return buf.String()
+}
+
+// newOutputContext returns a new context using "config", whose root
+// name defaults to "rootName".
+func newOutputContext(config ContextConfig, rootName string) ctxerr.Context {
+	if config.RootName == "" || config.RootName == contextDefaultRootName {
+		config.RootName = rootName
+	}
+	return newContextWithConfig(config)
+}
+
+func cmpOutput(config ContextConfig, t TestingT, fn func(),
+	expectedStdout, expectedStderr interface{}, args ...interface{}) bool {
+	t.Helper()
+
+	stdout, stderr, err := captureOutput(fn)
+	if err != nil {
+		ctx := newOutputContext(config, contextStdoutRootName)
+		formatError(t,
+			ctx.FailureIsFatal,
+			&ctxerr.Error{
+				Context: ctx,
+				Message: "cannot capture output",
+				Summary: ctxerr.NewSummary(err.Error()),
+			},
+			args...)
+		return false
+	}
+
+	ok := true
+	if expectedStdout != nil {
+		ok = cmpDeeply(newOutputContext(config, contextStdoutRootName),
+			t, stdout, expectedStdout, args...)
+	}
+	if expectedStderr != nil {
+		ok = cmpDeeply(newOutputContext(config, contextStderrRootName),
+			t, stderr, expectedStderr, args...) && ok
+	}
+	return ok
+}
+
+func cmpLog(config ContextConfig, t TestingT, logger *log.Logger, fn func(),
+	expected interface{}, args ...interface{}) bool {
+	t.Helper()
+	return cmpDeeply(newOutputContext(config, contextLogRootName),
+		t, captureLog(logger, fn), expected, args...)
+}
+
+// CmpOutput calls "fn" and checks that all it wrote on os.Stdout
+// matches "expectedStdout" and all it wrote on os.Stderr matches
+// "expectedStderr". Both are compared as strings and can be
+// TestDeep operators, typically Re, Contains or Lines. A nil
+// expectation means the corresponding output is not checked, use ""
+// or Empty() to check that nothing was written.
+//
+//   CmpOutput(t, func() { fmt.Println("Hello!") },
+//     "Hello!\n", "") // succeeds
+//
+//   CmpOutput(t, func() {
+//     fmt.Fprintln(os.Stderr, "2 errors")
+//     fmt.Fprintln(os.Stderr, "1 warning")
+//   },
+//     nil, Lines(Bag("1 warning", Re(`^\d+ errors$`)))) // succeeds
+//
+// During "fn" call, os.Stdout and os.Stderr are redirected to pipes,
+// so "fn" has not to be modified to be tested. They are always
+// restored before CmpOutput returns, even if "fn" panics. Note that
+// the standard logger as well as all loggers created before the
+// call keep writing to the original os.Stderr, use CmpLog to check
+// them.
+//
+// In case of failure, the root of the reported path is STDOUT or
+// STDERR instead of DATA.
+//
+// "args..." are optional and allow to name the test. This name is
+// logged as is in case of failure. If len(args) > 1 and the first
+// item of args is a string and contains a '%' rune then fmt.Fprintf
+// is used to compose the name, else args are passed to fmt.Fprint.
+func CmpOutput(t TestingT, fn func(), expectedStdout, expectedStderr interface{},
+	args ...interface{}) bool {
+	t.Helper()
+	return cmpOutput(DefaultContextConfig, t, fn, expectedStdout, expectedStderr, args...)
+}
+
+// CmpLog calls "fn" and checks that all "logger" logged in the
+// meantime matches "expected". If "logger" is nil, the standard
+// logger is used. The logs are compared as a string and "expected"
+// can be a TestDeep operator, typically Re, Contains or Lines.
+//
+//   logger := log.New(os.Stderr, "", 0)
+//   CmpLog(t, logger, func() {
+//     logger.Println("start")
+//     logger.Println("end")
+//   },
+//     Lines([]string{"start", "end"})) // succeeds
+//
+// The output of "logger" is always restored before CmpLog returns,
+// even if "fn" panics.
+//
+// In case of failure, the root of the reported path is LOG instead
+// of DATA.
+//
+// "args..." are optional and allow to name the test. This name is
+// logged as is in case of failure. If len(args) > 1 and the first
+// item of args is a string and contains a '%' rune then fmt.Fprintf
+// is used to compose the name, else args are passed to fmt.Fprint.
+func CmpLog(t TestingT, logger *log.Logger, fn func(), expected interface{},
+	args ...interface{}) bool {
+	t.Helper()
+	return cmpLog(DefaultContextConfig, t, logger, fn, expected, args...)
+}
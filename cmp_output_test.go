@@ -0,0 +1,94 @@
+// Copyright (c) 2019, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package testdeep_test
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/maxatome/go-testdeep"
+	"github.com/maxatome/go-testdeep/internal/test"
+)
+
+func TestCmpOutput(t *testing.T) {
+	origStdout, origStderr := os.Stdout, os.Stderr
+
+	fn := func() {
+		fmt.Println("out1")
+		fmt.Fprintln(os.Stderr, "err1")
+		fmt.Println("out2")
+	}
+
+	test.IsTrue(t, testdeep.CmpOutput(t, fn, "out1\nout2\n", "err1\n"))
+	test.IsTrue(t, testdeep.CmpOutput(t, fn,
+		testdeep.Lines(testdeep.Bag("out2", "out1")), nil))
+	test.IsTrue(t, testdeep.CmpOutput(t, func() {}, "", testdeep.Empty()))
+
+	// Big outputs do not block
+	test.IsTrue(t, testdeep.CmpOutput(t,
+		func() { fmt.Print(strings.Repeat("x", 1<<20)) },
+		testdeep.Len(1<<20), ""))
+
+	mockT := &test.TestingT{}
+	test.IsFalse(t, testdeep.CmpOutput(mockT, fn, "out1\n", nil))
+	test.IsTrue(t, strings.Contains(mockT.LastMessage, "STDOUT: values differ"))
+
+	mockT = &test.TestingT{}
+	test.IsFalse(t, testdeep.CmpOutput(mockT, fn,
+		nil, testdeep.Lines([]string{"err2"})))
+	test.IsTrue(t, strings.Contains(mockT.LastMessage, "lines(STDERR)[0]: values differ"))
+
+	mockFT := &test.TestingFT{}
+	test.IsFalse(t, testdeep.NewT(mockFT).RootName("OUTPUT").CmpOutput(fn, "out1\n", nil))
+	test.IsTrue(t, strings.Contains(mockFT.LastMessage, "OUTPUT: values differ"))
+
+	// Restored even if fn panics
+	test.CheckPanic(t,
+		func() {
+			testdeep.CmpOutput(t, func() { fmt.Println("out"); panic("boom") }, nil, nil)
+		},
+		"boom")
+
+	test.IsTrue(t, os.Stdout == origStdout, "os.Stdout is restored")
+	test.IsTrue(t, os.Stderr == origStderr, "os.Stderr is restored")
+}
+
+func TestCmpLog(t *testing.T) {
+	var out strings.Builder
+	logger := log.New(&out, "", 0)
+
+	fn := func() {
+		logger.Println("line1")
+		logger.Print("line2")
+	}
+
+	test.IsTrue(t, testdeep.CmpLog(t, logger, fn, "line1\nline2\n"))
+	test.IsTrue(t, testdeep.CmpLog(t, logger, fn,
+		testdeep.Lines(testdeep.Bag("line2", "line1"))))
+
+	mockT := &test.TestingT{}
+	test.IsFalse(t, testdeep.CmpLog(mockT, logger, fn, testdeep.Lines(testdeep.Len(3))))
+	test.IsTrue(t, strings.Contains(mockT.LastMessage, "lines(LOG): bad length"))
+
+	// Restored even if fn panics
+	test.CheckPanic(t,
+		func() {
+			testdeep.CmpLog(t, logger, func() { logger.Print("in"); panic("boom") }, nil)
+		},
+		"boom")
+	logger.Print("out")
+	test.EqualStr(t, out.String(), "out\n")
+
+	// Standard logger
+	origWriter := log.Writer()
+	test.IsTrue(t, testdeep.CmpLog(t, nil, func() { log.Print("std") },
+		testdeep.HasSuffix(" std\n")))
+	test.IsTrue(t, log.Writer() == origWriter, "standard logger is restored")
+}
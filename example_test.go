@@ -672,6 +672,60 @@ func ExampleContainsKey_nil() {
 	// map contains *byte nil key: false
 }
 
+func ExampleContainsSeq() {
+	t := &testing.T{}
+
+	events := []string{"start", "login", "buy", "logout", "stop"}
+
+	ok := Cmp(t, events, ContainsSeq("login", "buy", "logout"))
+	fmt.Println("login, buy then logout in a row:", ok)
+
+	ok = Cmp(t, events, ContainsSeq("login", "logout"))
+	fmt.Println("login then logout in a row:", ok)
+
+	ok = Cmp(t, []int{1, 15, 2, 18}, ContainsSeq(Gt(10), Lt(10)))
+	fmt.Println("a number greater than 10 followed by a lesser one:", ok)
+
+	ok = Cmp(t, "Hello World!", ContainsSeq('W', 'o'))
+	fmt.Println("'W' followed by 'o':", ok)
+
+	// Output:
+	// login, buy then logout in a row: true
+	// login then logout in a row: false
+	// a number greater than 10 followed by a lesser one: true
+	// 'W' followed by 'o': true
+}
+
+func ExampleCount() {
+	t := &testing.T{}
+
+	got := []int{-3, -2, -1, 0, 1, 2, 3}
+
+	ok := Cmp(t, got, Count(Gt(0), 3))
+	fmt.Println("3 positive numbers:", ok)
+
+	ok = Cmp(t, got, Count(Between(-1, 1), Between(2, 5)))
+	fmt.Println("between 2 and 5 numbers in [-1 .. 1]:", ok)
+
+	isEven := func(x int) bool { return x%2 == 0 }
+
+	ok = Cmp(t, got, Count(isEven, 3))
+	fmt.Println("3 even numbers:", ok)
+
+	ok = Cmp(t, "Hello World!", Count('o', 2))
+	fmt.Println("2 'o' in string:", ok)
+
+	ok = Cmp(t, map[string]int{"a": 1, "b": 15, "c": 2}, Count(Gt(10), 2))
+	fmt.Println("2 map values greater than 10:", ok)
+
+	// Output:
+	// 3 positive numbers: true
+	// between 2 and 5 numbers in [-1 .. 1]: true
+	// 3 even numbers: true
+	// 2 'o' in string: true
+	// 2 map values greater than 10: false
+}
+
 func ExampleEmpty() {
 	t := &testing.T{}
 
@@ -755,6 +809,80 @@ func ExampleEmpty_pointers() {
 	// false
 }
 
+func ExampleFirst() {
+	t := &testing.T{}
+
+	got := []int{-3, -2, -1, 0, 1, 2, 3}
+
+	ok := Cmp(t, got, First(Gt(0), 1))
+	fmt.Println("first positive number is 1:", ok)
+
+	isEven := func(x int) bool { return x%2 == 0 }
+
+	ok = Cmp(t, got, First(isEven, -2))
+	fmt.Println("first even number is -2:", ok)
+
+	ok = Cmp(t, got, First(Gt(3), Gt(3)))
+	fmt.Println("first number greater than 3 is found:", ok)
+
+	// Output:
+	// first positive number is 1: true
+	// first even number is -2: true
+	// first number greater than 3 is found: false
+}
+
+func ExampleFlatten() {
+	t := &testing.T{}
+
+	got := []int{3, 1, 2}
+
+	expected := []int{1, 2, 3}
+	ok := Cmp(t, got, Bag(Flatten(expected)))
+	fmt.Println("same items as expected:", ok)
+
+	ok = Cmp(t, got, Set(Flatten(expected[:2]), 3))
+	fmt.Println("mixed with other items:", ok)
+
+	ok = Cmp(t, 2, Any(Flatten(map[string]int{"a": 2, "b": 4})))
+	fmt.Println("2 is one of the map values:", ok)
+
+	// Output:
+	// same items as expected: true
+	// mixed with other items: true
+	// 2 is one of the map values: true
+}
+
+func ExampleGrep() {
+	t := &testing.T{}
+
+	type Order struct {
+		Status string
+		Amount int
+	}
+
+	got := []Order{
+		{Status: "pending", Amount: 5},
+		{Status: "paid", Amount: 12},
+		{Status: "paid", Amount: 18},
+	}
+
+	ok := Cmp(t, []int{-3, -2, -1, 0, 1, 2, 3}, Grep(Gt(0), []int{1, 2, 3}))
+	fmt.Println("positive numbers:", ok)
+
+	isPaid := func(o Order) bool { return o.Status == "paid" }
+
+	ok = Cmp(t, got, Grep(isPaid, ArrayEach(Smuggle("Amount", Gt(10)))))
+	fmt.Println("all paid orders amount is greater than 10:", ok)
+
+	ok = Cmp(t, got, Grep(Smuggle("Status", "paid"), Len(2)))
+	fmt.Println("there are 2 paid orders:", ok)
+
+	// Output:
+	// positive numbers: true
+	// all paid orders amount is greater than 10: true
+	// there are 2 paid orders: true
+}
+
 func ExampleGt_int() {
 	t := &testing.T{}
 
@@ -888,6 +1016,43 @@ func ExampleIsa_interface() {
 	// true
 }
 
+func ExampleJSONSchema() {
+	t := &testing.T{}
+
+	type Person struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+
+	schema := `{
+    "type": "object",
+    "properties": {
+      "name": {"type": "string", "minLength": 1},
+      "age":  {"type": "integer", "minimum": 0}
+    },
+    "required": ["name", "age"]
+  }`
+
+	got := Person{Name: "Bob", Age: 42}
+	ok := Cmp(t, got, JSONSchema(schema))
+	fmt.Println("Bob respects the schema:", ok)
+
+	// Raw JSON documents are validated as is
+	doc := json.RawMessage(`{"name":"Alice","age":-1}`)
+	ok = Cmp(t, doc, JSONSchema(schema))
+	fmt.Println("Alice respects the schema:", ok)
+
+	// As well as any value marshaled to JSON
+	brian := map[string]interface{}{"name": "Brian"}
+	ok = Cmp(t, brian, JSONSchema(schema))
+	fmt.Println("Brian respects the schema:", ok)
+
+	// Output:
+	// Bob respects the schema: true
+	// Alice respects the schema: false
+	// Brian respects the schema: false
+}
+
 func ExampleKeys() {
 	t := &testing.T{}
 
@@ -916,6 +1081,28 @@ func ExampleKeys() {
 	// Each key is 3 bytes long: true
 }
 
+func ExampleLast() {
+	t := &testing.T{}
+
+	got := []int{-3, -2, -1, 0, 1, 2, 3}
+
+	ok := Cmp(t, got, Last(Lt(0), -1))
+	fmt.Println("last negative number is -1:", ok)
+
+	isEven := func(x int) bool { return x%2 == 0 }
+
+	ok = Cmp(t, got, Last(isEven, 2))
+	fmt.Println("last even number is 2:", ok)
+
+	ok = Cmp(t, got, Last(Lt(-3), Lt(-3)))
+	fmt.Println("last number lesser than -3 is found:", ok)
+
+	// Output:
+	// last negative number is -1: true
+	// last even number is 2: true
+	// last number lesser than -3 is found: false
+}
+
 func ExampleLen_slice() {
 	t := &testing.T{}
 
@@ -994,6 +1181,35 @@ func ExampleLen_operatorMap() {
 	// true
 }
 
+func ExampleLines() {
+	t := &testing.T{}
+
+	got := "Once upon a time,\nthere was\na gopher.\n"
+
+	ok := Cmp(t, got,
+		Lines([]string{"Once upon a time,", "there was", "a gopher."}))
+	fmt.Println("All lines are found in order:", ok)
+
+	// Unordered lines can be checked using Bag operator
+	ok = Cmp(t, got, Lines(Bag("a gopher.", "there was", Re(`^Once`))))
+	fmt.Println("All lines are found, with the help of Bag operator:", ok)
+
+	// Count lines
+	ok = Cmp(t, got, Lines(Len(3)))
+	fmt.Println("There are 3 lines:", ok)
+
+	// Works with errors and fmt.Stringer too
+	err := errors.New("1st error\n2nd error")
+	ok = Cmp(t, err, Lines(ArrayEach(HasSuffix(" error"))))
+	fmt.Println("Each error line ends with \" error\":", ok)
+
+	// Output:
+	// All lines are found in order: true
+	// All lines are found, with the help of Bag operator: true
+	// There are 3 lines: true
+	// Each error line ends with " error": true
+}
+
 func ExampleLt_int() {
 	t := &testing.T{}
 
@@ -1128,6 +1344,26 @@ func ExampleMap_typedMap() {
 	// true
 }
 
+func ExampleMap_operatorKeys() {
+	t := &testing.T{}
+
+	got := map[string]int{"id": 42, "tmp-a": 1, "tmp-b": 2}
+
+	ok := Cmp(t, got,
+		Map(map[string]int{"id": 42}, MapEntries{Re("^tmp-"): Between(1, 2)}),
+		"checks map %v", got)
+	fmt.Println(ok)
+
+	ok = Cmp(t, got,
+		SuperMapOf(map[string]int{}, MapEntries{Re("^tmp-"): Lt(2)}),
+		"checks map %v", got)
+	fmt.Println(ok)
+
+	// Output:
+	// true
+	// false
+}
+
 func ExampleMapEach_map() {
 	t := &testing.T{}
 
@@ -1161,6 +1397,65 @@ func ExampleMapEach_typedMap() {
 	// true
 }
 
+func ExampleMax() {
+	t := &testing.T{}
+
+	type Order struct {
+		ID     int
+		Amount float64
+	}
+
+	got := []Order{
+		{ID: 1, Amount: 12.5},
+		{ID: 2, Amount: 99.9},
+		{ID: 3, Amount: 7.5},
+	}
+
+	ok := Cmp(t, []int{3, 8, 2}, Max(8))
+	fmt.Println("max of ints is 8:", ok)
+
+	ok = Cmp(t, got, Max(Lt(100.0), "Amount"))
+	fmt.Println("no amount reaches 100:", ok)
+
+	ok = Cmp(t, map[string]time.Duration{"a": time.Second, "b": time.Minute},
+		Max(time.Minute))
+	fmt.Println("max duration is 1 minute:", ok)
+
+	// Output:
+	// max of ints is 8: true
+	// no amount reaches 100: true
+	// max duration is 1 minute: true
+}
+
+func ExampleMin() {
+	t := &testing.T{}
+
+	type Event struct {
+		Name string
+		Date time.Time
+	}
+
+	start := time.Date(2019, time.March, 1, 0, 0, 0, 0, time.UTC)
+	got := []Event{
+		{Name: "launch", Date: start.Add(2 * time.Hour)},
+		{Name: "landing", Date: start.Add(5 * time.Hour)},
+	}
+
+	ok := Cmp(t, []int{3, 8, 2}, Min(2))
+	fmt.Println("min of ints is 2:", ok)
+
+	ok = Cmp(t, got, Min(Gte(start), "Date"))
+	fmt.Println("no event before start:", ok)
+
+	ok = Cmp(t, []string{"pear", "apple", "plum"}, Min("apple"))
+	fmt.Println("min string is apple:", ok)
+
+	// Output:
+	// min of ints is 2: true
+	// no event before start: true
+	// min string is apple: true
+}
+
 func ExampleN() {
 	t := &testing.T{}
 
@@ -1758,6 +2053,29 @@ func ExampleReAll_compiledCaptureComplex() {
 	// false
 }
 
+func ExampleReduce() {
+	t := &testing.T{}
+
+	ok := Cmp(t, []int{1, 2, 3, 4},
+		Reduce(func(acc, n int) int { return acc * n }, 1, 24))
+	fmt.Println("product is 24:", ok)
+
+	ok = Cmp(t, []string{"a", "b", "c"},
+		Reduce(func(acc, s string) string { return acc + s }, "", "abc"))
+	fmt.Println("concatenation is abc:", ok)
+
+	// Map values are passed sorted by their keys
+	ok = Cmp(t, map[int]string{2: "b", 1: "a"},
+		Reduce(func(acc []string, s string) []string { return append(acc, s) },
+			nil, []string{"a", "b"}))
+	fmt.Println("values sorted by keys:", ok)
+
+	// Output:
+	// product is 24: true
+	// concatenation is abc: true
+	// values sorted by keys: true
+}
+
 func ExampleSet() {
 	t := &testing.T{}
 
@@ -1901,6 +2219,34 @@ func ExampleSlice_typedSlice() {
 	// true
 }
 
+func ExampleSlice_negativeIndexes() {
+	t := &testing.T{}
+
+	got := []int{42, 58, 26, 12}
+
+	ok := Cmp(t, got,
+		Slice([]int{42}, ArrayEntries{
+			1:  ArrayRange{From: 1, To: -2, Expected: Between(20, 60)},
+			-1: 12,
+		}),
+		"checks slice %v", got)
+	fmt.Println(ok)
+
+	// Whatever the length of the slice is
+	got = []int{42, 12}
+	ok = Cmp(t, got,
+		Slice([]int{42}, ArrayEntries{
+			1:  ArrayRange{From: 1, To: -2, Expected: Between(20, 60)},
+			-1: 12,
+		}),
+		"checks slice %v", got)
+	fmt.Println(ok)
+
+	// Output:
+	// true
+	// true
+}
+
 func ExampleSmuggle_convert() {
 	t := &testing.T{}
 
@@ -2137,6 +2483,74 @@ func ExampleSmuggle_field_path() {
 	// check Num using an other fields-path: true
 }
 
+func ExampleSort() {
+	t := &testing.T{}
+
+	type Person struct {
+		Name string
+		Age  int
+	}
+
+	got := []Person{
+		{Name: "Bob", Age: 42},
+		{Name: "Alice", Age: 24},
+		{Name: "Brian", Age: 42},
+	}
+
+	ok := Cmp(t, []int{3, 1, 2}, Sort(nil, []int{1, 2, 3}))
+	fmt.Println("sorted ints:", ok)
+
+	ok = Cmp(t, got, Sort("Name", []Person{
+		{Name: "Alice", Age: 24},
+		{Name: "Bob", Age: 42},
+		{Name: "Brian", Age: 42},
+	}))
+	fmt.Println("sorted by name:", ok)
+
+	ok = Cmp(t, got,
+		Sort([]string{"-Age", "-Name"},
+			Smuggle(func(persons []Person) string { return persons[0].Name }, "Brian")))
+	fmt.Println("oldest, then last by name, is Brian:", ok)
+
+	// Output:
+	// sorted ints: true
+	// sorted by name: true
+	// oldest, then last by name, is Brian: true
+}
+
+func ExampleSorted() {
+	t := &testing.T{}
+
+	type Person struct {
+		Name string
+		Age  int
+	}
+
+	got := []Person{
+		{Name: "Bob", Age: 42},
+		{Name: "Brian", Age: 42},
+		{Name: "Alice", Age: 24},
+	}
+
+	ok := Cmp(t, []int{1, 2, 2, 5}, Sorted())
+	fmt.Println("ascending:", ok)
+
+	ok = Cmp(t, []int{5, 2, 2, 1}, Sorted("-"))
+	fmt.Println("descending:", ok)
+
+	ok = Cmp(t, got, Sorted("-Age", "Name"))
+	fmt.Println("older first, then by name:", ok)
+
+	ok = Cmp(t, got, Sorted("Name"))
+	fmt.Println("by name:", ok)
+
+	// Output:
+	// ascending: true
+	// descending: true
+	// older first, then by name: true
+	// by name: false
+}
+
 func ExampleString() {
 	t := &testing.T{}
 
@@ -2308,6 +2722,50 @@ func ExampleStruct() {
 	// true
 }
 
+func ExampleStruct_patterns() {
+	t := &testing.T{}
+
+	type Person struct {
+		Firstname string
+		Lastname  string
+		Surname   string
+		CreatedAt time.Time
+		UpdatedAt time.Time
+	}
+
+	now := time.Now()
+	got := Person{
+		Firstname: "Maxime",
+		Lastname:  "Foo",
+		Surname:   "Max",
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	ok := Cmp(t, got,
+		Struct(Person{Firstname: "Maxime"}, StructFields{
+			"=*At":     Lte(time.Now()),
+			`~name\z`:  Re(`^[A-Z][a-z]+\z`),
+			"Lastname": "Foo",
+		}),
+		"mix of explicit names and patterns")
+	fmt.Println(ok)
+
+	// Globs are applied before regexps: CreatedAt and UpdatedAt are
+	// not checked against the regexp pattern
+	ok = Cmp(t, got,
+		Struct(Person{}, StructFields{
+			"=*At": Ignore(),
+			"~.":   NotZero(),
+		}),
+		"all fields are set")
+	fmt.Println(ok)
+
+	// Output:
+	// true
+	// true
+}
+
 func ExampleSubBagOf() {
 	t := &testing.T{}
 
@@ -2397,6 +2855,58 @@ func ExampleSubSetOf() {
 	// true
 }
 
+func ExampleSubsequence() {
+	t := &testing.T{}
+
+	events := []string{"start", "login", "buy", "logout", "stop"}
+
+	ok := Cmp(t, events, Subsequence("start", "buy", "stop"))
+	fmt.Println("start, buy then stop:", ok)
+
+	ok = Cmp(t, events, Subsequence("start", "stop", "buy"))
+	fmt.Println("start, stop then buy:", ok)
+
+	ok = Cmp(t, events, Subsequence(HasPrefix("log"), "buy", HasPrefix("log")))
+	fmt.Println("buy between two log* events:", ok)
+
+	ok = Cmp(t, "Hello World!", Subsequence('H', 'W', '!'))
+	fmt.Println("'H', 'W' then '!':", ok)
+
+	// Output:
+	// start, buy then stop: true
+	// start, stop then buy: false
+	// buy between two log* events: true
+	// 'H', 'W' then '!': true
+}
+
+func ExampleSum() {
+	t := &testing.T{}
+
+	type Order struct {
+		ID     int
+		Amount float64
+	}
+
+	got := []Order{
+		{ID: 1, Amount: 12.5},
+		{ID: 2, Amount: 7.5},
+	}
+
+	ok := Cmp(t, []int{1, 2, 3}, Sum(6))
+	fmt.Println("sum of ints is 6:", ok)
+
+	ok = Cmp(t, got, Sum(Between(19.0, 21.0), "Amount"))
+	fmt.Println("total amount is about 20:", ok)
+
+	ok = Cmp(t, []time.Duration{time.Second, time.Minute}, Sum(61*time.Second))
+	fmt.Println("total duration is 61s:", ok)
+
+	// Output:
+	// sum of ints is 6: true
+	// total amount is about 20: true
+	// total duration is 61s: true
+}
+
 func ExampleSuperBagOf() {
 	t := &testing.T{}
 
@@ -2470,6 +2980,32 @@ func ExampleSuperSetOf() {
 	// true
 }
 
+func ExampleSuperSliceOf() {
+	t := &testing.T{}
+
+	got := []int{42, 58, 26, 12}
+
+	ok := Cmp(t, got, SuperSliceOf([]int{42}, ArrayEntries{-1: 12}),
+		"checks first and last items of %v", got)
+	fmt.Println(ok)
+
+	ok = Cmp(t, got,
+		SuperSliceOf([]int{}, ArrayEntries{
+			1: ArrayRange{From: 1, To: 2, Expected: Between(20, 60)},
+		}),
+		"checks 2nd and 3rd items of %v", got)
+	fmt.Println(ok)
+
+	ok = Cmp(t, got, SuperSliceOf([]int{42, 0, 0, 0, 12}, nil),
+		"checks %v has at least 5 items", got)
+	fmt.Println(ok)
+
+	// Output:
+	// true
+	// true
+	// false
+}
+
 func ExampleTruncTime() {
 	t := &testing.T{}
 
@@ -2507,6 +3043,39 @@ func ExampleTruncTime() {
 	// true
 }
 
+func ExampleUnique() {
+	t := &testing.T{}
+
+	type User struct {
+		ID    int
+		Email string
+	}
+
+	users := []User{
+		{ID: 1, Email: "bob@example.com"},
+		{ID: 2, Email: "Alice@example.com"},
+		{ID: 3, Email: "alice@example.com"},
+	}
+
+	ok := Cmp(t, []int{1, 2, 3}, Unique())
+	fmt.Println("distinct numbers:", ok)
+
+	ok = Cmp(t, []int{1, 2, 1}, Unique())
+	fmt.Println("distinct numbers with duplicates:", ok)
+
+	ok = Cmp(t, users, Unique("ID"))
+	fmt.Println("distinct IDs:", ok)
+
+	ok = Cmp(t, users, Unique(func(u User) int { return u.ID % 2 }))
+	fmt.Println("distinct ID parities:", ok)
+
+	// Output:
+	// distinct numbers: true
+	// distinct numbers with duplicates: false
+	// distinct IDs: true
+	// distinct ID parities: false
+}
+
 func ExampleValues() {
 	t := &testing.T{}
 
@@ -0,0 +1,48 @@
+// Copyright (c) 2019, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package testdeep
+
+import (
+	"reflect"
+
+	"github.com/maxatome/go-testdeep/internal/flat"
+)
+
+// Flatten allows to flatten any slice, array or map in parameters of
+// operators expecting ...interface{}: All, Any, Bag, ContainsSeq,
+// None, NotAny, Set, SubBagOf, SubSetOf, Subsequence, SuperBagOf and
+// SuperSetOf.
+//
+// For example the Set operator:
+//
+//   Set(Flatten([]int{1, 2, 3}))
+//
+// is exactly the same as:
+//
+//   Set(1, 2, 3)
+//
+// Flatten can be mixed with other items:
+//
+//   Bag(0, Flatten([]int{1, 2}), Flatten(map[string]int{"a": 3, "b": 4}))
+//
+// is the same as:
+//
+//   Bag(0, 1, 2, 3, 4)
+//
+// For maps, only values are used, sorted by their keys. If an item of
+// "sliceOrMap" is itself a Flatten result, it is recursively
+// flattened. Other slices, arrays or maps items are kept as is.
+//
+// Flatten panics if "sliceOrMap" is not a slice, an array or a map.
+func Flatten(sliceOrMap interface{}) flat.Slice {
+	switch reflect.ValueOf(sliceOrMap).Kind() {
+	case reflect.Array, reflect.Slice, reflect.Map:
+		return flat.Slice{Slice: sliceOrMap}
+	default:
+		panic("usage: Flatten(SLICE|ARRAY|MAP)")
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2019, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package testdeep_test
+
+import (
+	"testing"
+
+	"github.com/maxatome/go-testdeep"
+	"github.com/maxatome/go-testdeep/internal/test"
+)
+
+func TestFlatten(t *testing.T) {
+	got := []int{1, 2, 3, 4}
+
+	checkOK(t, got, testdeep.Bag(testdeep.Flatten([]int{4, 3, 2, 1})))
+	checkOK(t, got, testdeep.Bag(1, testdeep.Flatten([]int{2, 3}), 4))
+	checkOK(t, got, testdeep.Set(testdeep.Flatten([4]int{4, 3, 2, 1})))
+	checkOK(t, got, testdeep.SubSetOf(testdeep.Flatten([]int{1, 2, 3, 4, 5})))
+	checkOK(t, got,
+		testdeep.SuperBagOf(testdeep.Flatten(map[string]int{"a": 1, "b": 3})))
+	checkOK(t, got, testdeep.NotAny(testdeep.Flatten([]int{5, 6})))
+	checkOK(t, got, testdeep.Subsequence(testdeep.Flatten([]int{1, 3})))
+	checkOK(t, got, testdeep.ContainsSeq(testdeep.Flatten([]int{2, 3})))
+	checkOK(t, 3, testdeep.Any(testdeep.Flatten([]int{1, 2, 3})))
+	checkOK(t, 3, testdeep.None(testdeep.Flatten([]int{1, 2})))
+	checkOK(t, 3, testdeep.All(testdeep.Flatten([]interface{}{
+		testdeep.Gt(2),
+		testdeep.Lt(4),
+	})))
+
+	// Empty
+	checkOK(t, []int{}, testdeep.Bag(testdeep.Flatten([]int{})))
+	checkOK(t, []int{}, testdeep.Set(testdeep.Flatten([]int(nil))))
+
+	// Recursive
+	checkOK(t, got, testdeep.Bag(testdeep.Flatten([]interface{}{
+		1,
+		testdeep.Flatten([]int{2, 3}),
+		testdeep.Flatten(map[int]int{1: 4}),
+	})))
+
+	// Nested slices are not flattened
+	checkOK(t, [][]int{{1, 2}, {3}},
+		testdeep.Bag(testdeep.Flatten([][]int{{3}, {1, 2}})))
+
+	checkError(t, got, testdeep.Bag(testdeep.Flatten([]int{1, 2, 3})),
+		expectedError{
+			Message: mustBe("comparing %% as a Bag"),
+			Path:    mustBe("DATA"),
+			Summary: mustContain("4"),
+		})
+
+	//
+	// String
+	test.EqualStr(t,
+		testdeep.Bag(0, testdeep.Flatten([]int{1, 2})).String(),
+		"Bag(0,\n    1,\n    2)")
+	test.EqualStr(t,
+		testdeep.Any(testdeep.Flatten([]int{1, 2})).String(),
+		"Any(1,\n    2)")
+
+	//
+	// Bad usage
+	test.CheckPanic(t, func() { testdeep.Flatten(42) },
+		"usage: Flatten(SLICE|ARRAY|MAP)")
+	test.CheckPanic(t, func() { testdeep.Flatten(nil) },
+		"usage: Flatten(SLICE|ARRAY|MAP)")
+}
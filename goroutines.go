@@ -0,0 +1,195 @@
+// Copyright (c) 2019, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package testdeep
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LeakConfig allows to configure how goroutine leaks are detected by
+// CmpNoLeak function and T.NoGoroutineLeak & T.CmpNoLeak methods.
+type LeakConfig struct {
+	// GracePeriod is the maximum duration to wait for the goroutines
+	// started in the meantime to terminate, before reporting them as
+	// leaked. It defaults to DefaultLeakConfig.GracePeriod if 0 (1
+	// second if not overridden). A negative value means no wait.
+	GracePeriod time.Duration
+	// Ignore is a list of regexps. A goroutine whose stack trace
+	// matches one of them is never reported as leaked. They are
+	// matched in multi-line mode, so ^ and $ match at the beginning
+	// and end of each stack trace line. An invalid regexp makes the
+	// leak check fail. Standard runtime, testing and time.AfterFunc
+	// goroutines are always ignored.
+	Ignore []string
+}
+
+// DefaultLeakConfig is the default configuration used to detect
+// goroutine leaks. If overridden, new settings will impact CmpNoLeak
+// function and T.NoGoroutineLeak & T.CmpNoLeak methods (if not
+// specifically configured.)
+var DefaultLeakConfig = LeakConfig{
+	GracePeriod: time.Second,
+}
+
+// leakStdIgnore contains the standard goroutines never reported as
+// leaked.
+var leakStdIgnore = []*regexp.Regexp{
+	regexp.MustCompile(`(?m)^created by (?:runtime|testing)\.`),
+	regexp.MustCompile(`(?m)^os/signal\.signal_recv`),
+	regexp.MustCompile(`(?m)^runtime\.ensureSigM`),
+	regexp.MustCompile(`(?m)^created by time\.goFunc`),
+}
+
+func (c *LeakConfig) sanitize() {
+	if c.GracePeriod == 0 {
+		c.GracePeriod = DefaultLeakConfig.GracePeriod
+	}
+}
+
+type goroutine struct {
+	id    uint64
+	stack string // whole goroutine dump, header included
+}
+
+// allGoroutines returns all currently running goroutines, except the
+// calling one.
+func allGoroutines() []goroutine {
+	buf := make([]byte, 64*1024)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+
+	var gs []goroutine
+	// First dump is always the calling goroutine one
+	for i, dump := range bytes.Split(buf, []byte("\n\n")) {
+		if i == 0 {
+			continue
+		}
+		if g, ok := parseGoroutine(string(dump)); ok {
+			gs = append(gs, g)
+		}
+	}
+	return gs
+}
+
+// parseGoroutine parses a goroutine dump as produced by runtime.Stack:
+//
+//   goroutine 42 [chan receive]:
+//   main.worker()
+//   	/path/to/main.go:12 +0x25
+//   created by main.main in goroutine 1
+//   	/path/to/main.go:8 +0x1f
+func parseGoroutine(dump string) (goroutine, bool) {
+	dump = strings.TrimSpace(dump)
+
+	const prefix = "goroutine "
+	if !strings.HasPrefix(dump, prefix) {
+		return goroutine{}, false
+	}
+
+	end := strings.IndexByte(dump[len(prefix):], ' ')
+	if end < 0 {
+		return goroutine{}, false
+	}
+
+	id, err := strconv.ParseUint(dump[len(prefix):len(prefix)+end], 10, 64)
+	if err != nil {
+		return goroutine{}, false
+	}
+	return goroutine{id: id, stack: dump}, true
+}
+
+// goroutinesSnapshot records the IDs of running goroutines, to be
+// able to detect, later, new ones.
+type goroutinesSnapshot struct {
+	known       map[uint64]bool
+	ignore      []*regexp.Regexp
+	gracePeriod time.Duration
+	err         error // set if an Ignore regexp is invalid
+}
+
+func newGoroutinesSnapshot(config LeakConfig) goroutinesSnapshot {
+	config.sanitize()
+
+	s := goroutinesSnapshot{
+		known:       map[uint64]bool{},
+		ignore:      make([]*regexp.Regexp, 0, len(leakStdIgnore)+len(config.Ignore)),
+		gracePeriod: config.GracePeriod,
+	}
+
+	s.ignore = append(s.ignore, leakStdIgnore...)
+	for _, re := range config.Ignore {
+		cre, err := regexp.Compile(`(?m)` + re)
+		if err != nil {
+			s.err = fmt.Errorf("bad Ignore regexp `%s': %s", re, err)
+			return s
+		}
+		s.ignore = append(s.ignore, cre)
+	}
+
+	for _, g := range allGoroutines() {
+		s.known[g.id] = true
+	}
+	return s
+}
+
+// leaked returns the goroutines started since the snapshot creation,
+// still running and not ignored.
+func (s goroutinesSnapshot) leaked() (leaked []goroutine) {
+nextGoroutine:
+	for _, g := range allGoroutines() {
+		if s.known[g.id] {
+			continue
+		}
+		for _, re := range s.ignore {
+			if re.MatchString(g.stack) {
+				continue nextGoroutine
+			}
+		}
+		leaked = append(leaked, g)
+	}
+	return
+}
+
+// waitLeaked waits at most the grace period for goroutines started
+// since the snapshot creation to terminate. It returns the ones still
+// running after this delay.
+func (s goroutinesSnapshot) waitLeaked() []goroutine {
+	deadline := time.Now().Add(s.gracePeriod)
+	delay := time.Millisecond
+
+	for {
+		leaked := s.leaked()
+		if len(leaked) == 0 {
+			return nil
+		}
+
+		remain := time.Until(deadline)
+		if remain <= 0 {
+			return leaked
+		}
+
+		if delay > remain {
+			delay = remain
+		}
+		time.Sleep(delay)
+		if delay < 100*time.Millisecond {
+			delay *= 2
+		}
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2019, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package testdeep
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/internal/test"
+)
+
+func TestParseGoroutine(t *testing.T) {
+	g, ok := parseGoroutine(`
+goroutine 42 [chan receive]:
+main.worker()
+	/path/to/main.go:12 +0x25
+created by main.main in goroutine 1
+	/path/to/main.go:8 +0x1f
+`)
+	if test.IsTrue(t, ok) {
+		test.EqualInt(t, int(g.id), 42)
+		test.IsTrue(t, strings.HasPrefix(g.stack, "goroutine 42 [chan receive]:\n"))
+		test.IsTrue(t, strings.HasSuffix(g.stack, "main.go:8 +0x1f"))
+	}
+
+	for _, dump := range []string{
+		"",
+		"foobar",
+		"goroutine 42",
+		"goroutine xx [running]:",
+	} {
+		_, ok = parseGoroutine(dump)
+		test.IsFalse(t, ok, dump)
+	}
+}
+
+func TestGoroutinesSnapshot(t *testing.T) {
+	stop := make(chan struct{})
+	defer close(stop)
+
+	config := LeakConfig{GracePeriod: -1}
+
+	snapshot := newGoroutinesSnapshot(config)
+	test.EqualInt(t, len(snapshot.waitLeaked()), 0)
+
+	go func() { <-stop }()
+	leaked := snapshot.waitLeaked()
+	if test.EqualInt(t, len(leaked), 1) {
+		test.IsTrue(t, strings.Contains(leaked[0].stack, "created by "))
+		test.IsTrue(t, strings.Contains(leaked[0].stack, "TestGoroutinesSnapshot"))
+	}
+
+	// Already running goroutines are not reported
+	test.EqualInt(t, len(newGoroutinesSnapshot(config).waitLeaked()), 0)
+
+	// Ignored goroutines are not reported
+	config.Ignore = []string{`TestGoroutinesSnapshot`}
+	test.EqualInt(t, len(newGoroutinesSnapshot(config).leaked()), 0)
+	snapshot = newGoroutinesSnapshot(config)
+	go func() { <-stop }()
+	test.EqualInt(t, len(snapshot.waitLeaked()), 0)
+
+	// Anchored patterns match at the beginning of any stack line
+	config.Ignore = []string{`^created by github\.com/maxatome/go-testdeep\.TestGoroutinesSnapshot`}
+	snapshot = newGoroutinesSnapshot(config)
+	go func() { <-stop }()
+	test.EqualInt(t, len(snapshot.waitLeaked()), 0)
+
+	// Goroutines terminating during the grace period are not reported
+	snapshot = newGoroutinesSnapshot(LeakConfig{GracePeriod: time.Second})
+	go func() { time.Sleep(10 * time.Millisecond) }()
+	test.EqualInt(t, len(snapshot.waitLeaked()), 0)
+}
+
+func TestCmpNoLeak(t *testing.T) {
+	stop := make(chan struct{})
+	defer close(stop)
+
+	mockT := &test.TestingT{}
+	snapshot := newGoroutinesSnapshot(LeakConfig{GracePeriod: 10 * time.Millisecond})
+	go func() { <-stop }()
+	test.IsFalse(t, cmpNoLeak(newContext(), mockT, snapshot, "my test"))
+	test.IsTrue(t, strings.Contains(mockT.LastMessage, "Failed test 'my test'"))
+	test.IsTrue(t, strings.Contains(mockT.LastMessage,
+		"FUNCTION: should NOT leak goroutines (1 still running after 10ms)"))
+	test.IsTrue(t, strings.Contains(mockT.LastMessage, "created by "))
+	test.IsTrue(t, strings.Contains(mockT.LastMessage, "no goroutine left running"))
+
+	// Invalid Ignore regexp
+	mockT = &test.TestingT{}
+	snapshot = newGoroutinesSnapshot(LeakConfig{Ignore: []string{`(`}})
+	test.IsFalse(t, cmpNoLeak(newContext(), mockT, snapshot))
+	test.IsTrue(t, strings.Contains(mockT.LastMessage,
+		"FUNCTION: cannot check goroutine leaks"))
+	test.IsTrue(t, strings.Contains(mockT.LastMessage, "bad Ignore regexp `('"))
+
+	// time.AfterFunc goroutines are ignored
+	mockT = &test.TestingT{}
+	snapshot = newGoroutinesSnapshot(LeakConfig{GracePeriod: -1})
+	running := make(chan struct{})
+	time.AfterFunc(0, func() { close(running); <-stop })
+	<-running
+	test.IsTrue(t, cmpNoLeak(newContext(), mockT, snapshot))
+}
@@ -0,0 +1,217 @@
+// Copyright (c) 2019, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+// Package tdexec provides some functions to easily test commands.
+package tdexec
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	td "github.com/maxatome/go-testdeep"
+	"github.com/maxatome/go-testdeep/helpers/tdutil"
+)
+
+// Command describes the command to run.
+type Command struct {
+	Path    string        // Path is the command to run, looked up in PATH if it contains no path separator
+	Args    []string      // Args are the command arguments, the command name excluded
+	Env     []string      // Env contains "KEY=value" variables added to the current environment
+	Stdin   io.Reader     // Stdin is the command standard input (empty if nil)
+	Dir     string        // Dir is the command working directory (current one if empty)
+	Timeout time.Duration // Timeout is the delay after which the command is killed (no limit if 0)
+}
+
+// String returns the command line as it could be typed in a shell,
+// preceded by its working directory and additional environment, if
+// any.
+func (c Command) String() string {
+	var buf bytes.Buffer
+
+	if c.Dir != "" {
+		buf.WriteString("cd ")
+		buf.WriteString(shellQuote(c.Dir))
+		buf.WriteString(" && ")
+	}
+
+	for _, env := range c.Env {
+		if pos := strings.IndexByte(env, '='); pos > 0 {
+			buf.WriteString(env[:pos+1])
+			buf.WriteString(shellQuote(env[pos+1:]))
+		} else {
+			buf.WriteString(shellQuote(env))
+		}
+		buf.WriteByte(' ')
+	}
+
+	buf.WriteString(shellQuote(c.Path))
+	for _, arg := range c.Args {
+		buf.WriteByte(' ')
+		buf.WriteString(shellQuote(arg))
+	}
+	return buf.String()
+}
+
+// shellQuote quotes s if it contains characters interpreted by a
+// shell.
+func shellQuote(s string) string {
+	if s == "" {
+		return "''"
+	}
+	if strings.IndexFunc(s, func(r rune) bool {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return false
+		}
+		return !strings.ContainsRune("-_./:=,+@%", r)
+	}) < 0 {
+		return s
+	}
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+// Result is used by CmpCommand function to make the command result
+// match easier. Each field, can be a TestDeep operator as well as the
+// exact expected value.
+//
+// Stdout and Stderr are compared as string, except if the expected
+// value is a []byte. See testdeep.Lines operator to compare them
+// line by line.
+type Result struct {
+	ExitCode interface{} // ExitCode is the expected exit code (ignored if nil)
+	Stdout   interface{} // Stdout is the expected standard output (expected to be empty if nil)
+	Stderr   interface{} // Stderr is the expected standard error (expected to be empty if nil)
+}
+
+// run runs the command and returns its outputs and exit code. The
+// exit code is -1 if the command has been killed. err is non-nil only
+// if the command cannot be started or has timed out.
+func (c Command) run() (stdout, stderr []byte, exitCode int, err error) {
+	ctx := context.Background()
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, c.Path, c.Args...)
+	cmd.Dir = c.Dir
+	if len(c.Env) > 0 {
+		cmd.Env = append(os.Environ(), c.Env...)
+	}
+	cmd.Stdin = c.Stdin
+
+	var outBuf, errBuf bytes.Buffer
+	cmd.Stdout = &outBuf
+	cmd.Stderr = &errBuf
+
+	err = cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		err = ctx.Err()
+	} else if _, ok := err.(*exec.ExitError); ok {
+		err = nil
+	}
+
+	exitCode = -1
+	if cmd.ProcessState != nil {
+		exitCode = cmd.ProcessState.ExitCode()
+	}
+	return outBuf.Bytes(), errBuf.Bytes(), exitCode, err
+}
+
+// cmpOutput compares an output against expected. If expected is a
+// []byte, output is compared as is, else it is converted to string.
+func cmpOutput(t *td.T, output []byte, expected interface{}, name string) bool {
+	t.Helper()
+
+	// nil = no output expected
+	if expected == nil {
+		return t.Empty(string(output), name+" should be empty")
+	}
+
+	if _, ok := expected.([]byte); ok {
+		return t.Cmp(output, expected, name+" should match")
+	}
+	return t.Cmp(string(output), expected, name+" should match")
+}
+
+// CmpCommand runs cmd and checks its exit code, standard output and
+// standard error against expectedResult.
+//
+// If cmd cannot be started or does not end before cmd.Timeout, the
+// test fails. In case of failure, the command line is logged.
+//
+//   tdexec.CmpCommand(t,
+//     tdexec.Command{
+//       Path:  "./myprog",
+//       Args:  []string{"-v", "--count", "2"},
+//       Stdin: strings.NewReader("input"),
+//     },
+//     tdexec.Result{
+//       ExitCode: 0,
+//       Stdout:   td.Lines(td.Bag("1 input", "2 input")),
+//       Stderr:   td.Contains("verbose mode"),
+//     })
+//
+// It returns true if the tests succeed, false otherwise.
+func CmpCommand(tt td.TestingFT, cmd Command, expectedResult Result,
+	args ...interface{}) bool {
+	tt.Helper()
+
+	if testName := tdutil.BuildTestName(args...); testName != "" {
+		tt.Log(testName)
+	}
+
+	t := td.NewT(tt) // nolint: vetshadow
+
+	stdout, stderr, exitCode, err := cmd.run()
+
+	success := t.RootName("Command").CmpNoError(err, "command should run")
+	if success {
+		// Check exit code, nil = ignore
+		if expectedResult.ExitCode != nil {
+			success = t.RootName("Command.ExitCode").
+				Cmp(exitCode, expectedResult.ExitCode, "exit code should match")
+		}
+
+		success = cmpOutput(t.RootName("Command.Stdout"),
+			stdout, expectedResult.Stdout, "stdout") && success
+		success = cmpOutput(t.RootName("Command.Stderr"),
+			stderr, expectedResult.Stderr, "stderr") && success
+	}
+
+	if !success {
+		t.Logf("Command: %s", cmd)
+		if exitCode >= 0 {
+			t.Logf("Exit code: %d", exitCode)
+		}
+	}
+	return success
+}
+
+// CmpCommandFunc returns a function ready to be used with
+// testing.Run, calling CmpCommand behind the scene. As it is intended
+// to be used in conjunction with testing.Run() which names the
+// sub-test, the test name part (args...) is voluntary omitted.
+//
+//   t.Run("Subtest name", tdexec.CmpCommandFunc(
+//     tdexec.Command{Path: "./myprog", Args: []string{"-h"}},
+//     tdexec.Result{
+//       ExitCode: 0,
+//       Stdout:   td.HasPrefix("Usage: "),
+//     }))
+func CmpCommandFunc(cmd Command, expectedResult Result) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Helper()
+		CmpCommand(t, cmd, expectedResult)
+	}
+}
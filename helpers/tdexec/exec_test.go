@@ -0,0 +1,162 @@
+// Copyright (c) 2019, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package tdexec_test
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	td "github.com/maxatome/go-testdeep"
+	"github.com/maxatome/go-testdeep/helpers/tdexec"
+	"github.com/maxatome/go-testdeep/internal/test"
+)
+
+func TestMain(m *testing.M) {
+	tdexec.Main(m, map[string]func(){
+		"echo": func() {
+			fmt.Println(strings.Join(os.Args[1:], " "))
+		},
+		"cat": func() {
+			io.Copy(os.Stdout, os.Stdin) // nolint: errcheck
+		},
+		"env": func() {
+			fmt.Println(os.Getenv(os.Args[1]))
+		},
+		"pwd": func() {
+			dir, _ := os.Getwd()
+			fmt.Println(dir)
+		},
+		"fail": func() {
+			fmt.Fprintln(os.Stderr, "failure!")
+			code, _ := strconv.Atoi(os.Args[1])
+			os.Exit(code)
+		},
+		"sleep": func() {
+			time.Sleep(10 * time.Second)
+		},
+	})
+}
+
+// logT records logs, to check the command line is logged.
+type logT struct {
+	test.TestingFT
+	logs []string
+}
+
+func (t *logT) Logf(format string, args ...interface{}) {
+	t.logs = append(t.logs, fmt.Sprintf(format, args...))
+}
+
+func TestCmpCommand(t *testing.T) {
+	td.CmpTrue(t, tdexec.CmpCommand(t,
+		tdexec.MainCommand("echo", "foo", "bar"),
+		tdexec.Result{
+			ExitCode: 0,
+			Stdout:   "foo bar\n",
+		}))
+
+	td.CmpTrue(t, tdexec.CmpCommand(t,
+		tdexec.MainCommand("echo", "foo", "bar"),
+		tdexec.Result{
+			Stdout: []byte("foo bar\n"),
+		}))
+
+	cmd := tdexec.MainCommand("cat")
+	cmd.Stdin = strings.NewReader("line1\nline2\n")
+	td.CmpTrue(t, tdexec.CmpCommand(t, cmd,
+		tdexec.Result{
+			Stdout: td.Lines(td.Bag("line2", "line1")),
+		}))
+
+	cmd = tdexec.MainCommand("env", "TDEXEC_TEST")
+	cmd.Env = append(cmd.Env, "TDEXEC_TEST=foobar")
+	td.CmpTrue(t, tdexec.CmpCommand(t, cmd, tdexec.Result{Stdout: "foobar\n"}))
+
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parent := dir[:strings.LastIndexAny(dir, `/\`)]
+	cmd = tdexec.MainCommand("pwd")
+	cmd.Dir = ".."
+	td.CmpTrue(t, tdexec.CmpCommand(t, cmd, tdexec.Result{Stdout: parent + "\n"}))
+
+	td.CmpTrue(t, tdexec.CmpCommand(t,
+		tdexec.MainCommand("fail", "3"),
+		tdexec.Result{
+			ExitCode: td.Between(1, 5),
+			Stderr:   td.Contains("failure"),
+		}))
+
+	// Failures
+	mockT := &logT{}
+	td.CmpFalse(t, tdexec.CmpCommand(mockT,
+		tdexec.MainCommand("fail", "3"),
+		tdexec.Result{ExitCode: 0, Stderr: td.Ignore()}))
+	td.Cmp(t, mockT.LastMessage, td.Contains("Command.ExitCode: values differ"))
+	td.Cmp(t, mockT.logs, td.Contains(td.Re(`^Command: TDEXEC_MAIN=fail \S+ 3$`)))
+	td.Cmp(t, mockT.logs, td.Contains("Exit code: 3"))
+
+	mockT = &logT{}
+	td.CmpFalse(t, tdexec.CmpCommand(mockT,
+		tdexec.MainCommand("echo", "foo"),
+		tdexec.Result{Stdout: "bar\n"}))
+	td.Cmp(t, mockT.LastMessage, td.Contains("Command.Stdout: values differ"))
+
+	mockT = &logT{}
+	td.CmpFalse(t, tdexec.CmpCommand(mockT,
+		tdexec.MainCommand("fail", "1"),
+		tdexec.Result{}))
+	td.Cmp(t, mockT.LastMessage, td.Contains("Command.Stderr: not empty"))
+
+	mockT = &logT{}
+	cmd = tdexec.MainCommand("sleep")
+	cmd.Timeout = 50 * time.Millisecond
+	td.CmpFalse(t, tdexec.CmpCommand(mockT, cmd, tdexec.Result{}))
+	td.Cmp(t, mockT.LastMessage, td.Contains("deadline exceeded"))
+
+	mockT = &logT{}
+	td.CmpFalse(t, tdexec.CmpCommand(mockT,
+		tdexec.Command{Path: "/does/not/exist"},
+		tdexec.Result{}))
+	td.Cmp(t, mockT.LastMessage, td.Contains("Command: should NOT be an error"))
+	td.Cmp(t, mockT.logs, []string{"Command: /does/not/exist"})
+
+	mockT = &logT{}
+	td.CmpFalse(t, tdexec.CmpCommand(mockT,
+		tdexec.MainCommand("unknown"),
+		tdexec.Result{ExitCode: 0, Stderr: td.Contains("unknown main")}))
+	td.Cmp(t, mockT.LastMessage, td.Contains("Command.ExitCode: values differ"))
+}
+
+func TestCmpCommandFunc(t *testing.T) {
+	t.Run("echo", tdexec.CmpCommandFunc(
+		tdexec.MainCommand("echo", "foo"),
+		tdexec.Result{
+			ExitCode: 0,
+			Stdout:   "foo\n",
+		}))
+}
+
+func TestCommandString(t *testing.T) {
+	td.Cmp(t,
+		tdexec.Command{Path: "prog", Args: []string{"-v", "a b", "it's", ""}}.String(),
+		`prog -v 'a b' 'it'\''s' ''`)
+
+	td.Cmp(t,
+		tdexec.Command{
+			Path: "./prog",
+			Dir:  "/tmp/my dir",
+			Env:  []string{"FOO=bar zip", "X=1"},
+		}.String(),
+		`cd '/tmp/my dir' && FOO='bar zip' X=1 ./prog`)
+}
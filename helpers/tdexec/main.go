@@ -0,0 +1,78 @@
+// Copyright (c) 2019, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package tdexec
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+// envMain is the environment variable used to tell the re-executed
+// test binary which main function to run.
+const envMain = "TDEXEC_MAIN"
+
+// Main is intended to be called by TestMain. It allows the test
+// binary to be re-executed as one of the "mains" functions, so
+// CLI main functions can be tested in-process, without building
+// them separately:
+//
+//   func TestMain(m *testing.M) {
+//     tdexec.Main(m, map[string]func(){
+//       "myprog": main,
+//     })
+//   }
+//
+//   func TestMyProg(t *testing.T) {
+//     tdexec.CmpCommand(t,
+//       tdexec.MainCommand("myprog", "-v"),
+//       tdexec.Result{
+//         ExitCode: 0,
+//         Stdout:   td.HasPrefix("myprog version "),
+//       })
+//   }
+//
+// When the test binary is launched by a Command returned by
+// MainCommand, the corresponding main function is called with
+// os.Args set accordingly, then the process exits with status 0 if
+// the function returns (it can of course call os.Exit by itself). In
+// all other cases, m.Run() is called and the process exits with its
+// result.
+func Main(m *testing.M, mains map[string]func()) {
+	name, ok := os.LookupEnv(envMain)
+	if !ok {
+		os.Exit(m.Run())
+	}
+
+	mainFn := mains[name]
+	if mainFn == nil {
+		fmt.Fprintf(os.Stderr, "tdexec: unknown main %q\n", name)
+		os.Exit(2)
+	}
+
+	os.Unsetenv(envMain) // nolint: errcheck
+	os.Args = append([]string{name}, os.Args[1:]...)
+	mainFn()
+	os.Exit(0)
+}
+
+// MainCommand returns a Command re-executing the current test binary
+// as "name" main function registered by Main, with "args" as
+// arguments. The returned Command can be modified before being used,
+// typically to set its Stdin, Env, Dir or Timeout fields.
+func MainCommand(name string, args ...string) Command {
+	path, err := os.Executable()
+	if err != nil {
+		path = os.Args[0]
+	}
+
+	return Command{
+		Path: path,
+		Args: args,
+		Env:  []string{envMain + "=" + name},
+	}
+}
@@ -0,0 +1,213 @@
+// Copyright (c) 2019, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package tdhttp
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+)
+
+// XMLNode is a generic XML element. It is used by CmpResponse to
+// unmarshal an XML body when the expected body type cannot be
+// guessed.
+type XMLNode struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Content string     `xml:",chardata"`
+	Nodes   []XMLNode  `xml:",any"`
+}
+
+// decoder describes how a response body is unmarshaled.
+type decoder struct {
+	// unmarshal unmarshals the body into the target
+	unmarshal func([]byte, interface{}) error
+	// auto enables the Content-Type based unmarshaling, and the
+	// charset handling of text bodies. If false, the Content-Type is
+	// ignored, as handlers often do not set it (and then get the
+	// sniffed one)
+	auto bool
+}
+
+var (
+	rawDecoder = decoder{
+		unmarshal: unmarshalRaw,
+		auto:      true,
+	}
+	jsonDecoder = decoder{
+		unmarshal: json.Unmarshal,
+	}
+	xmlDecoder = decoder{
+		unmarshal: xml.Unmarshal,
+	}
+	sseDecoder = decoder{
+		unmarshal: unmarshalSSE,
+	}
+	ndjsonDecoder = decoder{
+		unmarshal: unmarshalNDJSON,
+	}
+)
+
+func isJSONMediaType(mediaType string) bool {
+	return mediaType == "application/json" ||
+		mediaType == "text/json" ||
+		strings.HasSuffix(mediaType, "+json")
+}
+
+func isXMLMediaType(mediaType string) bool {
+	return mediaType == "application/xml" ||
+		mediaType == "text/xml" ||
+		strings.HasSuffix(mediaType, "+xml")
+}
+
+// decodeContentEncoding returns "body" decoded according to the
+// Content-Encoding of "header". gzip, deflate and identity encodings
+// are handled.
+func decodeContentEncoding(header http.Header, body []byte) ([]byte, error) {
+	encodings := strings.Split(header.Get("Content-Encoding"), ",")
+
+	// Encodings are listed in the order they were applied
+	for i := len(encodings) - 1; i >= 0; i-- {
+		var (
+			r   io.ReadCloser
+			err error
+		)
+		switch encoding := strings.ToLower(strings.TrimSpace(encodings[i])); encoding {
+		case "", "identity":
+			continue
+		case "gzip", "x-gzip":
+			r, err = gzip.NewReader(bytes.NewReader(body))
+		case "deflate":
+			// deflate should be zlib-wrapped, but some servers send raw
+			// deflate data
+			r, err = zlib.NewReader(bytes.NewReader(body))
+			if err != nil {
+				r, err = flate.NewReader(bytes.NewReader(body)), nil
+			}
+		default:
+			return nil, fmt.Errorf("unsupported Content-Encoding %q", encoding)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s decoding: %s", encodings[i], err)
+		}
+
+		body, err = ioutil.ReadAll(r)
+		r.Close() // nolint: errcheck
+		if err != nil {
+			return nil, fmt.Errorf("%s decoding: %s", encodings[i], err)
+		}
+	}
+	return body, nil
+}
+
+// decodeCharset converts "body" encoded using "charset" to an UTF-8
+// string. Only UTF-8, US-ASCII and ISO-8859-1 charsets are handled.
+func decodeCharset(charset string, body []byte) (string, error) {
+	switch strings.ToLower(charset) {
+	case "", "utf-8", "utf8", "us-ascii", "ascii":
+		return string(body), nil
+
+	case "iso-8859-1", "iso8859-1", "latin1", "l1":
+		var buf bytes.Buffer
+		buf.Grow(len(body))
+		for _, b := range body {
+			if b < utf8.RuneSelf {
+				buf.WriteByte(b)
+			} else {
+				buf.WriteRune(rune(b))
+			}
+		}
+		return buf.String(), nil
+	}
+	return "", fmt.Errorf("unsupported charset %q", charset)
+}
+
+// unmarshaler returns the function to use to unmarshal a body whose
+// header is "header".
+func (d decoder) unmarshaler(header http.Header) func([]byte, interface{}) error {
+	contentType := header.Get("Content-Type")
+	if !d.auto || contentType == "" {
+		return d.unmarshal
+	}
+
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return func([]byte, interface{}) error {
+			return fmt.Errorf("bad Content-Type %q: %s", contentType, err)
+		}
+	}
+
+	isText := strings.HasPrefix(mediaType, "text/")
+	isForm := mediaType == "application/x-www-form-urlencoded"
+
+	return func(body []byte, target interface{}) error {
+		switch t := target.(type) {
+		case *interface{}:
+			switch {
+			case isXMLMediaType(mediaType):
+				var node XMLNode
+				err := xml.Unmarshal(body, &node)
+				*t = node
+				return err
+
+			case isForm:
+				values, err := url.ParseQuery(string(body))
+				*t = values
+				return err
+
+			case isText:
+				str, err := decodeCharset(params["charset"], body)
+				*t = str
+				return err
+			}
+
+		case *string:
+			if isText || params["charset"] != "" {
+				str, err := decodeCharset(params["charset"], body)
+				*t = str
+				return err
+			}
+
+		case *url.Values:
+			if isForm {
+				values, err := url.ParseQuery(string(body))
+				*t = values
+				return err
+			}
+		}
+
+		switch target.(type) {
+		case *string, *[]byte:
+			return d.unmarshal(body, target)
+		}
+
+		switch {
+		case isJSONMediaType(mediaType):
+			return json.Unmarshal(body, target)
+		case isXMLMediaType(mediaType):
+			return xml.Unmarshal(body, target)
+		}
+
+		if _, ok := target.(*interface{}); ok {
+			return d.unmarshal(body, target)
+		}
+		return fmt.Errorf("content-type mismatch: cannot decode %q body into %s",
+			contentType, reflect.TypeOf(target).Elem())
+	}
+}
@@ -0,0 +1,288 @@
+// Copyright (c) 2019, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package tdhttp_test
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+
+	td "github.com/maxatome/go-testdeep"
+	"github.com/maxatome/go-testdeep/helpers/tdhttp"
+)
+
+func compress(t *testing.T, encoding string, data []byte) []byte {
+	t.Helper()
+
+	var (
+		buf bytes.Buffer
+		w   io.WriteCloser
+	)
+	switch encoding {
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "zlib":
+		w = zlib.NewWriter(&buf)
+	case "flate":
+		var err error
+		w, err = flate.NewWriter(&buf, flate.DefaultCompression)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func bodyHandler(header http.Header, body []byte) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		for key, values := range header {
+			w.Header()[key] = values
+		}
+		w.Write(body) // nolint: errcheck
+	})
+}
+
+func TestContentEncoding(t *testing.T) {
+	const jsonBody = `{"id":42,"name":"Bob"}`
+
+	for _, tc := range []struct {
+		contentEncoding string
+		body            []byte
+	}{
+		{"gzip", compress(t, "gzip", []byte(jsonBody))},
+		{"x-gzip", compress(t, "gzip", []byte(jsonBody))},
+		{"deflate", compress(t, "zlib", []byte(jsonBody))},
+		{"deflate", compress(t, "flate", []byte(jsonBody))},
+		{"identity", []byte(jsonBody)},
+		{"deflate, gzip",
+			compress(t, "gzip", compress(t, "zlib", []byte(jsonBody)))},
+	} {
+		handler := bodyHandler(http.Header{
+			"Content-Type":     []string{"application/json"},
+			"Content-Encoding": []string{tc.contentEncoding},
+		}, tc.body)
+
+		td.CmpTrue(t, tdhttp.CmpJSONResponse(t,
+			tdhttp.NewRequest("GET", "/", nil),
+			handler.ServeHTTP,
+			tdhttp.Response{
+				Status: http.StatusOK,
+				Body:   Person{ID: 42, Name: "Bob"},
+			}),
+			tc.contentEncoding)
+
+		tdhttp.NewTestAPI(t, handler).
+			Get("/").
+			CmpBody(td.All(td.Isa(""), td.Contains(`"Bob"`)))
+	}
+
+	// Empty body
+	tdhttp.NewTestAPI(t, bodyHandler(http.Header{
+		"Content-Encoding": []string{"gzip"},
+	}, compress(t, "gzip", nil))).
+		Get("/").
+		NoBody()
+
+	mockT := &recordT{}
+	tdhttp.NewTestAPI(mockT, bodyHandler(http.Header{
+		"Content-Encoding": []string{"br"},
+	}, []byte("xxx"))).
+		Get("/").
+		CmpBody("xxx")
+	td.Cmp(t, mockT.Errors(), td.All(
+		td.Contains("Failed test 'body decoding'"),
+		td.Contains(`unsupported Content-Encoding "br"`),
+	))
+
+	mockT = &recordT{}
+	tdhttp.NewTestAPI(mockT, bodyHandler(http.Header{
+		"Content-Encoding": []string{"gzip"},
+	}, []byte("not gzipped"))).
+		Get("/").
+		CmpBody("not gzipped")
+	td.Cmp(t, mockT.Errors(), td.All(
+		td.Contains("Failed test 'body decoding'"),
+		td.Contains("gzip decoding: "),
+	))
+}
+
+func TestContentType(t *testing.T) {
+	t.Run("Auto decoding", func(t *testing.T) {
+		for _, tc := range []struct {
+			contentType string
+			body        string
+			expected    interface{}
+		}{
+			{
+				contentType: "application/json",
+				body:        `{"id":42,"name":"Bob"}`,
+				expected:    map[string]interface{}{"id": float64(42), "name": "Bob"},
+			},
+			{
+				contentType: "application/problem+json",
+				body:        `[1,2]`,
+				expected:    []interface{}{float64(1), float64(2)},
+			},
+			{
+				contentType: "application/x-www-form-urlencoded",
+				body:        `a=1&b=2&a=3`,
+				expected:    url.Values{"a": []string{"1", "3"}, "b": []string{"2"}},
+			},
+			{
+				contentType: "text/plain; charset=ISO-8859-1",
+				body:        "caf\xe9",
+				expected:    "café",
+			},
+			{
+				contentType: "text/html",
+				body:        "<p>Hello</p>",
+				expected:    "<p>Hello</p>",
+			},
+			{
+				contentType: "application/octet-stream",
+				body:        "\x00\x01",
+				expected:    []byte{0, 1},
+			},
+			{
+				contentType: "text/xml",
+				body:        `<person id="42"><name>Bob</name></person>`,
+				expected: td.Struct(tdhttp.XMLNode{
+					XMLName: xml.Name{Local: "person"},
+					Attrs:   []xml.Attr{{Name: xml.Name{Local: "id"}, Value: "42"}},
+				}, td.StructFields{
+					"Nodes": []tdhttp.XMLNode{{
+						XMLName: xml.Name{Local: "name"},
+						Content: "Bob",
+					}},
+				}),
+			},
+		} {
+			handler := bodyHandler(
+				http.Header{"Content-Type": []string{tc.contentType}},
+				[]byte(tc.body))
+
+			// Smuggle hides the type behind
+			td.CmpTrue(t, tdhttp.CmpResponse(t,
+				tdhttp.NewRequest("GET", "/", nil),
+				handler.ServeHTTP,
+				tdhttp.Response{
+					Body: td.Smuggle(func(v interface{}) interface{} { return v },
+						tc.expected),
+				}),
+				tc.contentType)
+
+			tdhttp.NewTestAPI(t, handler).
+				Get("/").
+				CmpBody(td.All(td.Ignore(), tc.expected))
+		}
+	})
+
+	t.Run("Typed", func(t *testing.T) {
+		latin1 := bodyHandler(
+			http.Header{"Content-Type": []string{"text/plain; charset=latin1"}},
+			[]byte("caf\xe9"))
+
+		tdhttp.NewTestAPI(t, latin1).
+			Get("/").
+			CmpBody("café").
+			CmpBody([]byte("caf\xe9"))
+
+		tdhttp.NewTestAPI(t, bodyHandler(
+			http.Header{"Content-Type": []string{"application/x-www-form-urlencoded"}},
+			[]byte("a=1"))).
+			Get("/").
+			CmpBody(url.Values{"a": []string{"1"}}).
+			CmpBody("a=1")
+	})
+
+	t.Run("Lenient", func(t *testing.T) {
+		// No Content-Type set, so text/plain is sniffed
+		noType := bodyHandler(nil, []byte(`{"id":42,"name":"Bob"}`))
+
+		td.CmpTrue(t, tdhttp.CmpJSONResponse(t,
+			tdhttp.NewRequest("GET", "/", nil),
+			noType.ServeHTTP,
+			tdhttp.Response{
+				Body: Person{ID: 42, Name: "Bob"},
+			}))
+
+		tdhttp.NewTestAPI(t, noType).
+			Get("/").
+			CmpHeader(td.SuperMapOf(http.Header{
+				"Content-Type": []string{"text/plain; charset=utf-8"},
+			}, nil)).
+			CmpJSONBody(Person{ID: 42, Name: "Bob"})
+
+		noType = bodyHandler(nil, []byte(`<Person><id>42</id><name>Bob</name></Person>`))
+		td.CmpTrue(t, tdhttp.CmpXMLResponse(t,
+			tdhttp.NewRequest("GET", "/", nil),
+			noType.ServeHTTP,
+			tdhttp.Response{
+				Body: Person{ID: 42, Name: "Bob"},
+			}))
+
+		// Content-Type is ignored by explicit decoders
+		tdhttp.NewTestAPI(t, bodyHandler(
+			http.Header{"Content-Type": []string{"text/html"}},
+			[]byte(`{"id":42,"name":"Bob"}`))).
+			Get("/").
+			CmpJSONBody(Person{ID: 42, Name: "Bob"})
+	})
+
+	t.Run("Mismatch", func(t *testing.T) {
+		html := bodyHandler(
+			http.Header{"Content-Type": []string{"text/html"}},
+			[]byte(`{"id":42,"name":"Bob"}`))
+
+		mockT := &recordT{}
+		td.CmpFalse(t, tdhttp.CmpResponse(mockT,
+			tdhttp.NewRequest("GET", "/", nil),
+			html.ServeHTTP,
+			tdhttp.Response{
+				Body: Person{ID: 42, Name: "Bob"},
+			}))
+		td.Cmp(t, mockT.Errors(), td.All(
+			td.Contains("Failed test 'body unmarshaling'"),
+			td.Contains(`content-type mismatch: cannot decode "text/html" body into tdhttp_test.Person`),
+		))
+
+		mockT = &recordT{}
+		tdhttp.NewTestAPI(mockT, html).
+			Get("/").
+			CmpBody(url.Values{"id": []string{"42"}})
+		td.Cmp(t, mockT.Errors(),
+			td.Contains(`content-type mismatch: cannot decode "text/html" body into url.Values`))
+
+		mockT = &recordT{}
+		tdhttp.NewTestAPI(mockT, bodyHandler(
+			http.Header{"Content-Type": []string{"text/plain; charset=koi8-r"}},
+			[]byte("xxx"))).
+			Get("/").
+			CmpBody("xxx")
+		td.Cmp(t, mockT.Errors(), td.Contains(`unsupported charset "koi8-r"`))
+
+		mockT = &recordT{}
+		tdhttp.NewTestAPI(mockT, bodyHandler(
+			http.Header{"Content-Type": []string{"text/plain; bad"}},
+			[]byte("xxx"))).
+			Get("/").
+			CmpBody("xxx")
+		td.Cmp(t, mockT.Errors(), td.Contains(`bad Content-Type "text/plain; bad"`))
+	})
+}
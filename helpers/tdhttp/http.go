@@ -8,11 +8,10 @@
 package tdhttp
 
 import (
-	"encoding/json"
-	"encoding/xml"
+	"bytes"
 	"fmt"
+	"io/ioutil"
 	"net/http"
-	"net/http/httptest"
 	"reflect"
 	"testing"
 
@@ -21,19 +20,80 @@ import (
 )
 
 // Response is used by Cmp*Response functions to make the HTTP
-// response match easier. Each field, can be a TestDeep operator as
-// well as the exact expected value.
+// response match easier. Each field, except OpenAPI, can be a TestDeep
+// operator as well as the exact expected value.
 type Response struct {
 	Status interface{} // Status is the expected status (ignored if nil)
 	Header interface{} // Header is the expected header (ignored if nil)
 	Body   interface{} // Body is the expected body (expected to be empty if nil)
+	Chunks interface{} // Chunks are the expected flushed chunks (ignored if nil)
+	// Redirects, if non-nil, makes redirections followed and is the
+	// expected redirections chain, as a []Redirect. Other fields then
+	// apply to the final response. See CmpMarshaledResponse.
+	Redirects interface{}
+	// OpenAPI, if non-nil, is the OpenAPI document the request and its
+	// response are checked against. See CmpMarshaledResponse.
+	OpenAPI *OpenAPI
 }
 
+// maxResponseRedirects is the maximum number of redirections followed
+// by Cmp*Response functions, as net/http client does.
+const maxResponseRedirects = 10
+
 // CmpMarshaledResponse is the base function used by all others in
 // tdhttp package. The req *http.Request is launched against
 // handler. The response body is unmarshaled using unmarshal. The
 // response is then tested against expectedResp.
 //
+// If expectedResp.Chunks is non-nil, the body chunks, as flushed by
+// handler (see FlushRecorder), are tested against it as a []string.
+// If handler is a Server, chunk boundaries are not guaranteed (see
+// Server).
+//
+// Before being unmarshaled, the body is decoded according to the
+// response Content-Encoding header. gzip and deflate encodings are
+// handled.
+//
+// If expectedResp.Redirects is non-nil, 3xx redirections are
+// followed, up to 10, each redirection being sent to handler
+// too. Cookies are preserved across redirections, as TestAPI does
+// (see TestAPI.FollowRedirects). The chain of redirections is tested
+// against expectedResp.Redirects as a []Redirect, and the other
+// fields of expectedResp against the final response:
+//
+//   tdhttp.CmpResponse(t,
+//     tdhttp.NewRequest("GET", "/old", nil),
+//     mux.ServeHTTP,
+//     tdhttp.Response{
+//       Redirects: []tdhttp.Redirect{
+//         {Status: http.StatusMovedPermanently, Location: "/new"},
+//       },
+//       Status: http.StatusOK,
+//       Body:   "new page",
+//     })
+//
+// Use td.Ignore() as expectedResp.Redirects to follow redirections
+// without checking the chain. If nil, the default, redirections are
+// not followed and the first response received is tested.
+//
+// If expectedResp.OpenAPI is non-nil, the request and the response
+// are checked against this OpenAPI document, as TestAPI does (see
+// TestAPI.UseOpenAPI). When redirections are followed, only the last
+// request and its response are checked:
+//
+//   api, err := tdhttp.LoadOpenAPI("api/openapi.json")
+//   if err != nil {
+//     t.Fatal(err)
+//   }
+//   tdhttp.CmpJSONResponse(t,
+//     tdhttp.NewRequest("GET", "/person/42", nil),
+//     mux.ServeHTTP,
+//     tdhttp.Response{
+//       OpenAPI: api,
+//       Status:  http.StatusOK,
+//       Body:    td.SuperMapOf(map[string]interface{}{"id": 42}, nil),
+//     })
+//
 // All the tests are enclosed in a testdeep.Run().
 //
 // It returns true if the tests succeed, false otherwise.
@@ -45,18 +105,101 @@ func CmpMarshaledResponse(tt td.TestingFT,
 	args ...interface{},
 ) bool {
 	tt.Helper()
+	return cmpResponse(tt, req, handler, decoder{unmarshal: unmarshal},
+		expectedResp, args...)
+}
+
+// cmpResponse is the base function used by all Cmp*Response
+// functions. The req *http.Request is launched against handler. The
+// response body is unmarshaled using dec. The response is then
+// tested against expectedResp.
+func cmpResponse(tt td.TestingFT,
+	req *http.Request,
+	handler func(w http.ResponseWriter, r *http.Request),
+	dec decoder,
+	expectedResp Response,
+	args ...interface{},
+) bool {
+	tt.Helper()
 
 	if testName := tdutil.BuildTestName(args...); testName != "" {
 		tt.Log(testName)
 	}
 
-	var statusMismatch, headerMismatch bool
-
 	t := td.NewT(tt) // nolint: vetshadow
 
-	w := httptest.NewRecorder()
+	// Keep the body to be able to send it again during redirections
+	// or to check it against the OpenAPI document
+	var body []byte
+	if req.Body != nil &&
+		(expectedResp.Redirects != nil || expectedResp.OpenAPI != nil) {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close() // nolint: errcheck
+		if !t.CmpNoError(err, "request body reading") {
+			return false
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	ok := true
+
+	var chain redirectChain
+	if expectedResp.Redirects == nil {
+		w := NewFlushRecorder()
+
+		handler(w, req)
+
+		chain = redirectChain{req: req, reqBody: body, response: w}
+	} else {
+		var err error
+		chain, err = serveFollowingRedirects(http.HandlerFunc(handler), req, body,
+			maxResponseRedirects, nil)
+		if !t.CmpNoError(err, "redirections following") {
+			return false
+		}
 
-	handler(w, req)
+		if chain.tooMany {
+			t.Errorf("stopped after %d redirects", maxResponseRedirects)
+			ok = false
+		}
+
+		redirects := chain.redirects
+		if redirects == nil {
+			redirects = []Redirect{}
+		}
+		if !t.RootName("Response.Redirects").
+			Cmp(redirects, expectedResp.Redirects, "redirects should match") {
+			ok = false
+		}
+	}
+
+	if expectedResp.OpenAPI != nil {
+		reqErr, respErr := expectedResp.OpenAPI.check(chain.req, chain.reqBody,
+			chain.response)
+		if reqErr != nil {
+			t.Error(formatFailure(reqErr, "request should respect OpenAPI contract"))
+			ok = false
+		}
+		if respErr != nil {
+			t.Error(formatFailure(respErr, "response should respect OpenAPI contract"))
+			ok = false
+		}
+	}
+
+	return cmpMarshaledResponse(t, chain.response, dec, expectedResp) && ok
+}
+
+// cmpMarshaledResponse tests the response recorded in w against
+// expectedResp.
+func cmpMarshaledResponse(t *td.T,
+	w *FlushRecorder,
+	dec decoder,
+	expectedResp Response,
+) bool {
+	t.Helper()
+
+	var statusMismatch, headerMismatch, chunksMismatch bool
 
 	// Check status, nil = ignore
 	if expectedResp.Status != nil {
@@ -70,15 +213,46 @@ func CmpMarshaledResponse(tt td.TestingFT,
 			Cmp(w.Header(), expectedResp.Header, "header should match")
 	}
 
+	// Check chunks, nil = ignore
+	if expectedResp.Chunks != nil {
+		chunksMismatch = !t.RootName("Response.Chunks").
+			Cmp(w.Chunks(), expectedResp.Chunks, "chunks should match")
+	}
+
+	ok := cmpMarshaledBody(t, "", w.Header(), w.Body.Bytes(), dec,
+		expectedResp.Body, statusMismatch)
+	return !statusMismatch && !headerMismatch && !chunksMismatch && ok
+}
+
+// cmpMarshaledBody decodes rawBody according to the Content-Encoding
+// of header, unmarshals it using dec, then tests it against
+// expectedBody. namePrefix prefixes all tests names. If
+// statusMismatch is true, the raw body is shown even if expectedBody
+// is Ignore() or NotEmpty().
+func cmpMarshaledBody(t *td.T,
+	namePrefix string,
+	header http.Header,
+	rawBody []byte,
+	dec decoder,
+	expectedBody interface{},
+	statusMismatch bool,
+) bool {
+	t.Helper()
+
+	rawBody, err := decodeContentEncoding(header, rawBody)
+	if !t.RootName("decode(Response.Body)").
+		CmpNoError(err, namePrefix+"body decoding") {
+		return false
+	}
+
 	t = t.RootName("Response.Body")
 
 	// Body, nil = no body expected
-	if expectedResp.Body == nil {
-		ok := t.Empty(w.Body.Bytes(), "body should be empty")
-		return !statusMismatch && !headerMismatch && ok
+	if expectedBody == nil {
+		return t.Empty(rawBody, namePrefix+"body should be empty")
 	}
 
-	if !t.NotEmpty(w.Body.Bytes(), "body should not be empty") {
+	if !t.NotEmpty(rawBody, namePrefix+"body should not be empty") {
 		return false
 	}
 
@@ -91,7 +265,7 @@ func CmpMarshaledResponse(tt td.TestingFT,
 	// behind it. It should work in most cases (typically Struct(),
 	// Map() & Slice()).
 	var unknownExpectedType, showRawBody bool
-	op, ok := expectedResp.Body.(td.TestDeep)
+	op, ok := expectedBody.(td.TestDeep)
 	if ok {
 		bodyType = op.TypeBehind()
 		if bodyType == nil {
@@ -107,17 +281,17 @@ func CmpMarshaledResponse(tt td.TestingFT,
 			}
 		}
 	} else {
-		bodyType = reflect.TypeOf(expectedResp.Body)
+		bodyType = reflect.TypeOf(expectedBody)
 	}
 
 	// For unmarshaling below, body must be a pointer
 	body = reflect.New(bodyType).Interface()
 
-	success := !statusMismatch && !headerMismatch
+	success := true
 
 	// Try to unmarshal body
 	if !t.RootName("unmarshal(Response.Body)").
-		CmpNoError(unmarshal(w.Body.Bytes(), body), "body unmarshaling") {
+		CmpNoError(dec.unmarshaler(header)(rawBody, body), namePrefix+"body unmarshaling") {
 		// If unmarshal failed, perhaps it's coz the expected body type
 		// is unknown?
 		if unknownExpectedType {
@@ -128,7 +302,7 @@ func CmpMarshaledResponse(tt td.TestingFT,
 		}
 		success = false
 		showRawBody = true // let's show its real body contents
-	} else if !t.Cmp(body, td.Ptr(expectedResp.Body), "body contents is OK") {
+	} else if !t.Cmp(body, td.Ptr(expectedBody), namePrefix+"body contents is OK") {
 		// If the body comparison fails
 		success = false
 
@@ -142,18 +316,54 @@ func CmpMarshaledResponse(tt td.TestingFT,
 	}
 
 	if showRawBody {
-		t.Logf("Raw received body: %s", tdutil.FormatString(w.Body.String()))
+		t.Logf("Raw received body: %s", tdutil.FormatString(string(rawBody)))
 	}
 
 	return success
 }
 
+// unmarshalRaw is the unmarshal function used by CmpResponse: the
+// body is kept as is in a string or a []byte.
+func unmarshalRaw(body []byte, target interface{}) error {
+	switch t := target.(type) {
+	case *string:
+		*t = string(body)
+	case *[]byte:
+		*t = body
+	case *interface{}:
+		*t = body
+	default:
+		return fmt.Errorf(
+			"CmpResponse does not handle %T body, only string & []byte",
+			target)
+	}
+	return nil
+}
+
 // CmpResponse is used to match a []byte or string response body. The
 // req *http.Request is launched against handler. If expectedResp.Body
 // is non-nil, the response body is converted to []byte or string,
 // depending on the expectedResp.Body type. The response is then
 // tested against expectedResp.
 //
+// A text body, as told by the response Content-Type, is converted
+// from its charset to UTF-8 (only UTF-8, US-ASCII and ISO-8859-1 are
+// handled) when converted to a string. An
+// "application/x-www-form-urlencoded" body can also be converted to
+// an url.Values, and JSON or XML bodies unmarshaled into any other
+// type. If the response Content-Type does not allow to decode the
+// body into the expectedResp.Body type, the body unmarshaling fails
+// with a "content-type mismatch" error.
+//
+// If expectedResp.Body is a TestDeep operator that does not know the
+// type behind it, the response body is decoded depending on the
+// response Content-Type:
+//   - JSON is json.Unmarshal'ed into an interface{};
+//   - XML is xml.Unmarshal'ed into an XMLNode;
+//   - "application/x-www-form-urlencoded" is parsed into an url.Values;
+//   - text is converted to a string;
+//   - anything else is kept as a []byte.
+//
 // All the tests are enclosed in a testdeep.Run().
 //
 // It returns true if the tests succeed, false otherwise.
@@ -163,24 +373,10 @@ func CmpResponse(t td.TestingFT,
 	expectedResp Response,
 	args ...interface{}) bool {
 	t.Helper()
-	return CmpMarshaledResponse(t,
+	return cmpResponse(t,
 		req,
 		handler,
-		func(body []byte, target interface{}) error {
-			switch t := target.(type) {
-			case *string:
-				*t = string(body)
-			case *[]byte:
-				*t = body
-			case *interface{}:
-				*t = body
-			default:
-				return fmt.Errorf(
-					"CmpResponse does not handle %T body, only string & []byte",
-					target)
-			}
-			return nil
-		},
+		rawDecoder,
 		expectedResp,
 		args...)
 }
@@ -190,6 +386,9 @@ func CmpResponse(t td.TestingFT,
 // non-nil, the response body is json.Unmarshal'ed. The response is
 // then tested against expectedResp.
 //
+// The response Content-Type is ignored, so a handler not setting it
+// is fine.
+//
 // All the tests are enclosed in a testdeep.Run().
 //
 // It returns true if the tests succeed, false otherwise.
@@ -200,10 +399,10 @@ func CmpJSONResponse(t td.TestingFT,
 	args ...interface{},
 ) bool {
 	t.Helper()
-	return CmpMarshaledResponse(t,
+	return cmpResponse(t,
 		req,
 		handler,
-		json.Unmarshal,
+		jsonDecoder,
 		expectedResp,
 		args...)
 }
@@ -213,6 +412,9 @@ func CmpJSONResponse(t td.TestingFT,
 // non-nil, the response body is xml.Unmarshal'ed. The response is
 // then tested against expectedResp.
 //
+// The response Content-Type is ignored, so a handler not setting it
+// is fine.
+//
 // All the tests are enclosed in a testdeep.Run().
 //
 // It returns true if the tests succeed, false otherwise.
@@ -223,10 +425,10 @@ func CmpXMLResponse(t td.TestingFT,
 	args ...interface{},
 ) bool {
 	t.Helper()
-	return CmpMarshaledResponse(t,
+	return cmpResponse(t,
 		req,
 		handler,
-		xml.Unmarshal,
+		xmlDecoder,
 		expectedResp,
 		args...)
 }
@@ -0,0 +1,575 @@
+// Copyright (c) 2019, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package tdhttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strconv"
+	"sync"
+
+	td "github.com/maxatome/go-testdeep"
+)
+
+// MockTransport is an http.RoundTripper allowing to test HTTP
+// clients. Expectations are registered using Expect, each one
+// matching requests thanks to TestDeep operators and returning a
+// canned response:
+//
+//   func TestMyClient(t *testing.T) {
+//     mt := tdhttp.NewMockTransport(t)
+//
+//     mt.Expect("GET", td.HasPrefix("http://api.example.com/person/")).
+//       WithHeader(td.SuperMapOf(http.Header{
+//         "Accept": []string{"application/json"},
+//       }, nil)).
+//       RespondJSON(http.StatusOK, Person{ID: 42, Name: "Bob"})
+//
+//     mt.Expect("POST", "http://api.example.com/person").
+//       WithJSONBody(td.Struct(Person{Name: "Alice"}, nil)).
+//       Times(1).
+//       Respond(http.StatusCreated, "")
+//
+//     client := myapi.NewClient(mt.Client())
+//     …
+//
+//     mt.AssertAllCalled()
+//   }
+//
+// A request matching no expectation gets an error from the
+// transport and is reported by AssertAllCalled.
+//
+// MockTransport is safe for concurrent use.
+type MockTransport struct {
+	t *td.T
+
+	mu           sync.Mutex
+	ordered      bool
+	current      int
+	expectations []*Expectation
+	unmatched    []unmatchedRequest
+}
+
+// Expectation is a request expectation registered in a
+// MockTransport using Expect.
+type Expectation struct {
+	mock *MockTransport
+	num  int
+
+	method interface{}
+	url    interface{}
+	header interface{}
+
+	body          interface{}
+	bodyUnmarshal func([]byte, interface{}) error
+
+	minCalls int
+	maxCalls int // -1 = unlimited
+	calls    int
+
+	respond func(*http.Request) (*http.Response, error)
+}
+
+// mockRequest is the data of a request, as matched against
+// expectations.
+type mockRequest struct {
+	method string
+	url    string
+	header http.Header
+	body   []byte
+}
+
+type unmatchedRequest struct {
+	req     mockRequest
+	closest *Expectation // nil if no expectations
+}
+
+// NewMockTransport returns a new MockTransport, whose expectations
+// failures are reported to "tt".
+func NewMockTransport(tt td.TestingFT) *MockTransport {
+	return &MockTransport{
+		t: td.NewT(tt),
+	}
+}
+
+// Ordered makes the expectations of m matched in the order they are
+// registered. Each request is then matched against the current
+// expectation. Once the current expectation has been called the
+// minimum number of times it expects, a request not matching it is
+// matched against the next expectation, and so on.
+//
+// By default, each request is matched against all the expectations
+// in turn, the first matching one wins.
+func (m *MockTransport) Ordered() *MockTransport {
+	m.mu.Lock()
+	m.ordered = true
+	m.mu.Unlock()
+	return m
+}
+
+// Client returns a new *http.Client using m as transport.
+func (m *MockTransport) Client() *http.Client {
+	return &http.Client{Transport: m}
+}
+
+// Expect registers a new expectation matching requests whose method
+// matches "method" and URL, as a string, matches "url". Both can be
+// TestDeep operators. A nil value means any method or URL.
+//
+// By default, the expectation has to be called at least once (see
+// Times) and responds with an empty 200 response (see Respond*
+// methods).
+func (m *MockTransport) Expect(method, url interface{}) *Expectation {
+	e := &Expectation{
+		mock:     m,
+		method:   method,
+		url:      url,
+		minCalls: 1,
+		maxCalls: -1,
+	}
+	e.Respond(http.StatusOK, "")
+
+	m.mu.Lock()
+	e.num = len(m.expectations) + 1
+	m.expectations = append(m.expectations, e)
+	m.mu.Unlock()
+	return e
+}
+
+// WithHeader makes e match only requests whose header matches
+// "expectedHeader". It can be a http.Header or a TestDeep operator.
+func (e *Expectation) WithHeader(expectedHeader interface{}) *Expectation {
+	e.mock.mu.Lock()
+	e.header = expectedHeader
+	e.mock.mu.Unlock()
+	return e
+}
+
+// WithBody makes e match only requests whose body matches
+// "expectedBody". "expectedBody" can be a string, a []byte or a
+// TestDeep operator, in which case it is applied on the body as a
+// string.
+func (e *Expectation) WithBody(expectedBody interface{}) *Expectation {
+	return e.withBody(unmarshalMockString, expectedBody)
+}
+
+// WithJSONBody makes e match only requests whose JSON body matches
+// "expectedBody". As for CmpJSONResponse, the body is unmarshaled in
+// the type of "expectedBody" or in the type behind it if it is a
+// TestDeep operator, in an interface{} otherwise.
+func (e *Expectation) WithJSONBody(expectedBody interface{}) *Expectation {
+	return e.withBody(json.Unmarshal, expectedBody)
+}
+
+func (e *Expectation) withBody(unmarshal func([]byte, interface{}) error,
+	expectedBody interface{}) *Expectation {
+	e.mock.mu.Lock()
+	e.body = expectedBody
+	e.bodyUnmarshal = unmarshal
+	e.mock.mu.Unlock()
+	return e
+}
+
+// Times sets the exact number of times e has to be called. Once
+// called "times" times, e does not match any request anymore.
+func (e *Expectation) Times(times int) *Expectation {
+	e.mock.mu.Lock()
+	e.minCalls, e.maxCalls = times, times
+	e.mock.mu.Unlock()
+	return e
+}
+
+// AtLeast sets the minimum number of times e has to be called, 1 by
+// default. AtLeast(0) makes e optional. It can be combined with
+// AtMost:
+//
+//   mt.Expect("GET", "http://api.test/health").
+//     AtLeast(1).
+//     AtMost(3)
+func (e *Expectation) AtLeast(times int) *Expectation {
+	e.mock.mu.Lock()
+	e.minCalls = times
+	if e.maxCalls >= 0 && e.maxCalls < times {
+		e.maxCalls = times
+	}
+	e.mock.mu.Unlock()
+	return e
+}
+
+// AtMost sets the maximum number of times e can be called, unlimited
+// by default. Once called "times" times, e does not match any
+// request anymore. As the minimum stays 1 (unless "times" is 0), use
+// AtLeast(0) to make e optional.
+func (e *Expectation) AtMost(times int) *Expectation {
+	e.mock.mu.Lock()
+	e.maxCalls = times
+	if e.minCalls > times {
+		e.minCalls = times
+	}
+	e.mock.mu.Unlock()
+	return e
+}
+
+// Calls returns the number of requests e matched so far.
+func (e *Expectation) Calls() int {
+	e.mock.mu.Lock()
+	defer e.mock.mu.Unlock()
+	return e.calls
+}
+
+// Respond sets the response returned for requests matching e, with
+// "status" as status code and "body" as body. "headers" can contain
+// http.Header instances or pairs of strings (key then value), added
+// to the response header.
+func (e *Expectation) Respond(status int, body string, headers ...interface{}) *Expectation {
+	header := http.Header{}
+	addHeaders(&http.Request{Header: header}, headers)
+
+	return e.RespondFunc(func(req *http.Request) (*http.Response, error) {
+		return newMockResponse(req, status, header, []byte(body)), nil
+	})
+}
+
+// RespondJSON sets the response returned for requests matching e,
+// with "status" as status code and "body" JSON-marshaled as
+// body. "headers" can contain http.Header instances or pairs of
+// strings (key then value), added to the response header. The
+// Content-Type header defaults to "application/json".
+func (e *Expectation) RespondJSON(status int, body interface{}, headers ...interface{}) *Expectation {
+	b, err := json.Marshal(body)
+	if err != nil {
+		panic("JSON encoding failed: " + err.Error())
+	}
+
+	header := http.Header{}
+	addHeaders(&http.Request{Header: header}, headers)
+	if header.Get("Content-Type") == "" {
+		header.Set("Content-Type", "application/json")
+	}
+
+	return e.RespondFunc(func(req *http.Request) (*http.Response, error) {
+		return newMockResponse(req, status, header, b), nil
+	})
+}
+
+// RespondError makes requests matching e fail with "err", as if a
+// network error occurred.
+func (e *Expectation) RespondError(err error) *Expectation {
+	return e.RespondFunc(func(*http.Request) (*http.Response, error) {
+		return nil, err
+	})
+}
+
+// RespondFunc makes requests matching e handled by "fn". The body of
+// the request passed to "fn" can be read again.
+func (e *Expectation) RespondFunc(fn func(*http.Request) (*http.Response, error)) *Expectation {
+	e.mock.mu.Lock()
+	e.respond = fn
+	e.mock.mu.Unlock()
+	return e
+}
+
+func (e *Expectation) String() string {
+	return fmt.Sprintf("expectation #%d (%s %s)",
+		e.num, expectedString(e.method), expectedString(e.url))
+}
+
+func expectedString(expected interface{}) string {
+	switch exp := expected.(type) {
+	case nil:
+		return "*"
+	case string:
+		return exp
+	case td.TestDeep:
+		return exp.String()
+	}
+	return fmt.Sprint(expected)
+}
+
+// unmarshalMockString is the unmarshal function used by
+// Expectation.WithBody: contrary to unmarshalRaw, the body is
+// unmarshaled as a string in an interface{}.
+func unmarshalMockString(body []byte, target interface{}) error {
+	if t, ok := target.(*interface{}); ok {
+		*t = string(body)
+		return nil
+	}
+	return unmarshalRaw(body, target)
+}
+
+// unmarshalBody unmarshals "body" using "unmarshal" in the type of
+// "expected" or in the type behind it if it is a TestDeep operator,
+// in an interface{} otherwise.
+func unmarshalBody(body []byte, unmarshal func([]byte, interface{}) error,
+	expected interface{}) (interface{}, error) {
+	var bodyType reflect.Type
+	if op, ok := expected.(td.TestDeep); ok {
+		bodyType = op.TypeBehind()
+	} else {
+		bodyType = reflect.TypeOf(expected)
+	}
+	if bodyType == nil {
+		bodyType = reflect.TypeOf((*interface{})(nil)).Elem()
+	}
+
+	target := reflect.New(bodyType)
+	err := unmarshal(body, target.Interface())
+	return target.Elem().Interface(), err
+}
+
+// matchFields returns the number of request fields matching e,
+// and whether all of them match.
+func (e *Expectation) matchFields(req mockRequest) (int, bool) {
+	var num, total int
+
+	check := func(got, expected interface{}) {
+		if expected != nil {
+			total++
+			if td.EqDeeply(got, expected) {
+				num++
+			}
+		}
+	}
+
+	check(req.method, e.method)
+	check(req.url, e.url)
+	check(req.header, e.header)
+	if e.body != nil {
+		total++
+		body, err := unmarshalBody(req.body, e.bodyUnmarshal, e.body)
+		if err == nil && td.EqDeeply(body, e.body) {
+			num++
+		}
+	}
+	return num, num == total
+}
+
+// full returns true if e cannot match any request anymore.
+func (e *Expectation) full() bool {
+	return e.maxCalls >= 0 && e.calls >= e.maxCalls
+}
+
+// matchResult is the result of matching a request against an
+// expectation, see Expectation.matchFields.
+type matchResult struct {
+	num int
+	ok  bool
+}
+
+// matchAll matches "req" against all the expectations registered so
+// far. Expectations are copied under lock, but TestDeep operators are
+// run without holding it, so they can safely use m.
+func (m *MockTransport) matchAll(req mockRequest) []matchResult {
+	m.mu.Lock()
+	expectations := make([]Expectation, len(m.expectations))
+	for i, e := range m.expectations {
+		expectations[i] = *e
+	}
+	m.mu.Unlock()
+
+	results := make([]matchResult, len(expectations))
+	for i := range expectations {
+		results[i].num, results[i].ok = expectations[i].matchFields(req)
+	}
+	return results
+}
+
+// findExpectation returns the expectation matching a request, given
+// the "results" of matchAll for it, or nil and the closest
+// expectation if none matches. m.mu must be held.
+func (m *MockTransport) findExpectation(results []matchResult) (*Expectation, *Expectation) {
+	expectations := m.expectations[:len(results)]
+
+	if m.ordered {
+		for m.current < len(expectations) {
+			e := expectations[m.current]
+			if !e.full() && results[m.current].ok {
+				return e, nil
+			}
+			if e.calls < e.minCalls {
+				return nil, e
+			}
+			m.current++
+		}
+		if len(expectations) > 0 {
+			return nil, expectations[len(expectations)-1]
+		}
+		return nil, nil
+	}
+
+	var closest *Expectation
+	best := -1
+	for i, e := range expectations {
+		if results[i].ok && !e.full() {
+			return e, nil
+		}
+		if results[i].num > best {
+			closest, best = e, results[i].num
+		}
+	}
+	return nil, closest
+}
+
+// RoundTrip implements http.RoundTripper interface.
+//
+// TestDeep operators of expectations and responders are called
+// without holding any lock, so a responder can send other requests
+// through m.
+func (m *MockTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close() // nolint: errcheck
+		if err != nil {
+			return nil, err
+		}
+		// RoundTrip must not modify req, so work on a copy of it
+		req = req.WithContext(req.Context())
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	mreq := mockRequest{
+		method: req.Method,
+		url:    req.URL.String(),
+		header: req.Header,
+		body:   body,
+	}
+
+	results := m.matchAll(mreq)
+
+	m.mu.Lock()
+	e, closest := m.findExpectation(results)
+	if e == nil {
+		m.unmatched = append(m.unmatched, unmatchedRequest{
+			req:     mreq,
+			closest: closest,
+		})
+		m.mu.Unlock()
+		return nil, fmt.Errorf("tdhttp.MockTransport: no expectation matches %s %s",
+			mreq.method, mreq.url)
+	}
+	e.calls++
+	respond := e.respond
+	m.mu.Unlock()
+
+	return respond(req)
+}
+
+// AssertAllCalled checks that each expectation has been called the
+// expected number of times and that no request was left unmatched.
+// For each unmatched request, the mismatches against the closest
+// expectation are reported.
+//
+// It returns true if all checks succeed, false otherwise.
+func (m *MockTransport) AssertAllCalled() bool {
+	m.t.Helper()
+
+	// Copy the state under lock, as reporting mismatches runs
+	// TestDeep operators
+	m.mu.Lock()
+	expectations := make([]Expectation, len(m.expectations))
+	for i, e := range m.expectations {
+		expectations[i] = *e
+	}
+	unmatched := make([]unmatchedRequest, len(m.unmatched))
+	for i, u := range m.unmatched {
+		unmatched[i] = u
+		if u.closest != nil {
+			closest := *u.closest
+			unmatched[i].closest = &closest
+		}
+	}
+	m.mu.Unlock()
+
+	success := true
+
+	for i := range expectations {
+		e := &expectations[i]
+		if e.calls < e.minCalls {
+			success = false
+			switch {
+			case e.minCalls == e.maxCalls:
+				m.t.Errorf("%s called %d times, expected %d", e, e.calls, e.minCalls)
+			case e.calls == 0:
+				m.t.Errorf("%s never called", e)
+			default:
+				m.t.Errorf("%s called %d times, expected at least %d",
+					e, e.calls, e.minCalls)
+			}
+		}
+	}
+
+	for i, u := range unmatched {
+		success = false
+		name := fmt.Sprintf("unmatched request #%d %s %s", i+1, u.req.method, u.req.url)
+		if u.closest == nil {
+			m.t.Errorf("%s: no expectations registered", name)
+			continue
+		}
+		m.reportMismatch(name, u.req, u.closest)
+	}
+
+	return success
+}
+
+// reportMismatch reports all differences between "req" and "e".
+func (m *MockTransport) reportMismatch(name string, req mockRequest, e *Expectation) {
+	m.t.Helper()
+
+	if e.full() {
+		m.t.Errorf("%s: closest %s already called %d times",
+			name, e, e.calls)
+	}
+
+	name += ", closest " + e.String()
+
+	if e.method != nil {
+		m.t.RootName("Request.Method").
+			Cmp(req.method, e.method, name+": method should match")
+	}
+	if e.url != nil {
+		m.t.RootName("Request.URL").
+			Cmp(req.url, e.url, name+": URL should match")
+	}
+	if e.header != nil {
+		m.t.RootName("Request.Header").
+			Cmp(req.header, e.header, name+": header should match")
+	}
+	if e.body != nil {
+		body, err := unmarshalBody(req.body, e.bodyUnmarshal, e.body)
+		if m.t.RootName("unmarshal(Request.Body)").
+			CmpNoError(err, name+": body unmarshaling") {
+			m.t.RootName("Request.Body").
+				Cmp(body, e.body, name+": body should match")
+		}
+	}
+}
+
+// newMockResponse returns a new response to "req".
+func newMockResponse(req *http.Request, status int, header http.Header, body []byte) *http.Response {
+	h := make(http.Header, len(header))
+	for key, values := range header {
+		h[key] = append([]string(nil), values...)
+	}
+
+	return &http.Response{
+		Status:        strconv.Itoa(status) + " " + http.StatusText(status),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        h,
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
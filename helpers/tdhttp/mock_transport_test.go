@@ -0,0 +1,282 @@
+// Copyright (c) 2019, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package tdhttp_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	td "github.com/maxatome/go-testdeep"
+	"github.com/maxatome/go-testdeep/helpers/tdhttp"
+)
+
+func readBody(t *testing.T, resp *http.Response) string {
+	t.Helper()
+	defer resp.Body.Close() // nolint: errcheck
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
+func TestMockTransport(t *testing.T) {
+	t.Run("Unordered", func(t *testing.T) {
+		mt := tdhttp.NewMockTransport(t)
+
+		getBob := mt.Expect("GET", td.HasPrefix("http://api.test/person/")).
+			WithHeader(td.SuperMapOf(http.Header{
+				"Accept": []string{"application/json"},
+			}, nil)).
+			RespondJSON(http.StatusOK, Person{ID: 42, Name: "Bob"}, "X-Test", "yes")
+
+		createAlice := mt.Expect("POST", "http://api.test/person").
+			WithJSONBody(td.Struct(Person{Name: "Alice"}, nil)).
+			Times(2).
+			Respond(http.StatusCreated, "created")
+
+		upload := mt.Expect(nil, nil).
+			WithBody(td.HasPrefix("raw:")).
+			RespondFunc(func(req *http.Request) (*http.Response, error) {
+				body, _ := ioutil.ReadAll(req.Body)
+				return &http.Response{
+					StatusCode: http.StatusAccepted,
+					Body:       ioutil.NopCloser(strings.NewReader(strings.ToUpper(string(body)))),
+				}, nil
+			})
+
+		mt.Expect("DELETE", nil).
+			RespondError(errors.New("network down"))
+
+		client := mt.Client()
+
+		req, _ := http.NewRequest("GET", "http://api.test/person/42", nil)
+		req.Header.Set("Accept", "application/json")
+		resp, err := client.Do(req)
+		if td.CmpNoError(t, err) {
+			td.Cmp(t, resp.StatusCode, http.StatusOK)
+			td.Cmp(t, resp.Status, "200 OK")
+			td.Cmp(t, resp.Header.Get("Content-Type"), "application/json")
+			td.Cmp(t, resp.Header.Get("X-Test"), "yes")
+			var p Person
+			td.CmpNoError(t, json.Unmarshal([]byte(readBody(t, resp)), &p))
+			td.Cmp(t, p, Person{ID: 42, Name: "Bob"})
+		}
+
+		for i := 0; i < 2; i++ {
+			resp, err = client.Post("http://api.test/person", "application/json",
+				strings.NewReader(`{"name":"Alice"}`))
+			if td.CmpNoError(t, err) {
+				td.Cmp(t, resp.StatusCode, http.StatusCreated)
+				td.Cmp(t, readBody(t, resp), "created")
+			}
+		}
+
+		resp, err = client.Post("http://api.test/upload", "text/plain",
+			strings.NewReader("raw:data"))
+		if td.CmpNoError(t, err) {
+			td.Cmp(t, resp.StatusCode, http.StatusAccepted)
+			td.Cmp(t, readBody(t, resp), "RAW:DATA")
+		}
+
+		req, _ = http.NewRequest("DELETE", "http://api.test/person/42", nil)
+		_, err = client.Do(req)
+		td.Cmp(t, err, td.Contains("network down"))
+
+		td.Cmp(t, getBob.Calls(), 1)
+		td.Cmp(t, createAlice.Calls(), 2)
+		td.Cmp(t, upload.Calls(), 1)
+		td.CmpTrue(t, mt.AssertAllCalled())
+	})
+
+	t.Run("Unordered failures", func(t *testing.T) {
+		mockT := &recordT{}
+		mt := tdhttp.NewMockTransport(mockT)
+
+		mt.Expect("GET", "http://api.test/person/42")
+		mt.Expect("POST", "http://api.test/person").
+			WithHeader(td.ContainsKey("Authorization")).
+			WithJSONBody(Person{Name: "Alice"}).
+			Times(1)
+		mt.Expect("PUT", "http://api.test/person/12").
+			Times(2)
+
+		client := mt.Client()
+
+		// Matches 2nd expectation, called once
+		req, _ := http.NewRequest("POST", "http://api.test/person",
+			strings.NewReader(`{"name":"Alice"}`))
+		req.Header.Set("Authorization", "Bearer x")
+		_, err := client.Do(req)
+		td.CmpNoError(t, err)
+
+		// Closest is 2nd expectation, but Bob instead of Alice
+		_, err = client.Post("http://api.test/person", "application/json",
+			strings.NewReader(`{"name":"Bob"}`))
+		td.Cmp(t, err, td.Contains(
+			"tdhttp.MockTransport: no expectation matches POST http://api.test/person"))
+
+		// PUT called once instead of twice
+		req, _ = http.NewRequest("PUT", "http://api.test/person/12", nil)
+		_, err = client.Do(req)
+		td.CmpNoError(t, err)
+
+		td.CmpFalse(t, mt.AssertAllCalled())
+		td.Cmp(t, mockT.errors, td.Len(5))
+		td.Cmp(t, mockT.Errors(), td.All(
+			td.Contains(`expectation #1 (GET http://api.test/person/42) never called`),
+			td.Contains(`expectation #3 (PUT http://api.test/person/12) called 1 times, expected 2`),
+			td.Contains(`unmatched request #1 POST http://api.test/person: closest expectation #2 (POST http://api.test/person) already called 1 times`),
+			td.Contains(`unmatched request #1 POST http://api.test/person, closest expectation #2 (POST http://api.test/person): header should match`),
+			td.Contains(`unmatched request #1 POST http://api.test/person, closest expectation #2 (POST http://api.test/person): body should match`),
+			td.Contains(`Request.Body.Name`),
+		))
+	})
+
+	t.Run("Ordered", func(t *testing.T) {
+		mt := tdhttp.NewMockTransport(t).Ordered()
+
+		mt.Expect("POST", "http://api.test/login").
+			Times(1).
+			Respond(http.StatusOK, "token")
+		mt.Expect("GET", td.HasPrefix("http://api.test/person/")).
+			Respond(http.StatusOK, "person")
+		mt.Expect("POST", "http://api.test/logout")
+
+		client := mt.Client()
+		for _, call := range []struct{ method, url, body string }{
+			{"POST", "http://api.test/login", "token"},
+			{"GET", "http://api.test/person/1", "person"},
+			{"GET", "http://api.test/person/2", "person"},
+			{"POST", "http://api.test/logout", ""},
+		} {
+			req, _ := http.NewRequest(call.method, call.url, nil)
+			resp, err := client.Do(req)
+			if td.CmpNoError(t, err, call.url) {
+				td.Cmp(t, readBody(t, resp), call.body, call.url)
+			}
+		}
+		td.CmpTrue(t, mt.AssertAllCalled())
+	})
+
+	t.Run("Ordered failures", func(t *testing.T) {
+		mockT := &recordT{}
+		mt := tdhttp.NewMockTransport(mockT).Ordered()
+
+		mt.Expect("POST", "http://api.test/login")
+		mt.Expect("GET", "http://api.test/person")
+
+		client := mt.Client()
+
+		// Login must be called first
+		_, err := client.Get("http://api.test/person")
+		td.CmpError(t, err)
+
+		_, err = client.Post("http://api.test/login", "text/plain", nil)
+		td.CmpNoError(t, err)
+		_, err = client.Get("http://api.test/person")
+		td.CmpNoError(t, err)
+
+		// No more expectations after last one
+		_, err = client.Post("http://api.test/login", "text/plain", nil)
+		td.CmpError(t, err)
+
+		td.CmpFalse(t, mt.AssertAllCalled())
+		td.Cmp(t, mockT.Errors(), td.All(
+			td.Contains(`unmatched request #1 GET http://api.test/person, closest expectation #1 (POST http://api.test/login): method should match`),
+			td.Contains(`unmatched request #1 GET http://api.test/person, closest expectation #1 (POST http://api.test/login): URL should match`),
+			td.Contains(`unmatched request #2 POST http://api.test/login, closest expectation #2 (GET http://api.test/person): method should match`),
+		))
+	})
+
+	t.Run("No expectations", func(t *testing.T) {
+		mockT := &recordT{}
+		mt := tdhttp.NewMockTransport(mockT)
+
+		td.CmpTrue(t, mt.AssertAllCalled())
+
+		_, err := mt.Client().Get("http://api.test/")
+		td.CmpError(t, err)
+
+		td.CmpFalse(t, mt.AssertAllCalled())
+		td.Cmp(t, mockT.Errors(), td.Contains(
+			"unmatched request #1 GET http://api.test/: no expectations registered"))
+	})
+	t.Run("Reentrant", func(t *testing.T) {
+		mt := tdhttp.NewMockTransport(t)
+		client := mt.Client()
+
+		inner := mt.Expect("GET", "http://api.test/inner").
+			Respond(http.StatusOK, "inner")
+		mt.Expect("GET", td.Code(func(url string) bool {
+			// Operators can use the transport too
+			return url == "http://api.test/outer" && inner.Calls() >= 0
+		})).
+			RespondFunc(func(req *http.Request) (*http.Response, error) {
+				return client.Get("http://api.test/inner")
+			})
+
+		resp, err := client.Get("http://api.test/outer")
+		if td.CmpNoError(t, err) {
+			td.Cmp(t, readBody(t, resp), "inner")
+		}
+		td.CmpTrue(t, mt.AssertAllCalled())
+	})
+
+	t.Run("AtLeast & AtMost", func(t *testing.T) {
+		mockT := &recordT{}
+		mt := tdhttp.NewMockTransport(mockT)
+
+		health := mt.Expect("GET", "http://api.test/health").AtLeast(2).AtMost(3)
+		optional := mt.Expect("GET", "http://api.test/optional").AtLeast(0)
+
+		client := mt.Client()
+		for i := 0; i < 4; i++ {
+			client.Get("http://api.test/health") // nolint: errcheck
+		}
+		td.Cmp(t, health.Calls(), 3)
+		td.Cmp(t, optional.Calls(), 0)
+		td.CmpFalse(t, mt.AssertAllCalled()) // 4th request unmatched
+		td.Cmp(t, mockT.Errors(), td.All(
+			td.Contains("closest expectation #1 (GET http://api.test/health) already called 3 times"),
+			td.Not(td.Contains("never called")),
+		))
+
+		mockT = &recordT{}
+		mt = tdhttp.NewMockTransport(mockT)
+		mt.Expect("GET", nil).AtLeast(3)
+		mt.Client().Get("http://api.test/") // nolint: errcheck
+		td.CmpFalse(t, mt.AssertAllCalled())
+		td.Cmp(t, mockT.Errors(), td.Contains(
+			"expectation #1 (GET *) called 1 times, expected at least 3"))
+	})
+
+	t.Run("Request not modified", func(t *testing.T) {
+		mt := tdhttp.NewMockTransport(t)
+		mt.Expect("POST", nil).
+			WithBody("payload").
+			RespondFunc(func(req *http.Request) (*http.Response, error) {
+				body, _ := ioutil.ReadAll(req.Body)
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       ioutil.NopCloser(strings.NewReader(string(body))),
+				}, nil
+			})
+
+		body := ioutil.NopCloser(strings.NewReader("payload"))
+		req, _ := http.NewRequest("POST", "http://api.test/", body)
+		resp, err := mt.RoundTrip(req)
+		if td.CmpNoError(t, err) {
+			td.Cmp(t, readBody(t, resp), "payload")
+		}
+		td.CmpTrue(t, req.Body == body, "req.Body not replaced")
+	})
+}
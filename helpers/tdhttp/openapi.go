@@ -0,0 +1,560 @@
+// Copyright (c) 2019, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package tdhttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/maxatome/go-testdeep/internal/ctxerr"
+	"github.com/maxatome/go-testdeep/internal/jsonschema"
+	"github.com/maxatome/go-testdeep/internal/types"
+)
+
+// OpenAPI is an OpenAPI 3 document describing the API contract,
+// used by TestAPI to check each request and its response (see
+// TestAPI.UseOpenAPI) and by Cmp*Response functions (see
+// Response.OpenAPI).
+//
+// Only JSON documents are handled, as well as JSON bodies
+// validation. References ("$ref") to other local files are
+// resolved.
+type OpenAPI struct {
+	basePaths []string
+	routes    []openAPIRoute
+}
+
+type openAPIRoute struct {
+	template string
+	re       *regexp.Regexp
+	params   []string
+	literals int
+	item     *jsonschema.Schema
+}
+
+var openAPIMethods = []string{
+	"GET", "PUT", "POST", "DELETE", "OPTIONS", "HEAD", "PATCH", "TRACE",
+}
+
+// LoadOpenAPI loads the OpenAPI 3 JSON document contained in
+// "file". Only JSON is supported: a YAML document has to be
+// converted to JSON first.
+func LoadOpenAPI(file string) (*OpenAPI, error) {
+	doc, err := jsonschema.Load(file, jsonschema.Options{})
+	if err != nil {
+		return nil, err
+	}
+
+	root, ok := doc.Node().(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: OpenAPI document must be a JSON object", file)
+	}
+
+	version, _ := root["openapi"].(string)
+	if !strings.HasPrefix(version, "3.") {
+		return nil, fmt.Errorf("%s: OpenAPI 3 document expected, openapi field is %q",
+			file, version)
+	}
+
+	// OpenAPI 3.0 schemas use the "nullable" keyword, since 3.1 they
+	// are plain JSON Schemas
+	if strings.HasPrefix(version, "3.0") {
+		doc, err = jsonschema.Load(file, jsonschema.Options{Nullable: true})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var api OpenAPI
+
+	servers, _ := root["servers"].([]interface{})
+	for _, server := range servers {
+		server, _ := server.(map[string]interface{})
+		if serverURL, ok := server["url"].(string); ok {
+			if u, err := url.Parse(serverURL); err == nil {
+				if basePath := strings.TrimRight(u.Path, "/"); basePath != "" {
+					api.basePaths = append(api.basePaths, basePath)
+				}
+			}
+		}
+	}
+
+	paths, _ := root["paths"].(map[string]interface{})
+	for template, item := range paths {
+		route, err := newOpenAPIRoute(template)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", file, err)
+		}
+		route.item = doc.Sub(item).Deref()
+		api.routes = append(api.routes, route)
+	}
+
+	// The most specific paths first: /person/me before /person/{id}
+	sort.Slice(api.routes, func(i, j int) bool {
+		if api.routes[i].literals != api.routes[j].literals {
+			return api.routes[i].literals > api.routes[j].literals
+		}
+		return api.routes[i].template < api.routes[j].template
+	})
+
+	return &api, nil
+}
+
+var openAPIPathParam = regexp.MustCompile(`\{([^{}/]+)\}`)
+
+func newOpenAPIRoute(template string) (openAPIRoute, error) {
+	route := openAPIRoute{template: template}
+
+	var re bytes.Buffer
+	re.WriteByte('^')
+	last := 0
+	for _, loc := range openAPIPathParam.FindAllStringSubmatchIndex(template, -1) {
+		re.WriteString(regexp.QuoteMeta(template[last:loc[0]]))
+		re.WriteString("([^/]+)")
+		route.literals += loc[0] - last
+		route.params = append(route.params, template[loc[2]:loc[3]])
+		last = loc[1]
+	}
+	re.WriteString(regexp.QuoteMeta(template[last:]))
+	re.WriteByte('$')
+	route.literals += len(template) - last
+
+	var err error
+	route.re, err = regexp.Compile(re.String())
+	if err != nil {
+		return route, fmt.Errorf("bad path %q: %s", template, err)
+	}
+	return route, nil
+}
+
+// openAPIOperation is the OpenAPI operation matching a request.
+type openAPIOperation struct {
+	name       string
+	op         *jsonschema.Schema
+	params     []*jsonschema.Schema
+	pathParams map[string]string
+}
+
+// openAPIErrors accumulates the contract violations.
+type openAPIErrors []*ctxerr.Error
+
+func (e *openAPIErrors) add(path ctxerr.Path, message string, summary string) {
+	*e = append(*e, &ctxerr.Error{
+		Context: ctxerr.Context{Path: path},
+		Message: message,
+		Summary: ctxerr.NewSummary(summary),
+	})
+}
+
+func (e *openAPIErrors) addMismatch(path ctxerr.Path, message string, got, expected interface{}) {
+	*e = append(*e, &ctxerr.Error{
+		Context:  ctxerr.Context{Path: path},
+		Message:  message,
+		Got:      got,
+		Expected: expected,
+	})
+}
+
+func (e *openAPIErrors) addViolations(path ctxerr.Path, violations []jsonschema.Violation) {
+	for _, v := range violations {
+		vPath := path
+		if v.Path != "" {
+			vPath = path.AddCustomLevel(v.Path)
+		}
+		e.add(vPath, fmt.Sprintf("violates %q keyword", v.Keyword), v.Message)
+	}
+}
+
+// merge chains all the errors and returns the first one, or nil if
+// there is no error.
+func (e openAPIErrors) merge() *ctxerr.Error {
+	if len(e) == 0 {
+		return nil
+	}
+	for i := 1; i < len(e); i++ {
+		e[i-1].Next = e[i]
+	}
+	return e[0]
+}
+
+// check checks "req" whose body is "reqBody", then its response
+// "resp", against o. The request contract violations are returned
+// in reqErr, the response ones in respErr. If no operation matches
+// "req", the response is not checked.
+func (o *OpenAPI) check(req *http.Request, reqBody []byte, resp *FlushRecorder) (reqErr, respErr *ctxerr.Error) {
+	op, reqErr := o.operation(req)
+	if reqErr == nil {
+		reqErr = o.checkRequest(op, req, reqBody)
+	}
+	if op != nil {
+		respErr = o.checkResponse(op, req,
+			resp.Code, resp.Header(), resp.Body.Bytes())
+	}
+	return
+}
+
+// operation returns the operation matching "req". If none matches,
+// the returned error explains why.
+func (o *OpenAPI) operation(req *http.Request) (*openAPIOperation, *ctxerr.Error) {
+	reqPath := req.URL.EscapedPath()
+	for _, basePath := range o.basePaths {
+		if strings.HasPrefix(reqPath, basePath+"/") {
+			reqPath = reqPath[len(basePath):]
+			break
+		}
+	}
+
+	var errs openAPIErrors
+
+	for _, route := range o.routes {
+		matches := route.re.FindStringSubmatch(reqPath)
+		if matches == nil {
+			continue
+		}
+
+		item, _ := route.item.Node().(map[string]interface{})
+		method := strings.ToLower(req.Method)
+		op, ok := item[method]
+		if !ok && method == "head" {
+			op, ok = item["get"]
+		}
+		if !ok {
+			var methods []string
+			for _, m := range openAPIMethods {
+				if _, ok := item[strings.ToLower(m)]; ok {
+					methods = append(methods, m)
+				}
+			}
+			errs.addMismatch(ctxerr.NewPath("Request.Method"),
+				"no OpenAPI operation for "+route.template,
+				types.RawString(req.Method),
+				types.RawString(strings.Join(methods, ", ")))
+			return nil, errs.merge()
+		}
+
+		opr := openAPIOperation{
+			name:       req.Method + " " + route.template,
+			op:         route.item.Sub(op).Deref(),
+			pathParams: map[string]string{},
+		}
+		for i, name := range route.params {
+			value, err := url.PathUnescape(matches[i+1])
+			if err != nil {
+				value = matches[i+1]
+			}
+			opr.pathParams[name] = value
+		}
+
+		// Operation parameters override the path ones
+		seen := map[string]bool{}
+		for _, node := range []*jsonschema.Schema{opr.op, route.item} {
+			n, _ := node.Node().(map[string]interface{})
+			params, _ := n["parameters"].([]interface{})
+			for _, param := range params {
+				param := node.Sub(param).Deref()
+				p, _ := param.Node().(map[string]interface{})
+				name, _ := p["name"].(string)
+				in, _ := p["in"].(string)
+				if !seen[in+":"+name] {
+					seen[in+":"+name] = true
+					opr.params = append(opr.params, param)
+				}
+			}
+		}
+		return &opr, nil
+	}
+
+	templates := make([]string, len(o.routes))
+	for i, route := range o.routes {
+		templates[i] = route.template
+	}
+	sort.Strings(templates)
+
+	errs.addMismatch(ctxerr.NewPath("Request.URL.Path"),
+		"no matching OpenAPI path",
+		types.RawString(reqPath),
+		types.RawString(strings.Join(templates, ", ")))
+	return nil, errs.merge()
+}
+
+// checkRequest checks "req" whose body is "body" against "op".
+func (o *OpenAPI) checkRequest(op *openAPIOperation, req *http.Request, body []byte) *ctxerr.Error {
+	var errs openAPIErrors
+
+	for _, param := range op.params {
+		p, _ := param.Node().(map[string]interface{})
+		name, _ := p["name"].(string)
+		required, _ := p["required"].(bool)
+
+		var (
+			values []string
+			path   ctxerr.Path
+		)
+		switch in, _ := p["in"].(string); in {
+		case "path":
+			path = ctxerr.NewPath("Request.URL.Path").AddCustomLevel("{" + name + "}")
+			if value, ok := op.pathParams[name]; ok {
+				values = []string{value}
+			}
+			required = true
+
+		case "query":
+			path = ctxerr.NewPath("Request.URL.Query()").AddMapKey(name)
+			values = req.URL.Query()[name]
+
+		case "header":
+			path = ctxerr.NewPath("Request.Header").AddMapKey(http.CanonicalHeaderKey(name))
+			values = req.Header[http.CanonicalHeaderKey(name)]
+
+		case "cookie":
+			path = ctxerr.NewPath(fmt.Sprintf("Request.Cookie(%q)", name))
+			if cookie, err := req.Cookie(name); err == nil {
+				values = []string{cookie.Value}
+			}
+
+		default:
+			continue
+		}
+
+		if len(values) == 0 {
+			if required {
+				errs.add(path, "missing required parameter",
+					"required by "+op.name+" operation")
+			}
+			continue
+		}
+
+		if schema, ok := p["schema"]; ok {
+			schema := param.Sub(schema)
+			errs.addViolations(path, schema.Validate(paramValue(schema, values)))
+		}
+	}
+
+	opNode, _ := op.op.Node().(map[string]interface{})
+	if requestBody, ok := opNode["requestBody"]; ok {
+		requestBody := op.op.Sub(requestBody).Deref()
+		rb, _ := requestBody.Node().(map[string]interface{})
+
+		if len(body) == 0 {
+			if required, _ := rb["required"].(bool); required {
+				errs.add(ctxerr.NewPath("Request.Body"), "missing required body",
+					"required by "+op.name+" operation")
+			}
+		} else {
+			o.checkContent(&errs, "Request", op, requestBody.Sub(rb["content"]),
+				req.Header, body)
+		}
+	}
+
+	return errs.merge()
+}
+
+// checkResponse checks the response "resp" whose body is "body"
+// against "op".
+func (o *OpenAPI) checkResponse(op *openAPIOperation, req *http.Request, code int, header http.Header, body []byte) *ctxerr.Error {
+	var errs openAPIErrors
+
+	opNode, _ := op.op.Node().(map[string]interface{})
+	responses, _ := opNode["responses"].(map[string]interface{})
+
+	status := strconv.Itoa(code)
+	response, ok := responses[status]
+	if !ok {
+		response, ok = responses[status[:1]+"XX"]
+		if !ok {
+			response, ok = responses[status[:1]+"xx"]
+			if !ok {
+				response, ok = responses["default"]
+			}
+		}
+	}
+	if !ok {
+		documented := make([]string, 0, len(responses))
+		for key := range responses {
+			documented = append(documented, key)
+		}
+		sort.Strings(documented)
+
+		errs.addMismatch(ctxerr.NewPath("Response.Status"),
+			"status not documented by "+op.name+" operation",
+			code,
+			types.RawString(strings.Join(documented, ", ")))
+		return errs.merge()
+	}
+
+	resp := op.op.Sub(response).Deref()
+	r, _ := resp.Node().(map[string]interface{})
+
+	headers, _ := r["headers"].(map[string]interface{})
+	for _, name := range sortedKeys(headers) {
+		key := http.CanonicalHeaderKey(name)
+		if key == "Content-Type" {
+			continue // ignored by OpenAPI
+		}
+
+		h := resp.Sub(headers[name]).Deref()
+		hn, _ := h.Node().(map[string]interface{})
+		path := ctxerr.NewPath("Response.Header").AddMapKey(key)
+
+		values := header[key]
+		if len(values) == 0 {
+			if required, _ := hn["required"].(bool); required {
+				errs.add(path, "missing required header",
+					"required by "+op.name+" operation")
+			}
+			continue
+		}
+
+		if schema, ok := hn["schema"]; ok {
+			schema := h.Sub(schema)
+			errs.addViolations(path, schema.Validate(paramValue(schema, values)))
+		}
+	}
+
+	if content, ok := r["content"]; ok {
+		body, err := decodeContentEncoding(header, body)
+		switch {
+		case err != nil:
+			errs.add(ctxerr.NewPath("Response.Body"), "body decoding", err.Error())
+
+		case len(body) == 0:
+			if req.Method != "HEAD" {
+				errs.add(ctxerr.NewPath("Response.Body"), "missing body",
+					"content expected by "+op.name+" operation")
+			}
+
+		default:
+			o.checkContent(&errs, "Response", op, resp.Sub(content), header, body)
+		}
+	}
+
+	return errs.merge()
+}
+
+// checkContent checks "body" whose header is "header" against the
+// OpenAPI content map "content". Only JSON bodies are validated
+// against their schema.
+func (o *OpenAPI) checkContent(errs *openAPIErrors, root string, op *openAPIOperation, content *jsonschema.Schema, header http.Header, body []byte) {
+	c, _ := content.Node().(map[string]interface{})
+	if len(c) == 0 {
+		return
+	}
+
+	contentType := header.Get("Content-Type")
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	var (
+		mt    interface{}
+		found bool
+	)
+	for _, candidate := range []string{
+		mediaType,
+		mediaType[:strings.IndexByte(mediaType+"/", '/')] + "/*",
+		"*/*",
+	} {
+		for key, value := range c {
+			keyType, _, err := mime.ParseMediaType(key)
+			if err == nil && keyType == candidate {
+				mt, found = value, true
+				break
+			}
+		}
+		if found {
+			break
+		}
+	}
+
+	if !found {
+		errs.addMismatch(ctxerr.NewPath(root+".Header").AddMapKey("Content-Type"),
+			"Content-Type not documented by "+op.name+" operation",
+			types.RawString(contentType),
+			types.RawString(strings.Join(sortedKeys(c), ", ")))
+		return
+	}
+
+	if !isJSONMediaType(mediaType) {
+		return
+	}
+
+	mtNode, _ := mt.(map[string]interface{})
+	schema, ok := mtNode["schema"]
+	if !ok {
+		return
+	}
+
+	value, err := jsonschema.Decode(body)
+	if err != nil {
+		errs.add(ctxerr.NewPath(root+".Body"), "JSON decoding", err.Error())
+		return
+	}
+	errs.addViolations(ctxerr.NewPath(root+".Body"),
+		content.Sub(schema).Validate(value))
+}
+
+// paramValue converts the parameter or header "values" to a JSON
+// value, according to the type of "schema".
+func paramValue(schema *jsonschema.Schema, values []string) interface{} {
+	node, _ := schema.Deref().Node().(map[string]interface{})
+
+	switch schemaType(node) {
+	case "array":
+		if len(values) == 1 {
+			values = strings.Split(values[0], ",")
+		}
+		items := schema.Deref().Sub(node["items"])
+		array := make([]interface{}, len(values))
+		for i, value := range values {
+			array[i] = paramValue(items, []string{value})
+		}
+		return array
+
+	case "integer", "number":
+		if num, err := jsonschema.Decode([]byte(values[0])); err == nil {
+			if _, ok := num.(json.Number); ok {
+				return num
+			}
+		}
+
+	case "boolean":
+		if b, err := strconv.ParseBool(values[0]); err == nil {
+			return b
+		}
+	}
+	return values[0]
+}
+
+// schemaType returns the first non-null type of "node".
+func schemaType(node map[string]interface{}) string {
+	switch t := node["type"].(type) {
+	case string:
+		return t
+	case []interface{}:
+		for _, item := range t {
+			if str, ok := item.(string); ok && str != "null" {
+				return str
+			}
+		}
+	}
+	return ""
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
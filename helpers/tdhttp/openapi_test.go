@@ -0,0 +1,454 @@
+// Copyright (c) 2019, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package tdhttp_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	td "github.com/maxatome/go-testdeep"
+	"github.com/maxatome/go-testdeep/helpers/tdhttp"
+)
+
+const openAPIDoc = `{
+  "openapi": "3.0.3",
+  "info": {"title": "Persons", "version": "1.0.0"},
+  "servers": [{"url": "http://example.com/v1"}],
+  "paths": {
+    "/person/{id}": {
+      "parameters": [
+        {"name": "id", "in": "path", "required": true,
+         "schema": {"type": "integer", "minimum": 1}}
+      ],
+      "get": {
+        "parameters": [
+          {"name": "X-Trace", "in": "header", "required": true,
+           "schema": {"type": "string", "pattern": "^[0-9a-f]+$"}}
+        ],
+        "responses": {
+          "200": {
+            "description": "The person",
+            "headers": {
+              "X-Rate": {"required": true, "schema": {"type": "integer"}}
+            },
+            "content": {
+              "application/json": {"schema": {"$ref": "schemas.json#/Person"}}
+            }
+          },
+          "404": {"$ref": "#/components/responses/NotFound"}
+        }
+      }
+    },
+    "/person/me": {
+      "get": {
+        "responses": {
+          "200": {
+            "description": "Me",
+            "content": {
+              "application/json": {"schema": {"$ref": "schemas.json#/Person"}}
+            }
+          }
+        }
+      }
+    },
+    "/persons": {
+      "get": {
+        "parameters": [
+          {"name": "limit", "in": "query",
+           "schema": {"type": "integer", "maximum": 10}},
+          {"name": "tags", "in": "query",
+           "schema": {"type": "array", "items": {"type": "string", "enum": ["a", "b"]}}}
+        ],
+        "responses": {
+          "2XX": {
+            "description": "The persons",
+            "content": {
+              "application/json": {
+                "schema": {"type": "array", "items": {"$ref": "schemas.json#/Person"}}
+              }
+            }
+          }
+        }
+      },
+      "post": {
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {"schema": {"$ref": "schemas.json#/Person"}}
+          }
+        },
+        "responses": {
+          "201": {"description": "Created"},
+          "default": {"$ref": "#/components/responses/NotFound"}
+        }
+      }
+    }
+  },
+  "components": {
+    "responses": {
+      "NotFound": {"description": "Error", "content": {"text/*": {}}}
+    }
+  }
+}`
+
+const openAPISchemas = `{
+  "Person": {
+    "type": "object",
+    "required": ["id", "name"],
+    "properties": {
+      "id":   {"type": "integer", "minimum": 1},
+      "name": {"type": "string", "minLength": 1},
+      "age":  {"type": "integer", "minimum": 0, "nullable": true}
+    },
+    "additionalProperties": false
+  }
+}`
+
+func writeOpenAPI(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "tdhttp")
+	if err != nil {
+		t.Fatalf("TempDir failed: %s", err)
+	}
+	for name, content := range files {
+		err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+		if err != nil {
+			os.RemoveAll(dir) // nolint: errcheck
+			t.Fatalf("WriteFile failed: %s", err)
+		}
+	}
+	return dir
+}
+
+func respondHandler(status int, header http.Header, body string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		for key, values := range header {
+			w.Header()[key] = values
+		}
+		w.WriteHeader(status)
+		w.Write([]byte(body)) // nolint: errcheck
+	})
+}
+
+func TestOpenAPI(t *testing.T) {
+	dir := writeOpenAPI(t, map[string]string{
+		"openapi.json": openAPIDoc,
+		"schemas.json": openAPISchemas,
+		"bad.json":     `{"swagger": "2.0"}`,
+		"badref.json":  `{"openapi": "3.1.0", "paths": {"/": {"$ref": "unknown.json"}}}`,
+	})
+	defer os.RemoveAll(dir) // nolint: errcheck
+
+	api, err := tdhttp.LoadOpenAPI(filepath.Join(dir, "openapi.json"))
+	if err != nil {
+		t.Fatalf("LoadOpenAPI failed: %s", err)
+	}
+
+	jsonHeader := func(kv ...string) http.Header {
+		h := http.Header{"Content-Type": []string{"application/json"}}
+		for i := 0; i < len(kv); i += 2 {
+			h.Set(kv[i], kv[i+1])
+		}
+		return h
+	}
+
+	t.Run("Respected", func(t *testing.T) {
+		for _, tc := range []struct {
+			name    string
+			handler http.Handler
+			req     func(ta *tdhttp.TestAPI)
+		}{
+			{
+				name: "get person",
+				handler: respondHandler(http.StatusOK, jsonHeader("X-Rate", "12"),
+					`{"id":12,"name":"Bob","age":null}`),
+				req: func(ta *tdhttp.TestAPI) { ta.Get("/v1/person/12", "X-Trace", "a0") },
+			},
+			{
+				name: "person not found",
+				handler: respondHandler(http.StatusNotFound,
+					http.Header{"Content-Type": []string{"text/plain"}}, "Not found"),
+				req: func(ta *tdhttp.TestAPI) { ta.Get("/v1/person/12", "X-Trace", "a0") },
+			},
+			{
+				name: "head person",
+				handler: respondHandler(http.StatusOK, jsonHeader("X-Rate", "12"),
+					""),
+				req: func(ta *tdhttp.TestAPI) { ta.Head("/v1/person/12", "X-Trace", "a0") },
+			},
+			{
+				name:    "me before {id}",
+				handler: respondHandler(http.StatusOK, jsonHeader(), `{"id":1,"name":"Me"}`),
+				req:     func(ta *tdhttp.TestAPI) { ta.Get("/v1/person/me") },
+			},
+			{
+				name:    "list persons",
+				handler: respondHandler(http.StatusPartialContent, jsonHeader(), `[]`),
+				req: func(ta *tdhttp.TestAPI) {
+					ta.Get("/v1/persons?limit=10&tags=a&tags=b")
+				},
+			},
+			{
+				name:    "create person",
+				handler: respondHandler(http.StatusCreated, nil, ""),
+				req: func(ta *tdhttp.TestAPI) {
+					ta.PostJSON("/v1/persons", Person{ID: 2, Name: "Alice"})
+				},
+			},
+		} {
+			mockT := &recordT{}
+			ta := tdhttp.NewTestAPI(mockT, tc.handler).SetOpenAPI(api)
+			tc.req(ta)
+			td.CmpFalse(t, ta.Failed(), tc.name)
+			td.CmpEmpty(t, mockT.Errors(), tc.name)
+		}
+	})
+
+	t.Run("Violated", func(t *testing.T) {
+		for _, tc := range []struct {
+			name     string
+			handler  http.Handler
+			req      func(ta *tdhttp.TestAPI)
+			expected []string
+		}{
+			{
+				name:    "unknown path",
+				handler: respondHandler(http.StatusOK, nil, ""),
+				req:     func(ta *tdhttp.TestAPI) { ta.Get("/v1/unknown") },
+				expected: []string{
+					"Failed test 'request should respect OpenAPI contract'",
+					"Request.URL.Path: no matching OpenAPI path",
+					"/person/me, /person/{id}, /persons",
+				},
+			},
+			{
+				name:    "unknown operation",
+				handler: respondHandler(http.StatusOK, nil, ""),
+				req:     func(ta *tdhttp.TestAPI) { ta.Delete("/v1/persons", nil) },
+				expected: []string{
+					"Request.Method: no OpenAPI operation for /persons",
+					"GET, POST",
+				},
+			},
+			{
+				name: "bad parameters",
+				handler: respondHandler(http.StatusOK, jsonHeader("X-Rate", "12"),
+					`{"id":12,"name":"Bob"}`),
+				req: func(ta *tdhttp.TestAPI) { ta.Get("/v1/person/0") },
+				expected: []string{
+					`Request.URL.Path{id}: violates "minimum" keyword`,
+					"expected ≥ 1, got 0",
+					`Request.Header["X-Trace"]: missing required parameter`,
+					"required by GET /person/{id} operation",
+				},
+			},
+			{
+				name:    "bad query",
+				handler: respondHandler(http.StatusOK, jsonHeader(), `[]`),
+				req: func(ta *tdhttp.TestAPI) {
+					ta.Get("/v1/persons?limit=11&tags=a,c")
+				},
+				expected: []string{
+					`Request.URL.Query()["limit"]: violates "maximum" keyword`,
+					"expected ≤ 10, got 11",
+					`Request.URL.Query()["tags"]/1: violates "enum" keyword`,
+					`expected one of ["a","b"], got "c"`,
+				},
+			},
+			{
+				name:    "bad request body",
+				handler: respondHandler(http.StatusCreated, nil, ""),
+				req: func(ta *tdhttp.TestAPI) {
+					ta.PostJSON("/v1/persons", map[string]interface{}{"id": 2, "nick": "Al"})
+				},
+				expected: []string{
+					`Request.Body: violates "required" keyword`,
+					`missing required property "name"`,
+					`Request.Body/nick: violates "additionalProperties" keyword`,
+					"property not allowed",
+				},
+			},
+			{
+				name:    "missing request body",
+				handler: respondHandler(http.StatusCreated, nil, ""),
+				req:     func(ta *tdhttp.TestAPI) { ta.Post("/v1/persons", nil) },
+				expected: []string{
+					"Request.Body: missing required body",
+					"required by POST /persons operation",
+				},
+			},
+			{
+				name:    "bad request Content-Type",
+				handler: respondHandler(http.StatusCreated, nil, ""),
+				req: func(ta *tdhttp.TestAPI) {
+					ta.PostXML("/v1/persons", Person{ID: 2, Name: "Alice"})
+				},
+				expected: []string{
+					`Request.Header["Content-Type"]: Content-Type not documented by POST /persons operation`,
+				},
+			},
+			{
+				name:    "bad status",
+				handler: respondHandler(http.StatusTeapot, nil, ""),
+				req:     func(ta *tdhttp.TestAPI) { ta.Get("/v1/person/me") },
+				expected: []string{
+					"Failed test 'response should respect OpenAPI contract'",
+					"Response.Status: status not documented by GET /person/me operation",
+					"418",
+				},
+			},
+			{
+				name: "bad response header and body",
+				handler: respondHandler(http.StatusOK, jsonHeader("X-Rate", "many"),
+					`{"id":12,"name":"","age":-1}`),
+				req: func(ta *tdhttp.TestAPI) { ta.Get("/v1/person/12", "X-Trace", "a0") },
+				expected: []string{
+					`Response.Header["X-Rate"]: violates "type" keyword`,
+					"expected integer, got string",
+					`Response.Body/age: violates "minimum" keyword`,
+					"expected ≥ 0, got -1",
+					`Response.Body/name: violates "minLength" keyword`,
+					"expected at least 1 characters, got 0",
+				},
+			},
+			{
+				name:    "missing response header and body",
+				handler: respondHandler(http.StatusOK, jsonHeader(), ""),
+				req:     func(ta *tdhttp.TestAPI) { ta.Get("/v1/person/12", "X-Trace", "a0") },
+				expected: []string{
+					`Response.Header["X-Rate"]: missing required header`,
+					"Response.Body: missing body",
+				},
+			},
+			{
+				name:    "bad response Content-Type",
+				handler: respondHandler(http.StatusNotFound, jsonHeader(), "{}"),
+				req:     func(ta *tdhttp.TestAPI) { ta.Get("/v1/person/12", "X-Trace", "a0") },
+				expected: []string{
+					`Response.Header["Content-Type"]: Content-Type not documented by GET /person/{id} operation`,
+				},
+			},
+			{
+				name:    "bad response JSON",
+				handler: respondHandler(http.StatusOK, jsonHeader(), "{"),
+				req:     func(ta *tdhttp.TestAPI) { ta.Get("/v1/person/me") },
+				expected: []string{
+					"Response.Body: JSON decoding",
+				},
+			},
+		} {
+			mockT := &recordT{}
+			ta := tdhttp.NewTestAPI(mockT, tc.handler).SetOpenAPI(api)
+			tc.req(ta)
+			td.CmpTrue(t, ta.Failed(), tc.name)
+
+			expected := make([]interface{}, len(tc.expected))
+			for i, str := range tc.expected {
+				expected[i] = td.Contains(str)
+			}
+			td.Cmp(t, mockT.Errors(), td.All(expected...), tc.name)
+			// Request & response dumped once
+			td.Cmp(t, mockT.Logs(), td.Contains("Request:\n"), tc.name)
+		}
+	})
+
+	t.Run("Name", func(t *testing.T) {
+		mockT := &recordT{}
+		tdhttp.NewTestAPI(mockT, respondHandler(http.StatusTeapot, nil, "")).
+			SetOpenAPI(api).
+			Name("Teapot").
+			Get("/v1/person/me")
+		td.Cmp(t, mockT.Errors(),
+			td.Contains("Failed test 'Teapot: response should respect OpenAPI contract'"))
+	})
+
+	t.Run("UseOpenAPI", func(t *testing.T) {
+		ta := tdhttp.NewTestAPI(t, respondHandler(http.StatusCreated, nil, "")).
+			UseOpenAPI(filepath.Join(dir, "openapi.json"))
+		td.CmpNotNil(t, ta.OpenAPI())
+
+		ta.PostJSON("/v1/persons", Person{ID: 2, Name: "Alice"}).
+			CmpStatus(http.StatusCreated)
+
+		td.CmpNil(t, ta.SetOpenAPI(nil).OpenAPI())
+
+		mockT := &recordT{}
+		tdhttp.NewTestAPI(mockT, http.NotFoundHandler()).
+			UseOpenAPI(filepath.Join(dir, "unknown.json"))
+		td.Cmp(t, mockT.Errors(), td.Contains("Cannot load OpenAPI document: "))
+	})
+
+	t.Run("CmpResponse", func(t *testing.T) {
+		handler := respondHandler(http.StatusOK, jsonHeader("X-Rate", "12"),
+			`{"id":12,"name":"Bob"}`).ServeHTTP
+
+		req := tdhttp.NewRequest("GET", "/v1/person/12", nil)
+		req.Header.Set("X-Trace", "a0")
+		td.CmpTrue(t, tdhttp.CmpJSONResponse(t, req, handler,
+			tdhttp.Response{
+				OpenAPI: api,
+				Status:  http.StatusOK,
+				Body:    td.SuperMapOf(map[string]interface{}{"name": "Bob"}, nil),
+			}))
+
+		mockT := &recordT{}
+		td.CmpFalse(t, tdhttp.CmpJSONResponse(mockT,
+			tdhttp.NewRequest("GET", "/v1/person/0", nil),
+			handler,
+			tdhttp.Response{
+				OpenAPI: api,
+				Status:  http.StatusOK,
+				Body:    td.Ignore(),
+			}))
+		td.Cmp(t, mockT.Errors(), td.All(
+			td.Contains("Failed test 'request should respect OpenAPI contract'"),
+			td.Contains(`Request.URL.Path{id}: violates "minimum" keyword`),
+			td.Contains(`Request.Header["X-Trace"]: missing required parameter`),
+			td.Not(td.Contains("response should respect OpenAPI contract")),
+		))
+
+		// Request body still readable by the handler, redirections
+		// following or not
+		req = tdhttp.NewRequest("POST", "/v1/persons",
+			strings.NewReader(`{"id":2,"nick":"Al"}`))
+		req.Header.Set("Content-Type", "application/json")
+		mockT = &recordT{}
+		td.CmpFalse(t, tdhttp.CmpResponse(mockT, req,
+			func(w http.ResponseWriter, req *http.Request) {
+				body, _ := ioutil.ReadAll(req.Body)
+				if string(body) != `{"id":2,"nick":"Al"}` {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				w.WriteHeader(http.StatusTeapot)
+			},
+			tdhttp.Response{
+				OpenAPI:   api,
+				Redirects: td.Empty(),
+				Status:    http.StatusTeapot,
+			}))
+		td.Cmp(t, mockT.Errors(), td.All(
+			td.Contains(`Request.Body: violates "required" keyword`),
+			td.Contains(`Request.Body/nick: violates "additionalProperties" keyword`),
+			td.Contains("Failed test 'response should respect OpenAPI contract'"),
+			td.Contains("Response.Body: missing body"),
+		))
+	})
+
+	t.Run("LoadOpenAPI errors", func(t *testing.T) {
+		_, err := tdhttp.LoadOpenAPI(filepath.Join(dir, "bad.json"))
+		td.Cmp(t, err, td.HasSuffix(`OpenAPI 3 document expected, openapi field is ""`))
+
+		_, err = tdhttp.LoadOpenAPI(filepath.Join(dir, "badref.json"))
+		td.Cmp(t, err, td.HasPrefix(`cannot resolve $ref "unknown.json": `))
+	})
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2019, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package tdhttp
+
+import (
+	"net/http/httptest"
+)
+
+// FlushRecorder is an httptest.ResponseRecorder that also records
+// each time the handler flushes the response, allowing to check
+// that a streamed body was sent in several chunks.
+type FlushRecorder struct {
+	*httptest.ResponseRecorder
+	flushes []int // body length at each flush
+}
+
+// NewFlushRecorder returns an initialized FlushRecorder.
+func NewFlushRecorder() *FlushRecorder {
+	return &FlushRecorder{
+		ResponseRecorder: httptest.NewRecorder(),
+	}
+}
+
+// Flush implements http.Flusher, recording the body length at
+// flushing time.
+func (r *FlushRecorder) Flush() {
+	r.ResponseRecorder.Flush()
+
+	size := r.Body.Len()
+	if len(r.flushes) == 0 || r.flushes[len(r.flushes)-1] != size {
+		r.flushes = append(r.flushes, size)
+	}
+}
+
+// Chunks returns the body split at each flush. Data written after
+// the last flush, if any, is returned as the last chunk. Flushes not
+// preceded by any write are ignored.
+func (r *FlushRecorder) Chunks() []string {
+	body := r.Body.String()
+
+	chunks := make([]string, 0, len(r.flushes)+1)
+	start := 0
+	for _, end := range r.flushes {
+		if end > start {
+			chunks = append(chunks, body[start:end])
+			start = end
+		}
+	}
+	if start < len(body) {
+		chunks = append(chunks, body[start:])
+	}
+	return chunks
+}
@@ -0,0 +1,37 @@
+// Copyright (c) 2019, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package tdhttp_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	td "github.com/maxatome/go-testdeep"
+	"github.com/maxatome/go-testdeep/helpers/tdhttp"
+)
+
+func TestFlushRecorder(tt *testing.T) {
+	t := td.NewT(tt)
+
+	w := tdhttp.NewFlushRecorder()
+	var _ http.Flusher = w
+	t.Cmp(w.Chunks(), []string{})
+
+	fmt.Fprint(w, "foo")
+	w.Flush()
+	w.Flush() // nothing written since previous flush
+	fmt.Fprint(w, "bar")
+	fmt.Fprint(w, "baz")
+	w.Flush()
+	t.Cmp(w.Chunks(), []string{"foo", "barbaz"})
+	t.True(w.Flushed)
+
+	fmt.Fprint(w, "end")
+	t.Cmp(w.Chunks(), []string{"foo", "barbaz", "end"})
+	t.String(w.Body.String(), "foobarbazend")
+}
@@ -0,0 +1,163 @@
+// Copyright (c) 2019, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package tdhttp
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+)
+
+// Redirect is a redirection followed by a TestAPI, see
+// TestAPI.FollowRedirects and TestAPI.CmpRedirects, or by Cmp*Response
+// functions, see Response.Redirects.
+type Redirect struct {
+	Status   int    // Status is the 3xx status code of the response
+	Location string // Location is the Location header of the response
+}
+
+// isRedirect returns true if "status" is a redirection status code
+// that can be followed.
+func isRedirect(status int) bool {
+	switch status {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
+
+// redirectRequest returns the request to send to follow the
+// "status" redirection to "location" in response to "req" whose body
+// is "body".
+func redirectRequest(req *http.Request, body []byte, status int, location string) (*http.Request, error) {
+	u, err := requestURL(req).Parse(location)
+	if err != nil {
+		return nil, err
+	}
+
+	method := req.Method
+	keepBody := true
+	if status != http.StatusTemporaryRedirect && status != http.StatusPermanentRedirect &&
+		method != "GET" && method != "HEAD" {
+		method = "GET"
+		keepBody = false
+	}
+
+	var reqBody io.Reader
+	if keepBody && body != nil {
+		reqBody = bytes.NewReader(body)
+	}
+
+	next := NewRequest(method, u.String(), reqBody)
+	next.RequestURI = u.RequestURI() // as received by a server
+	for key, values := range req.Header {
+		switch key {
+		case "Cookie": // re-added by the caller and from the cookie jar
+			continue
+		case "Content-Type", "Content-Length":
+			if !keepBody {
+				continue
+			}
+		}
+		next.Header[key] = append([]string(nil), values...)
+	}
+	return next.WithContext(req.Context()), nil
+}
+
+// redirectChain is the result of serveFollowingRedirects.
+type redirectChain struct {
+	req       *http.Request  // req is the last request sent
+	reqBody   []byte         // reqBody is the body of req
+	response  *FlushRecorder // response is the response to req
+	redirects []Redirect     // redirects are the redirections followed
+	tooMany   bool           // tooMany is true if maxRedirects was reached
+}
+
+// serveFollowingRedirects sends "req", whose body is "body", to
+// "handler", then follows up to "maxRedirects" redirections, each
+// one being sent to "handler" too. Cookies are preserved across
+// redirections using "jar", a new one being used if it is nil. The
+// Cookie header of "req", if any, is sent with each redirection too.
+func serveFollowingRedirects(handler http.Handler, req *http.Request, body []byte,
+	maxRedirects int, jar http.CookieJar) (redirectChain, error) {
+	if jar == nil {
+		jar = newCookieJar()
+	}
+	userCookies := append([]string(nil), req.Header["Cookie"]...)
+
+	chain := redirectChain{
+		req:      req,
+		reqBody:  body,
+		response: serve(handler, req, jar),
+	}
+
+	for isRedirect(chain.response.Code) {
+		location := chain.response.Header().Get("Location")
+		if location == "" {
+			break
+		}
+		if len(chain.redirects) == maxRedirects {
+			chain.tooMany = true
+			break
+		}
+
+		chain.redirects = append(chain.redirects, Redirect{
+			Status:   chain.response.Code,
+			Location: location,
+		})
+
+		next, err := redirectRequest(chain.req, chain.reqBody, chain.response.Code, location)
+		if err != nil {
+			return chain, fmt.Errorf("cannot follow redirection to %q: %s", location, err)
+		}
+		if next.Method != chain.req.Method { // body dropped
+			chain.reqBody = nil
+		}
+		if len(userCookies) > 0 {
+			next.Header["Cookie"] = append([]string(nil), userCookies...)
+		}
+		chain.req = next
+		chain.response = serve(handler, next, jar)
+	}
+	return chain, nil
+}
+
+// serve sends "req" to "handler" and returns the recorded
+// response. If "jar" is non-nil, its cookies are added to "req" and
+// the cookies set by the response are stored in it.
+func serve(handler http.Handler, req *http.Request, jar http.CookieJar) *FlushRecorder {
+	var jarURL *url.URL
+	if jar != nil {
+		jarURL = jarURLOf(req)
+		for _, cookie := range jar.Cookies(jarURL) {
+			req.AddCookie(cookie)
+		}
+	}
+
+	response := NewFlushRecorder()
+	handler.ServeHTTP(response, req)
+
+	if jar != nil {
+		if cookies := response.Result().Cookies(); len(cookies) > 0 {
+			jar.SetCookies(jarURL, cookies)
+		}
+	}
+	return response
+}
+
+// newCookieJar returns a new empty cookie jar.
+func newCookieJar() http.CookieJar {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		panic(err) // cannot happen, as no options are passed
+	}
+	return jar
+}
@@ -0,0 +1,277 @@
+// Copyright (c) 2019, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package tdhttp_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	td "github.com/maxatome/go-testdeep"
+	"github.com/maxatome/go-testdeep/helpers/tdhttp"
+)
+
+func loginMux() *http.ServeMux {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/login", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != "POST" {
+			fmt.Fprint(w, "Login form")
+			return
+		}
+		http.SetCookie(w, &http.Cookie{
+			Name:  "session",
+			Value: req.FormValue("user"),
+			Path:  "/",
+		})
+		http.Redirect(w, req, "/home", http.StatusSeeOther)
+	})
+
+	mux.HandleFunc("/home", func(w http.ResponseWriter, req *http.Request) {
+		cookie, err := req.Cookie("session")
+		if err != nil {
+			http.Redirect(w, req, "/login?from=home", http.StatusFound)
+			return
+		}
+		fmt.Fprintf(w, "Welcome %s! (%s)", cookie.Value, req.Method)
+	})
+
+	mux.HandleFunc("/to-home", func(w http.ResponseWriter, req *http.Request) {
+		http.Redirect(w, req, "/home", http.StatusFound)
+	})
+
+	mux.HandleFunc("/old", func(w http.ResponseWriter, req *http.Request) {
+		http.Redirect(w, req, "http://other.com/temp", http.StatusMovedPermanently)
+	})
+
+	mux.HandleFunc("/temp", func(w http.ResponseWriter, req *http.Request) {
+		if req.Host != "other.com" {
+			http.NotFound(w, req)
+			return
+		}
+		http.Redirect(w, req, "echo", http.StatusTemporaryRedirect)
+	})
+
+	mux.HandleFunc("/echo", func(w http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+		fmt.Fprintf(w, "%s %s: %s", req.Method, req.Header.Get("X-Custom"), body)
+	})
+
+	mux.HandleFunc("/loop", func(w http.ResponseWriter, req *http.Request) {
+		http.Redirect(w, req, "/loop", http.StatusFound)
+	})
+
+	mux.HandleFunc("/nolocation", func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusFound)
+	})
+
+	return mux
+}
+
+func TestTestAPIRedirects(t *testing.T) {
+	mux := loginMux()
+
+	t.Run("Not followed", func(t *testing.T) {
+		tdhttp.NewTestAPI(t, mux).
+			PostForm("/login", url.Values{"user": []string{"bob"}}).
+			CmpStatus(http.StatusSeeOther).
+			CmpHeader(td.SuperMapOf(http.Header{"Location": []string{"/home"}}, nil)).
+			CmpRedirects([]tdhttp.Redirect{})
+	})
+
+	t.Run("Followed", func(t *testing.T) {
+		ta := tdhttp.NewTestAPI(t, mux).FollowRedirects(5)
+
+		// Cookies preserved even without jar
+		ta.PostForm("/login", url.Values{"user": []string{"bob"}}).
+			CmpRedirects([]tdhttp.Redirect{
+				{Status: http.StatusSeeOther, Location: "/home"},
+			}).
+			CmpStatus(http.StatusOK).
+			CmpBody("Welcome bob! (GET)")
+		td.CmpFalse(t, ta.Failed())
+		td.CmpNil(t, ta.CookieJar())
+
+		// But not across requests without jar
+		ta.Get("/home").
+			CmpRedirects([]tdhttp.Redirect{
+				{Status: http.StatusFound, Location: "/login?from=home"},
+			}).
+			CmpStatus(http.StatusOK).
+			CmpBody("Login form")
+		td.CmpFalse(t, ta.Failed())
+
+		// With a jar, the session is kept across requests
+		ta.UseCookieJar().
+			PostForm("/login", url.Values{"user": []string{"alice"}}).
+			CmpRedirects(td.Len(1))
+		ta.Get("/home").
+			CmpRedirects(td.Empty()).
+			CmpBody("Welcome alice! (GET)")
+		td.CmpFalse(t, ta.Failed())
+
+		// 301 turns POST into GET, headers are kept
+		ta.Post("/old", strings.NewReader("data"), "X-Custom", "zip").
+			CmpRedirects(td.Slice([]tdhttp.Redirect{}, td.ArrayEntries{
+				0: tdhttp.Redirect{
+					Status:   http.StatusMovedPermanently,
+					Location: "http://other.com/temp",
+				},
+				1: td.Struct(tdhttp.Redirect{Status: http.StatusTemporaryRedirect},
+					td.StructFields{"Location": td.HasSuffix("echo")}),
+			})).
+			CmpStatus(http.StatusOK).
+			CmpBody("GET zip: ")
+		td.CmpFalse(t, ta.Failed())
+
+		// 307 keeps method, body & headers
+		req := tdhttp.NewRequest("PUT", "http://other.com/temp", strings.NewReader("data"))
+		req.Header.Set("X-Custom", "zip")
+		ta.Request(req).
+			CmpRedirects([]tdhttp.Redirect{
+				{Status: http.StatusTemporaryRedirect, Location: "/echo"},
+			}).
+			CmpStatus(http.StatusOK).
+			CmpBody("PUT zip: data")
+		td.CmpFalse(t, ta.Failed())
+
+		// A caller-set Cookie header is kept across redirects
+		ta.SetCookieJar(nil).
+			Get("/to-home", "Cookie", "session=carol").
+			CmpRedirects([]tdhttp.Redirect{
+				{Status: http.StatusFound, Location: "/home"},
+			}).
+			CmpStatus(http.StatusOK).
+			CmpBody("Welcome carol! (GET)")
+		td.CmpFalse(t, ta.Failed())
+
+		// Redirect without Location is not followed
+		ta.Get("/nolocation").
+			CmpStatus(http.StatusFound).
+			CmpRedirects(td.Empty())
+
+		// Disable
+		ta.FollowRedirects(0).
+			Get("/loop").
+			CmpStatus(http.StatusFound)
+	})
+
+	t.Run("Too many redirects", func(t *testing.T) {
+		mockT := &recordT{}
+		ta := tdhttp.NewTestAPI(mockT, mux).FollowRedirects(3)
+
+		ta.Name("Loop").
+			Get("/loop").
+			CmpStatus(http.StatusFound).
+			CmpRedirects(td.Len(3))
+		td.CmpTrue(t, ta.Failed())
+		td.Cmp(t, mockT.errors, td.Len(1))
+		td.Cmp(t, mockT.Errors(), td.Contains("Loop: stopped after 3 redirects"))
+		td.Cmp(t, mockT.Logs(), td.All(
+			td.Contains("Redirects:\n302 → /loop\n302 → /loop\n302 → /loop\n"),
+			td.Contains("Request:\nGET /loop HTTP/1.1"),
+		))
+	})
+
+	t.Run("Failure", func(t *testing.T) {
+		mockT := &recordT{}
+		ta := tdhttp.NewTestAPI(mockT, mux)
+
+		ta.CmpRedirects(td.Empty())
+		td.Cmp(t, mockT.Errors(),
+			td.Contains("A request must be sent before testing the response"))
+
+		ta.FollowRedirects(1).
+			Get("/home").
+			CmpRedirects(td.Empty())
+		td.CmpTrue(t, ta.Failed())
+		td.Cmp(t, mockT.Errors(), td.Contains("Failed test 'redirects should match'"))
+	})
+}
+
+func TestCmpResponseRedirects(t *testing.T) {
+	mux := loginMux()
+
+	// Not followed by default
+	td.CmpTrue(t, tdhttp.CmpResponse(t,
+		tdhttp.NewRequest("GET", "/to-home", nil),
+		mux.ServeHTTP,
+		tdhttp.Response{
+			Status: http.StatusFound,
+			Body:   td.Ignore(),
+		}))
+
+	// Cookies preserved across redirects
+	td.CmpTrue(t, tdhttp.CmpResponse(t,
+		tdhttp.NewFormRequest("POST", "/login", url.Values{"user": []string{"bob"}}),
+		mux.ServeHTTP,
+		tdhttp.Response{
+			Redirects: []tdhttp.Redirect{
+				{Status: http.StatusSeeOther, Location: "/home"},
+			},
+			Status: http.StatusOK,
+			Body:   "Welcome bob! (GET)",
+		}))
+
+	// Caller-set Cookie header is kept, chain ignored
+	req := tdhttp.NewRequest("GET", "/to-home", nil)
+	req.Header.Set("Cookie", "session=carol")
+	td.CmpTrue(t, tdhttp.CmpResponse(t,
+		req,
+		mux.ServeHTTP,
+		tdhttp.Response{
+			Redirects: td.Ignore(),
+			Status:    http.StatusOK,
+			Body:      "Welcome carol! (GET)",
+		}))
+
+	// 307 keeps method & body
+	req = tdhttp.NewRequest("PUT", "http://other.com/temp", strings.NewReader("data"))
+	req.Header.Set("X-Custom", "zip")
+	td.CmpTrue(t, tdhttp.CmpResponse(t,
+		req,
+		mux.ServeHTTP,
+		tdhttp.Response{
+			Redirects: td.Len(1),
+			Body:      "PUT zip: data",
+		}))
+
+	// No redirection at all
+	td.CmpTrue(t, tdhttp.CmpResponse(t,
+		tdhttp.NewRequest("GET", "/echo", nil),
+		mux.ServeHTTP,
+		tdhttp.Response{
+			Redirects: td.Empty(),
+			Body:      "GET : ",
+		}))
+
+	// Failures
+	mockT := &recordT{}
+	td.CmpFalse(t, tdhttp.CmpResponse(mockT,
+		tdhttp.NewRequest("GET", "/loop", nil),
+		mux.ServeHTTP,
+		tdhttp.Response{
+			Redirects: td.Len(10),
+			Status:    http.StatusFound,
+			Body:      td.Ignore(),
+		}))
+	td.Cmp(t, mockT.errors, td.Len(1))
+	td.Cmp(t, mockT.Errors(), td.Contains("stopped after 10 redirects"))
+
+	mockT = &recordT{}
+	td.CmpFalse(t, tdhttp.CmpResponse(mockT,
+		tdhttp.NewRequest("GET", "/to-home", nil),
+		mux.ServeHTTP,
+		tdhttp.Response{
+			Redirects: td.Empty(),
+			Body:      td.Ignore(),
+		}))
+	td.Cmp(t, mockT.Errors(), td.Contains("Failed test 'redirects should match'"))
+}
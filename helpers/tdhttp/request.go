@@ -8,38 +8,401 @@ package tdhttp
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"encoding/xml"
+	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/textproto"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
 )
 
 // NewRequest is an alias on net/http/httptest.NewRequest for
 // convenience purpose.
 var NewRequest = httptest.NewRequest
 
-// NewJSONRequest creates a new HTTP request with body marshaled to JSON.
-func NewJSONRequest(method, target string, body interface{}) *http.Request {
+// addHeaders adds "headers" to req header. "headers" can contain
+// http.Header instances or pairs of strings (key then value).
+func addHeaders(req *http.Request, headers []interface{}) {
+	for i := 0; i < len(headers); i++ {
+		switch cur := headers[i].(type) {
+		case http.Header:
+			for key, values := range cur {
+				for _, value := range values {
+					req.Header.Add(key, value)
+				}
+			}
+
+		case string:
+			if i+1 >= len(headers) {
+				panic(fmt.Sprintf("headers must be http.Header or string pairs, no value for %q key", cur))
+			}
+			value, ok := headers[i+1].(string)
+			if !ok {
+				panic(fmt.Sprintf("headers must be http.Header or string pairs, %q key value is %T", cur, headers[i+1]))
+			}
+			req.Header.Add(cur, value)
+			i++
+
+		default:
+			panic(fmt.Sprintf("headers must be http.Header or string pairs, not %T", cur))
+		}
+	}
+}
+
+// NewJSONRequest creates a new HTTP request with body marshaled to
+// JSON. "headers" can contain http.Header instances or pairs of
+// strings (key then value), added to the request header. The
+// Content-Type header defaults to "application/json".
+func NewJSONRequest(method, target string, body interface{}, headers ...interface{}) *http.Request {
 	b, err := json.Marshal(body)
 	if err != nil {
 		panic("JSON encoding failed: " + err.Error())
 	}
 
 	req := NewRequest(method, target, bytes.NewBuffer(b))
-	req.Header.Add("Content-Type", "application/json")
+	addHeaders(req, headers)
+	if req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
 
 	return req
 }
 
-// NewXMLRequest creates a new HTTP request with body marshaled to XML.
-func NewXMLRequest(method, target string, body interface{}) *http.Request {
+// NewXMLRequest creates a new HTTP request with body marshaled to
+// XML. "headers" can contain http.Header instances or pairs of
+// strings (key then value), added to the request header. The
+// Content-Type header defaults to "application/xml".
+func NewXMLRequest(method, target string, body interface{}, headers ...interface{}) *http.Request {
 	b, err := xml.Marshal(body)
 	if err != nil {
 		panic("XML encoding failed: " + err.Error())
 	}
 
 	req := NewRequest(method, target, bytes.NewBuffer(b))
-	req.Header.Add("Content-Type", "application/xml")
+	addHeaders(req, headers)
+	if req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/xml")
+	}
+
+	return req
+}
+
+// NewFormRequest creates a new HTTP request with "data" URL-encoded
+// in its body. "data" can be an url.Values, a map[string]string, a
+// map[string][]string or a struct (or a pointer to a struct), see
+// AddQueryParams for details. "headers" can contain http.Header
+// instances or pairs of strings (key then value), added to the
+// request header. The Content-Type header defaults to
+// "application/x-www-form-urlencoded".
+//
+//   req := tdhttp.NewFormRequest("POST", "/login", url.Values{
+//     "user":     []string{"bob"},
+//     "password": []string{"secret"},
+//   })
+func NewFormRequest(method, target string, data interface{}, headers ...interface{}) *http.Request {
+	req := NewRequest(method, target,
+		strings.NewReader(toValues(data).Encode()))
+	addHeaders(req, headers)
+	if req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	return req
+}
+
+// MultipartPart is a part of a multipart/form-data body, see
+// NewMultipartRequest.
+type MultipartPart struct {
+	Name        string    // Name is the form field name
+	FileName    string    // FileName, if non-empty, makes the part a file
+	ContentType string    // ContentType of the part, optional
+	Content     io.Reader // Content is the part content
+}
+
+// quoteEscaper escapes quoted-string parameters of
+// Content-Disposition header, as mime/multipart package does.
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// NewMultipartRequest creates a new HTTP request with a
+// multipart/form-data body built from "parts". A part with a
+// FileName is a file; its ContentType defaults to
+// "application/octet-stream". "headers" can contain http.Header
+// instances or pairs of strings (key then value), added to the
+// request header.
+//
+//   req := tdhttp.NewMultipartRequest("POST", "/upload",
+//     []tdhttp.MultipartPart{
+//       {Name: "comment", Content: strings.NewReader("my photo")},
+//       {
+//         Name:        "photo",
+//         FileName:    "photo.png",
+//         ContentType: "image/png",
+//         Content:     bytes.NewReader(pngData),
+//       },
+//     })
+func NewMultipartRequest(method, target string, parts []MultipartPart, headers ...interface{}) *http.Request {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+
+	for _, part := range parts {
+		h := textproto.MIMEHeader{}
+
+		disposition := `form-data; name="` + quoteEscaper.Replace(part.Name) + `"`
+		contentType := part.ContentType
+		if part.FileName != "" {
+			disposition += `; filename="` + quoteEscaper.Replace(part.FileName) + `"`
+			if contentType == "" {
+				contentType = "application/octet-stream"
+			}
+		}
+		h.Set("Content-Disposition", disposition)
+		if contentType != "" {
+			h.Set("Content-Type", contentType)
+		}
+
+		w, err := mw.CreatePart(h)
+		if err == nil && part.Content != nil {
+			_, err = io.Copy(w, part.Content)
+		}
+		if err != nil {
+			panic(fmt.Sprintf("multipart encoding of %q part failed: %s", part.Name, err))
+		}
+	}
+
+	if err := mw.Close(); err != nil {
+		panic("multipart encoding failed: " + err.Error())
+	}
+
+	req := NewRequest(method, target, &body)
+	addHeaders(req, headers)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	return req
+}
+
+// AddQueryParams returns "target" with "params" added to its query
+// string. "params" can be:
+//   - an url.Values;
+//   - a map[string]string;
+//   - a map[string][]string;
+//   - a struct or a pointer to a struct. Each exported field is
+//     named after its "form" tag, or after its name if the tag is
+//     missing. A "-" tag name skips the field and the "omitempty"
+//     option skips it if it contains its zero value. Fields can be
+//     strings, booleans, numbers, fmt.Stringer or slices of them.
+//     Anonymous struct fields without tag are flattened.
+//
+// It panics if "target" cannot be parsed or "params" type is not
+// handled.
+//
+// For example:
+//
+//   tdhttp.AddQueryParams("/search", map[string]string{"q": "go"})
+//   // returns "/search?q=go"
+//
+//   type Filter struct {
+//     Name  string   `form:"name"`
+//     Tags  []string `form:"tag"`
+//     Limit int      `form:"limit,omitempty"`
+//   }
+//   tdhttp.AddQueryParams("/person?sort=id", Filter{
+//     Name: "Bob",
+//     Tags: []string{"a", "b"},
+//   })
+//   // returns "/person?name=Bob&sort=id&tag=a&tag=b"
+func AddQueryParams(target string, params interface{}) string {
+	u, err := url.Parse(target)
+	if err != nil {
+		panic("target parsing failed: " + err.Error())
+	}
+
+	q := u.Query()
+	for key, values := range toValues(params) {
+		q[key] = append(q[key], values...)
+	}
+	u.RawQuery = q.Encode()
+
+	return u.String()
+}
+
+// toValues converts "data" to url.Values. See AddQueryParams for
+// handled types.
+func toValues(data interface{}) url.Values {
+	switch d := data.(type) {
+	case url.Values:
+		return d
+	case map[string][]string:
+		return url.Values(d)
+	case map[string]string:
+		values := make(url.Values, len(d))
+		for key, value := range d {
+			values.Set(key, value)
+		}
+		return values
+	}
+
+	vdata := reflect.ValueOf(data)
+	if vdata.Kind() == reflect.Ptr && !vdata.IsNil() {
+		vdata = vdata.Elem()
+	}
+	if vdata.Kind() != reflect.Struct {
+		panic(fmt.Sprintf(
+			"url.Values, map[string]string, map[string][]string or struct expected, not %T",
+			data))
+	}
+
+	values := url.Values{}
+	structToValues(values, vdata)
+	return values
+}
+
+var stringerType = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+
+// structToValues adds the fields of "vstruct" to "values".
+func structToValues(values url.Values, vstruct reflect.Value) {
+	stype := vstruct.Type()
+	for i := 0; i < stype.NumField(); i++ {
+		field := stype.Field(i)
+		tag, hasTag := field.Tag.Lookup("form")
+
+		if field.Anonymous && !hasTag && field.Type.Kind() == reflect.Struct {
+			structToValues(values, vstruct.Field(i))
+			continue
+		}
+
+		if field.PkgPath != "" { // not exported
+			continue
+		}
+
+		name, opts := tag, ""
+		if pos := strings.IndexByte(tag, ','); pos >= 0 {
+			name, opts = tag[:pos], tag[pos+1:]
+		}
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		vfield := vstruct.Field(i)
+		if opts == "omitempty" && isZero(vfield) {
+			continue
+		}
+
+		if vfield.Kind() == reflect.Slice && !vfield.Type().Implements(stringerType) {
+			for j := 0; j < vfield.Len(); j++ {
+				values.Add(name, valueToString(field.Name, vfield.Index(j)))
+			}
+			continue
+		}
+		values.Add(name, valueToString(field.Name, vfield))
+	}
+}
+
+// isZero returns true if "v" contains its zero value.
+func isZero(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	}
+	return v.CanInterface() &&
+		reflect.DeepEqual(v.Interface(), reflect.Zero(v.Type()).Interface())
+}
+
+// valueToString returns the string representation of "v", the
+// contents of "fieldName" field.
+func valueToString(fieldName string, v reflect.Value) string {
+	if v.Type().Implements(stringerType) && v.CanInterface() {
+		return v.Interface().(fmt.Stringer).String()
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 32)
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64)
+	}
+	panic(fmt.Sprintf("%s field: %s type is not handled", fieldName, v.Type()))
+}
+
+// RequestOptions gathers the optional parts of a request built by
+// NewRequestWithOptions.
+type RequestOptions struct {
+	Header      http.Header     // Header is added to the request header
+	Cookies     []*http.Cookie  // Cookies are added to the request
+	Query       interface{}     // Query params, see AddQueryParams
+	BasicUser   string          // BasicUser, if non-empty, enables basic auth
+	BasicPass   string          // BasicPass is the basic auth password
+	BearerToken string          // BearerToken, if non-empty, enables bearer auth
+	Context     context.Context // Context of the request, if non-nil
+}
+
+// NewRequestWithOptions creates a new HTTP request, as NewRequest
+// does, then applies all "opts" settings to it.
+//
+//   req := tdhttp.NewRequestWithOptions("GET", "/person", nil,
+//     tdhttp.RequestOptions{
+//       Header:      http.Header{"Accept": []string{"application/json"}},
+//       Cookies:     []*http.Cookie{{Name: "lang", Value: "fr"}},
+//       Query:       map[string]string{"name": "Bob"},
+//       BearerToken: "secret-token",
+//       Context:     ctx,
+//     })
+//
+// It panics if both BasicUser and BearerToken are set.
+func NewRequestWithOptions(method, target string, body io.Reader, opts RequestOptions) *http.Request {
+	if opts.BasicUser != "" && opts.BearerToken != "" {
+		panic("BasicUser and BearerToken options are mutually exclusive")
+	}
+
+	if opts.Query != nil {
+		target = AddQueryParams(target, opts.Query)
+	}
+
+	req := NewRequest(method, target, body)
+	if opts.Header != nil {
+		addHeaders(req, []interface{}{opts.Header})
+	}
+
+	for _, cookie := range opts.Cookies {
+		req.AddCookie(cookie)
+	}
+
+	if opts.BasicUser != "" {
+		req.SetBasicAuth(opts.BasicUser, opts.BasicPass)
+	} else if opts.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+opts.BearerToken)
+	}
+
+	if opts.Context != nil {
+		req = req.WithContext(opts.Context)
+	}
 
 	return req
 }
@@ -7,7 +7,11 @@
 package tdhttp_test
 
 import (
+	"context"
 	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
 	"testing"
 
 	td "github.com/maxatome/go-testdeep"
@@ -35,6 +39,18 @@ func TestNewJSONRequest(tt *testing.T) {
 		}
 	})
 
+	t.Run("NewJSONRequest with headers", func(t *td.T) {
+		req := tdhttp.NewJSONRequest("GET", "/path",
+			TestStruct{
+				Name: "Bob",
+			},
+			"Content-Type", "application/vnd.api+json",
+			http.Header{"X-Test": []string{"zip"}})
+
+		t.String(req.Header.Get("Content-Type"), "application/vnd.api+json")
+		t.String(req.Header.Get("X-Test"), "zip")
+	})
+
 	t.Run("NewJSONRequest panic", func(t *td.T) {
 		t.CmpPanic(
 			func() { tdhttp.NewJSONRequest("GET", "/path", func() {}) },
@@ -67,3 +83,216 @@ func TestNewXMLRequest(tt *testing.T) {
 			"XML encoding failed")
 	})
 }
+
+type Color int
+
+func (c Color) String() string {
+	return [...]string{"red", "green", "blue"}[c]
+}
+
+type Embedded struct {
+	Page int `form:"page"`
+}
+
+type FormStruct struct {
+	Embedded
+	Name    string   `form:"name"`
+	Tags    []string `form:"tag"`
+	Admin   bool
+	Age     uint8   `form:"age,omitempty"`
+	Ratio   float64 `form:"ratio,omitempty"`
+	Color   Color   `form:"color"`
+	Ignored string  `form:"-"`
+	private string  // nolint: megacheck,structcheck
+}
+
+func TestNewFormRequest(tt *testing.T) {
+	t := td.NewT(tt)
+
+	checkBody := func(t *td.T, req *http.Request, expected string) {
+		t.Helper()
+		body, err := ioutil.ReadAll(req.Body)
+		if t.CmpNoError(err, "read request body") {
+			t.String(string(body), expected)
+		}
+	}
+
+	t.Run("url.Values", func(t *td.T) {
+		req := tdhttp.NewFormRequest("POST", "/path",
+			url.Values{"b": []string{"2", "3"}, "a": []string{"1"}})
+
+		t.String(req.Header.Get("Content-Type"),
+			"application/x-www-form-urlencoded")
+		checkBody(t, req, "a=1&b=2&b=3")
+	})
+
+	t.Run("maps", func(t *td.T) {
+		checkBody(t,
+			tdhttp.NewFormRequest("POST", "/path",
+				map[string]string{"b": "x y", "a": "&"}),
+			"a=%26&b=x+y")
+
+		checkBody(t,
+			tdhttp.NewFormRequest("POST", "/path",
+				map[string][]string{"a": {"1", "2"}}),
+			"a=1&a=2")
+	})
+
+	t.Run("struct", func(t *td.T) {
+		req := tdhttp.NewFormRequest("POST", "/path",
+			&FormStruct{
+				Embedded: Embedded{Page: 3},
+				Name:     "Bob",
+				Tags:     []string{"a", "b"},
+				Admin:    true,
+				Ratio:    0.5,
+				Color:    2,
+				Ignored:  "ignored",
+				private:  "private",
+			},
+			"Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+
+		t.String(req.Header.Get("Content-Type"),
+			"application/x-www-form-urlencoded; charset=utf-8")
+		checkBody(t, req,
+			"Admin=true&color=blue&name=Bob&page=3&ratio=0.5&tag=a&tag=b")
+	})
+
+	t.Run("panics", func(t *td.T) {
+		t.CmpPanic(
+			func() { tdhttp.NewFormRequest("POST", "/path", 42) },
+			"url.Values, map[string]string, map[string][]string or struct expected, not int")
+
+		t.CmpPanic(
+			func() {
+				tdhttp.NewFormRequest("POST", "/path", struct{ Bad *int }{})
+			},
+			"Bad field: *int type is not handled")
+	})
+}
+
+func TestNewMultipartRequest(tt *testing.T) {
+	t := td.NewT(tt)
+
+	req := tdhttp.NewMultipartRequest("POST", "/upload",
+		[]tdhttp.MultipartPart{
+			{Name: "comment", Content: strings.NewReader("my file")},
+			{
+				Name:     "file",
+				FileName: "file.bin",
+				Content:  strings.NewReader("\x00\x01"),
+			},
+			{
+				Name:        "doc",
+				FileName:    "doc.txt",
+				ContentType: "text/plain",
+				Content:     strings.NewReader("Hello!"),
+			},
+			{
+				Name:     `ünicode "name"`,
+				FileName: "été\u00a0\"2\".txt",
+				Content:  strings.NewReader("summer"),
+			},
+		},
+		"X-Test", "multi")
+
+	t.String(req.Header.Get("X-Test"), "multi")
+	t.Re(req.Header.Get("Content-Type"), `^multipart/form-data; boundary=\w+\z`, nil)
+
+	if !t.CmpNoError(req.ParseMultipartForm(1 << 20)) {
+		return
+	}
+
+	t.Cmp(req.MultipartForm.Value, map[string][]string{
+		"comment": {"my file"},
+	})
+
+	t.Cmp(req.MultipartForm.File, td.MapEach(td.Len(1)))
+	t.Cmp(req.MultipartForm.File, td.Keys(td.Bag("file", "doc", `ünicode "name"`)))
+
+	fh := req.MultipartForm.File["file"][0]
+	t.String(fh.Filename, "file.bin")
+	t.String(fh.Header.Get("Content-Type"), "application/octet-stream")
+
+	fh = req.MultipartForm.File["doc"][0]
+	t.String(fh.Filename, "doc.txt")
+	t.String(fh.Header.Get("Content-Type"), "text/plain")
+	if f, err := fh.Open(); t.CmpNoError(err) {
+		content, err := ioutil.ReadAll(f)
+		t.CmpNoError(err)
+		t.String(string(content), "Hello!")
+	}
+
+	// Non-ASCII & quotes are not escaped as Go strings
+	fh = req.MultipartForm.File[`ünicode "name"`][0]
+	t.String(fh.Filename, "été\u00a0\"2\".txt")
+}
+
+func TestAddQueryParams(tt *testing.T) {
+	t := td.NewT(tt)
+
+	t.String(tdhttp.AddQueryParams("/search", map[string]string{"q": "go"}),
+		"/search?q=go")
+
+	t.String(tdhttp.AddQueryParams("/person?sort=id", struct {
+		Name  string   `form:"name"`
+		Tags  []string `form:"tag"`
+		Limit int      `form:"limit,omitempty"`
+	}{
+		Name: "Bob",
+		Tags: []string{"a", "b"},
+	}), "/person?name=Bob&sort=id&tag=a&tag=b")
+
+	t.String(tdhttp.AddQueryParams("http://example.com/a?x=1",
+		url.Values{"x": []string{"2"}}),
+		"http://example.com/a?x=1&x=2")
+
+	t.CmpPanic(func() { tdhttp.AddQueryParams(":bad", nil) },
+		td.HasPrefix("target parsing failed: "))
+}
+
+type ctxKey struct{}
+
+func TestNewRequestWithOptions(tt *testing.T) {
+	t := td.NewT(tt)
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+
+	req := tdhttp.NewRequestWithOptions("GET", "/person?sort=id", nil,
+		tdhttp.RequestOptions{
+			Header:      http.Header{"Accept": []string{"application/json"}},
+			Cookies:     []*http.Cookie{{Name: "lang", Value: "fr"}},
+			Query:       map[string]string{"name": "Bob"},
+			BearerToken: "secret-token",
+			Context:     ctx,
+		})
+
+	t.String(req.URL.String(), "/person?name=Bob&sort=id")
+	t.String(req.Header.Get("Accept"), "application/json")
+	t.String(req.Header.Get("Authorization"), "Bearer secret-token")
+	if cookie, err := req.Cookie("lang"); t.CmpNoError(err) {
+		t.String(cookie.Value, "fr")
+	}
+	t.Cmp(req.Context().Value(ctxKey{}), "value")
+
+	req = tdhttp.NewRequestWithOptions("POST", "/person",
+		strings.NewReader("body"),
+		tdhttp.RequestOptions{
+			BasicUser: "bob",
+			BasicPass: "secret",
+		})
+	user, pass, ok := req.BasicAuth()
+	t.True(ok)
+	t.String(user, "bob")
+	t.String(pass, "secret")
+	t.Nil(req.Header["Cookie"])
+
+	t.CmpPanic(
+		func() {
+			tdhttp.NewRequestWithOptions("GET", "/", nil, tdhttp.RequestOptions{
+				BasicUser:   "bob",
+				BearerToken: "token",
+			})
+		},
+		"BasicUser and BearerToken options are mutually exclusive")
+}
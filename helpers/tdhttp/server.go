@@ -0,0 +1,147 @@
+// Copyright (c) 2019, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package tdhttp
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+)
+
+// ServerKind is the kind of server started by NewServer.
+type ServerKind int
+
+const (
+	// PlainServer is a plain HTTP/1.1 server.
+	PlainServer ServerKind = iota
+	// TLSServer is an HTTP/1.1 over TLS server.
+	TLSServer
+	// HTTP2Server is an HTTP/2 over TLS server.
+	HTTP2Server
+)
+
+// Server is an httptest.Server wrapping a handler, that is also an
+// http.Handler itself: each request it serves is sent to the wrapped
+// handler over the loopback, so the wrapped handler faces a real
+// connection (TLS state, HTTP/2, Connection headers, timeouts…).
+//
+// As a Server is an http.Handler, it can be used with all tdhttp
+// functions. Only the handler under test changes:
+//
+//   srv := tdhttp.NewServer(mux, tdhttp.HTTP2Server)
+//   defer srv.Close()
+//
+//   tdhttp.CmpJSONResponse(t,
+//     tdhttp.NewRequest("GET", "/person/42", nil),
+//     srv.ServeHTTP,
+//     tdhttp.Response{
+//       Status: http.StatusOK,
+//       Body:   Person{ID: 42, Name: "Bob"},
+//     })
+//
+//   ta := tdhttp.NewTestAPI(t, srv)
+//   ta.Get("/login").
+//     CmpStatus(http.StatusFound).
+//     CmpHeader(td.SuperMapOf(http.Header{
+//       "Location": []string{"/home"},
+//     }, nil))
+//
+// Note that redirections are not followed and that the response
+// header contains the headers added by the HTTP server, like Date or
+// Content-Length.
+//
+// Note also that the response body is flushed each time data is
+// received from the connection, not each time the wrapped handler
+// flushes it: chunk boundaries (see FlushRecorder and
+// TestAPI.CmpChunks) then depend on network buffering and are not
+// guaranteed. Only check them when calling the handler directly, or
+// loosely, as in td.Len(td.Gte(2)).
+type Server struct {
+	*httptest.Server
+	client *http.Client
+}
+
+// NewServer starts and returns a new Server of kind "kind" around
+// "handler". The caller should call Close when finished, to shut it
+// down.
+func NewServer(handler http.Handler, kind ServerKind) *Server {
+	if kind < PlainServer || kind > HTTP2Server {
+		panic(fmt.Sprintf("unknown server kind %d", kind))
+	}
+
+	srv := httptest.NewUnstartedServer(handler)
+	switch kind {
+	case PlainServer:
+		srv.Start()
+	case TLSServer:
+		srv.StartTLS()
+	case HTTP2Server:
+		srv.EnableHTTP2 = true
+		srv.StartTLS()
+	}
+
+	client := srv.Client()
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	return &Server{
+		Server: srv,
+		client: client,
+	}
+}
+
+// ServeHTTP implements http.Handler interface, sending "req" to the
+// wrapped handler over the loopback, then copying its response to
+// "w". Only the path and the query of "req" URL are used, its Host
+// header is kept.
+//
+// If the request cannot be sent, a 502 Bad Gateway response is
+// written to "w".
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	out, err := http.NewRequest(req.Method, s.URL+req.URL.RequestURI(), req.Body)
+	if err != nil {
+		http.Error(w, "tdhttp.Server: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+	out = out.WithContext(req.Context())
+	out.Host = req.Host
+	out.ContentLength = req.ContentLength
+	for key, values := range req.Header {
+		out.Header[key] = append([]string(nil), values...)
+	}
+
+	resp, err := s.client.Do(out)
+	if err != nil {
+		http.Error(w, "tdhttp.Server: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close() // nolint: errcheck
+
+	for key, values := range resp.Header {
+		w.Header()[key] = append([]string(nil), values...)
+	}
+	w.WriteHeader(resp.StatusCode)
+
+	// Flush after each read, so a streamed response is kept
+	// streamed. The handler flushes boundaries are lost, chunks are
+	// the ones received from the connection
+	flusher, _ := w.(http.Flusher)
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := resp.Body.Read(buf)
+		if n > 0 {
+			w.Write(buf[:n]) // nolint: errcheck
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2019, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package tdhttp_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	td "github.com/maxatome/go-testdeep"
+	"github.com/maxatome/go-testdeep/helpers/tdhttp"
+)
+
+func connHandler(w http.ResponseWriter, req *http.Request) {
+	switch req.URL.Path {
+	case "/redirect":
+		http.Redirect(w, req, "/target", http.StatusFound)
+		return
+	case "/echo":
+		body, _ := ioutil.ReadAll(req.Body)
+		w.Header().Set("X-Custom", req.Header.Get("X-Custom"))
+		fmt.Fprintf(w, "%s %s?%s: %s", req.Method, req.URL.Path, req.URL.RawQuery, body)
+		return
+	}
+	fmt.Fprintf(w, "%s TLS=%t Host=%s", req.Proto, req.TLS != nil, req.Host)
+}
+
+func TestServer(t *testing.T) {
+	for _, tc := range []struct {
+		kind     tdhttp.ServerKind
+		expected string
+		scheme   string
+	}{
+		{kind: tdhttp.PlainServer, expected: "HTTP/1.1 TLS=false", scheme: "http://"},
+		{kind: tdhttp.TLSServer, expected: "HTTP/1.1 TLS=true", scheme: "https://"},
+		{kind: tdhttp.HTTP2Server, expected: "HTTP/2.0 TLS=true", scheme: "https://"},
+	} {
+		srv := tdhttp.NewServer(http.HandlerFunc(connHandler), tc.kind)
+
+		td.Cmp(t, srv.URL, td.HasPrefix(tc.scheme))
+
+		ta := tdhttp.NewTestAPI(t, srv)
+
+		ta.Get("/").
+			CmpStatus(http.StatusOK).
+			CmpHeader(td.ContainsKey("Date")).
+			CmpBody(tc.expected + " Host=example.com")
+
+		ta.Post("/echo?q=1", strings.NewReader("body"), "X-Custom", "zip").
+			CmpStatus(http.StatusOK).
+			CmpHeader(td.SuperMapOf(http.Header{"X-Custom": []string{"zip"}}, nil)).
+			CmpBody("POST /echo?q=1: body")
+
+		// Redirections are not followed
+		ta.Get("/redirect").
+			CmpStatus(http.StatusFound).
+			CmpHeader(td.SuperMapOf(http.Header{
+				"Location": []string{"/target"},
+			}, nil))
+
+		// Usable with Cmp*Response functions
+		td.CmpTrue(t, tdhttp.CmpResponse(t,
+			tdhttp.NewRequest("GET", "/", nil),
+			srv.ServeHTTP,
+			tdhttp.Response{
+				Status: http.StatusOK,
+				Body:   td.All(td.Isa(""), td.HasPrefix(tc.expected)),
+			}))
+
+		srv.Close()
+	}
+
+	t.Run("Streaming", func(t *testing.T) {
+		srv := tdhttp.NewServer(http.HandlerFunc(sseHandler), tdhttp.PlainServer)
+		defer srv.Close()
+
+		tdhttp.NewTestAPI(t, srv).
+			Get("/events").
+			CmpStatus(http.StatusOK).
+			CmpChunks(td.Len(td.Gte(1))).
+			CmpSSEBody(td.Len(2))
+	})
+
+	t.Run("Bad gateway", func(t *testing.T) {
+		srv := tdhttp.NewServer(http.HandlerFunc(connHandler), tdhttp.PlainServer)
+		srv.Close()
+
+		tdhttp.NewTestAPI(t, srv).
+			Get("/").
+			CmpStatus(http.StatusBadGateway).
+			CmpBody(td.All(td.Isa(""), td.HasPrefix("tdhttp.Server: ")))
+	})
+
+	t.Run("Bad kind", func(t *testing.T) {
+		td.CmpPanic(t,
+			func() { tdhttp.NewServer(http.HandlerFunc(connHandler), 42) },
+			"unknown server kind 42")
+	})
+}
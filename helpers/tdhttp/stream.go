@@ -0,0 +1,248 @@
+// Copyright (c) 2019, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package tdhttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	td "github.com/maxatome/go-testdeep"
+)
+
+// SSEEvent is a Server-Sent Event, as parsed by CmpSSEResponse.
+type SSEEvent struct {
+	ID    string // ID is the "id" field of the event, if any
+	Event string // Event is the "event" field, the type of the event
+	Data  string // Data contains the "data" lines joined with "\n"
+	Retry int    // Retry is the "retry" field, 0 if not set
+}
+
+// ParseSSE parses "body" as a text/event-stream and returns its
+// events. Parsing follows the HTML specification: comments are
+// ignored, multiple "data" lines are joined with "\n", and an event
+// is only dispatched when a blank line is encountered and its data
+// is not empty. Unlike the specification, the ID of an event is only
+// set if the event contains an "id" field.
+func ParseSSE(body []byte) []SSEEvent {
+	var (
+		events  []SSEEvent
+		cur     SSEEvent
+		data    bytes.Buffer
+		hasData bool
+	)
+
+	text := strings.Replace(string(body), "\r\n", "\n", -1)
+	text = strings.Replace(text, "\r", "\n", -1)
+
+	for _, line := range strings.Split(text, "\n") {
+		if line == "" {
+			if hasData {
+				cur.Data = strings.TrimSuffix(data.String(), "\n")
+				events = append(events, cur)
+			}
+			cur = SSEEvent{}
+			data.Reset()
+			hasData = false
+			continue
+		}
+
+		if line[0] == ':' { // comment
+			continue
+		}
+
+		field, value := line, ""
+		if pos := strings.IndexByte(line, ':'); pos >= 0 {
+			field, value = line[:pos], strings.TrimPrefix(line[pos+1:], " ")
+		}
+
+		switch field {
+		case "event":
+			cur.Event = value
+		case "data":
+			data.WriteString(value)
+			data.WriteByte('\n')
+			hasData = true
+		case "id":
+			if strings.IndexByte(value, 0) < 0 {
+				cur.ID = value
+			}
+		case "retry":
+			if retry, err := strconv.Atoi(value); err == nil && retry >= 0 {
+				cur.Retry = retry
+			}
+		}
+	}
+	return events
+}
+
+// unmarshalSSE is the unmarshal function used by CmpSSEResponse.
+func unmarshalSSE(body []byte, target interface{}) error {
+	switch t := target.(type) {
+	case *[]SSEEvent:
+		*t = ParseSSE(body)
+	case *interface{}:
+		*t = ParseSSE(body)
+	default:
+		return fmt.Errorf(
+			"CmpSSEResponse does not handle %T body, only []tdhttp.SSEEvent",
+			target)
+	}
+	return nil
+}
+
+// unmarshalNDJSON is the unmarshal function used by
+// CmpNDJSONResponse. "target" must be a pointer to a slice or to an
+// interface{}, in which case a []interface{} is used. Each non-empty
+// line of "body" is json.Unmarshal'ed into a new item of this slice.
+func unmarshalNDJSON(body []byte, target interface{}) error {
+	vtarget := reflect.ValueOf(target)
+	if vtarget.Kind() != reflect.Ptr || vtarget.IsNil() {
+		return fmt.Errorf("CmpNDJSONResponse needs a non-nil pointer, not %T", target)
+	}
+	vtarget = vtarget.Elem()
+
+	var vslice reflect.Value
+	switch vtarget.Kind() {
+	case reflect.Slice:
+		vslice = reflect.MakeSlice(vtarget.Type(), 0, 0)
+	case reflect.Interface:
+		vslice = reflect.ValueOf([]interface{}{})
+	default:
+		return fmt.Errorf(
+			"CmpNDJSONResponse does not handle %T body, only slices", target)
+	}
+
+	for num, line := range bytes.Split(body, []byte("\n")) {
+		line = bytes.TrimSuffix(line, []byte("\r"))
+		if len(line) == 0 {
+			continue
+		}
+
+		item := reflect.New(vslice.Type().Elem())
+		if err := json.Unmarshal(line, item.Interface()); err != nil {
+			return fmt.Errorf("line %d: %s", num+1, err)
+		}
+		vslice = reflect.Append(vslice, item.Elem())
+	}
+
+	vtarget.Set(vslice)
+	return nil
+}
+
+// CmpSSEResponse is used to match a Server-Sent Events response
+// body. The req *http.Request is launched against handler. If
+// expectedResp.Body is non-nil, the response body is parsed using
+// ParseSSE into a []SSEEvent. The response is then tested against
+// expectedResp.
+//
+//   tdhttp.CmpSSEResponse(t,
+//     tdhttp.NewRequest("GET", "/events", nil),
+//     mux.ServeHTTP,
+//     tdhttp.Response{
+//       Status: http.StatusOK,
+//       Body: []tdhttp.SSEEvent{
+//         {ID: "1", Event: "add", Data: "Bob"},
+//         {ID: "2", Event: "del", Data: "Alice"},
+//       },
+//       Chunks: td.Len(td.Gte(2)),
+//     })
+//
+// As expectedResp.Body can be a TestDeep operator, Slice, Bag or
+// any other operator can be used to check events:
+//
+//   Body: td.Bag(
+//     td.Struct(tdhttp.SSEEvent{Event: "add"}, nil),
+//     td.Struct(tdhttp.SSEEvent{Event: "del"}, nil),
+//   ),
+//
+// All the tests are enclosed in a testdeep.Run().
+//
+// It returns true if the tests succeed, false otherwise.
+func CmpSSEResponse(t td.TestingFT,
+	req *http.Request,
+	handler func(w http.ResponseWriter, r *http.Request),
+	expectedResp Response,
+	args ...interface{},
+) bool {
+	t.Helper()
+	return cmpResponse(t,
+		req,
+		handler,
+		sseDecoder,
+		expectedResp,
+		args...)
+}
+
+// CmpNDJSONResponse is used to match a newline-delimited JSON
+// response body. The req *http.Request is launched against
+// handler. If expectedResp.Body is non-nil, each non-empty line of
+// the response body is json.Unmarshal'ed into a new item of a
+// slice. The type of this slice is the one of expectedResp.Body (or
+// the one behind it if it is a TestDeep operator), []interface{} if
+// it cannot be guessed. The response is then tested against
+// expectedResp.
+//
+//   tdhttp.CmpNDJSONResponse(t,
+//     tdhttp.NewRequest("GET", "/persons", nil),
+//     mux.ServeHTTP,
+//     tdhttp.Response{
+//       Status: http.StatusOK,
+//       Body:   td.Slice([]Person{}, td.ArrayEntries{
+//         0: Person{ID: 1, Name: "Bob"},
+//         1: td.Struct(Person{Name: "Alice"}, nil),
+//       }),
+//     })
+//
+// All the tests are enclosed in a testdeep.Run().
+//
+// It returns true if the tests succeed, false otherwise.
+func CmpNDJSONResponse(t td.TestingFT,
+	req *http.Request,
+	handler func(w http.ResponseWriter, r *http.Request),
+	expectedResp Response,
+	args ...interface{},
+) bool {
+	t.Helper()
+	return cmpResponse(t,
+		req,
+		handler,
+		ndjsonDecoder,
+		expectedResp,
+		args...)
+}
+
+// CmpSSEResponseFunc returns a function ready to be used with
+// testing.Run, calling CmpSSEResponse behind the scene. As it is
+// intended to be used in conjunction with testing.Run() which names
+// the sub-test, the test name part (args...) is voluntary omitted.
+func CmpSSEResponseFunc(req *http.Request,
+	handler func(w http.ResponseWriter, r *http.Request),
+	expectedResp Response) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Helper()
+		CmpSSEResponse(t, req, handler, expectedResp)
+	}
+}
+
+// CmpNDJSONResponseFunc returns a function ready to be used with
+// testing.Run, calling CmpNDJSONResponse behind the scene. As it is
+// intended to be used in conjunction with testing.Run() which names
+// the sub-test, the test name part (args...) is voluntary omitted.
+func CmpNDJSONResponseFunc(req *http.Request,
+	handler func(w http.ResponseWriter, r *http.Request),
+	expectedResp Response) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Helper()
+		CmpNDJSONResponse(t, req, handler, expectedResp)
+	}
+}
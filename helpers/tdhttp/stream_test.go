@@ -0,0 +1,230 @@
+// Copyright (c) 2019, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package tdhttp_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	td "github.com/maxatome/go-testdeep"
+	"github.com/maxatome/go-testdeep/helpers/tdhttp"
+)
+
+func TestParseSSE(tt *testing.T) {
+	t := td.NewT(tt)
+
+	t.Cmp(tdhttp.ParseSSE(nil), td.Empty())
+
+	t.Cmp(tdhttp.ParseSSE([]byte(`: comment
+id: 1
+event: add
+data: first line
+data:second line
+
+data: no id
+retry: 1500
+
+id
+event: empty-id
+data
+
+retry: bad
+data: unterminated`)),
+		[]tdhttp.SSEEvent{
+			{ID: "1", Event: "add", Data: "first line\nsecond line"},
+			{Data: "no id", Retry: 1500},
+			{Event: "empty-id"},
+		})
+
+	// CRLF & CR line endings, events without data are not dispatched
+	t.Cmp(tdhttp.ParseSSE([]byte("event: nodata\r\n\r\ndata: a\r\rdata: b\r\n\r\n")),
+		[]tdhttp.SSEEvent{{Data: "a"}, {Data: "b"}})
+}
+
+func sseHandler(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	for i, name := range []string{"Bob", "Alice"} {
+		fmt.Fprintf(w, "id: %d\nevent: add\ndata: %s\n\n", i+1, name)
+		w.(http.Flusher).Flush()
+	}
+}
+
+func ndjsonHandler(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	enc := json.NewEncoder(w)
+	enc.Encode(Person{ID: 1, Name: "Bob"})   // nolint: errcheck
+	enc.Encode(Person{ID: 2, Name: "Alice"}) // nolint: errcheck
+	w.(http.Flusher).Flush()
+	fmt.Fprint(w, "\n")                      // empty lines are ignored
+	enc.Encode(Person{ID: 3, Name: "Carol"}) // nolint: errcheck
+}
+
+func TestCmpSSEResponse(tt *testing.T) {
+	t := td.NewT(tt)
+
+	t.True(tdhttp.CmpSSEResponse(tt,
+		tdhttp.NewRequest("GET", "/events", nil),
+		sseHandler,
+		tdhttp.Response{
+			Status: http.StatusOK,
+			Body: []tdhttp.SSEEvent{
+				{ID: "1", Event: "add", Data: "Bob"},
+				{ID: "2", Event: "add", Data: "Alice"},
+			},
+			Chunks: td.Len(2),
+		}))
+
+	t.True(tdhttp.CmpSSEResponse(tt,
+		tdhttp.NewRequest("GET", "/events", nil),
+		sseHandler,
+		tdhttp.Response{
+			Body: td.Bag(
+				td.Struct(tdhttp.SSEEvent{Data: "Alice"}, nil),
+				td.Struct(tdhttp.SSEEvent{Data: "Bob"}, nil),
+			),
+		}))
+
+	tt.Run("Func", tdhttp.CmpSSEResponseFunc(
+		tdhttp.NewRequest("GET", "/events", nil),
+		sseHandler,
+		tdhttp.Response{
+			Body: td.Len(2),
+		}))
+
+	mockT := &recordT{}
+	t.False(tdhttp.CmpSSEResponse(mockT,
+		tdhttp.NewRequest("GET", "/events", nil),
+		sseHandler,
+		tdhttp.Response{
+			Body:   td.Len(2),
+			Chunks: td.Len(1),
+		}))
+	t.Cmp(mockT.Errors(), td.Contains("Failed test 'chunks should match'"))
+
+	mockT = &recordT{}
+	t.False(tdhttp.CmpSSEResponse(mockT,
+		tdhttp.NewRequest("GET", "/events", nil),
+		sseHandler,
+		tdhttp.Response{
+			Body: []string{},
+		}))
+	t.Cmp(mockT.Errors(), td.Contains("Failed test 'body unmarshaling'"))
+}
+
+func TestCmpNDJSONResponse(tt *testing.T) {
+	t := td.NewT(tt)
+
+	t.True(tdhttp.CmpNDJSONResponse(tt,
+		tdhttp.NewRequest("GET", "/persons", nil),
+		ndjsonHandler,
+		tdhttp.Response{
+			Status: http.StatusOK,
+			Body: []Person{
+				{ID: 1, Name: "Bob"},
+				{ID: 2, Name: "Alice"},
+				{ID: 3, Name: "Carol"},
+			},
+			Chunks: []string{
+				`{"id":1,"name":"Bob"}` + "\n" + `{"id":2,"name":"Alice"}` + "\n",
+				"\n" + `{"id":3,"name":"Carol"}` + "\n",
+			},
+		}))
+
+	t.True(tdhttp.CmpNDJSONResponse(tt,
+		tdhttp.NewRequest("GET", "/persons", nil),
+		ndjsonHandler,
+		tdhttp.Response{
+			Body: td.Slice([]Person{}, td.ArrayEntries{
+				0: td.Struct(Person{Name: "Bob"}, nil),
+				1: td.Ignore(),
+				2: td.Struct(Person{Name: "Carol"}, nil),
+			}),
+		}))
+
+	// Type behind unknown: []interface{}
+	t.True(tdhttp.CmpNDJSONResponse(tt,
+		tdhttp.NewRequest("GET", "/persons", nil),
+		ndjsonHandler,
+		tdhttp.Response{
+			Body: td.All(
+				td.Len(3),
+				td.Contains(map[string]interface{}{"id": float64(2), "name": "Alice"}),
+			),
+		}))
+
+	tt.Run("Func", tdhttp.CmpNDJSONResponseFunc(
+		tdhttp.NewRequest("GET", "/persons", nil),
+		ndjsonHandler,
+		tdhttp.Response{
+			Body: td.Len(3),
+		}))
+
+	mockT := &recordT{}
+	t.False(tdhttp.CmpNDJSONResponse(mockT,
+		tdhttp.NewRequest("GET", "/persons", nil),
+		func(w http.ResponseWriter, req *http.Request) {
+			fmt.Fprint(w, "{}\n{\n")
+		},
+		tdhttp.Response{
+			Body: td.Len(2),
+		}))
+	t.Cmp(mockT.Errors(), td.All(
+		td.Contains("Failed test 'body unmarshaling'"),
+		td.Contains("line 2: "),
+	))
+
+	mockT = &recordT{}
+	t.False(tdhttp.CmpNDJSONResponse(mockT,
+		tdhttp.NewRequest("GET", "/persons", nil),
+		ndjsonHandler,
+		tdhttp.Response{
+			Body: Person{},
+		}))
+	t.Cmp(mockT.Errors(), td.Contains("only slices"))
+}
+
+func TestTestAPIStream(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/events", sseHandler)
+	mux.HandleFunc("/persons", ndjsonHandler)
+
+	ta := tdhttp.NewTestAPI(t, mux)
+
+	ta.Get("/events").
+		CmpStatus(http.StatusOK).
+		CmpChunks(td.Len(2)).
+		CmpSSEBody(td.Slice([]tdhttp.SSEEvent{}, td.ArrayEntries{
+			0: td.Struct(tdhttp.SSEEvent{ID: "1", Data: "Bob"}, nil),
+			1: td.Struct(tdhttp.SSEEvent{ID: "2", Data: "Alice"}, nil),
+		}))
+	td.CmpFalse(t, ta.Failed())
+
+	ta.Get("/persons").
+		CmpStatus(http.StatusOK).
+		CmpChunks(td.Len(td.Gte(2))).
+		CmpNDJSONBody(td.All(
+			td.Isa([]Person{}),
+			td.Bag(
+				Person{ID: 3, Name: "Carol"},
+				Person{ID: 2, Name: "Alice"},
+				Person{ID: 1, Name: "Bob"},
+			),
+		))
+	td.CmpFalse(t, ta.Failed())
+
+	mockT := &recordT{}
+	ta = tdhttp.NewTestAPI(mockT, mux)
+	ta.CmpChunks(td.Len(2))
+	td.Cmp(t, mockT.Errors(),
+		td.Contains("A request must be sent before testing the response"))
+
+	ta.Get("/events").CmpChunks(td.Len(1))
+	td.CmpTrue(t, ta.Failed())
+	td.Cmp(t, mockT.Errors(), td.Contains("Failed test 'chunks should match'"))
+}
@@ -0,0 +1,817 @@
+// Copyright (c) 2019, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package tdhttp
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+
+	td "github.com/maxatome/go-testdeep"
+	"github.com/maxatome/go-testdeep/helpers/tdutil"
+	"github.com/maxatome/go-testdeep/internal/ctxerr"
+)
+
+// TestAPI allows to test one HTTP API, request after request,
+// checking each response using chained methods:
+//
+//   func TestMyAPI(t *testing.T) {
+//     ta := tdhttp.NewTestAPI(t, myAPI.Handler())
+//
+//     ta.Get("/person/42", "Accept", "application/json").
+//       CmpStatus(http.StatusOK).
+//       CmpHeader(td.ContainsKey("X-Custom-Header")).
+//       CmpJSONBody(Person{ID: 42, Name: "Bob"})
+//
+//     ta.Name("Creation of Alice").
+//       PostJSON("/person", Person{Name: "Alice"}).
+//       CmpStatus(http.StatusCreated).
+//       CmpJSONBody(td.Struct(Person{Name: "Alice"}, td.StructFields{
+//         "ID": td.NotZero(),
+//       }))
+//
+//     if ta.Failed() {
+//       t.Log("Something went wrong")
+//     }
+//   }
+//
+// On the first failure following a request, the request and its
+// response are dumped in the test log.
+type TestAPI struct {
+	t       *td.T
+	handler http.Handler
+	jar     http.CookieJar
+	openAPI *OpenAPI
+
+	name     string
+	nextName string
+
+	sent     bool
+	req      *http.Request
+	reqBody  []byte
+	response *FlushRecorder
+
+	maxRedirects int
+	redirects    []Redirect
+
+	failed         bool
+	statusMismatch bool
+	dumped         bool
+}
+
+// NewTestAPI creates a TestAPI that can be used to test the API
+// served by "handler".
+//
+// Note that an http.HandlerFunc can be used as handler:
+//
+//   ta := tdhttp.NewTestAPI(t, http.HandlerFunc(myHandler))
+func NewTestAPI(tt td.TestingFT, handler http.Handler) *TestAPI {
+	return &TestAPI{
+		t:       td.NewT(tt),
+		handler: handler,
+	}
+}
+
+// T returns the internal instance of *td.T.
+func (t *TestAPI) T() *td.T {
+	return t.t
+}
+
+// Name allows to name the series of tests that follow the next
+// request. This name is used as a prefix for all tests names of the
+// next request response.
+//
+// If len(args) > 1 and the first item of args is a string and
+// contains a '%' rune then fmt.Fprintf is used to compose the name,
+// else args are passed to fmt.Fprint.
+func (t *TestAPI) Name(args ...interface{}) *TestAPI {
+	t.nextName = tdutil.BuildTestName(args...)
+	if t.nextName != "" {
+		t.nextName += ": "
+	}
+	return t
+}
+
+// UseCookieJar makes t use a new empty cookie jar: cookies set by
+// each response are then stored and sent back with the following
+// requests, as a browser would do.
+//
+//   ta := tdhttp.NewTestAPI(t, mux).UseCookieJar()
+//
+//   ta.PostJSON("/login", Credentials{User: "bob", Password: "secret"}).
+//     CmpStatus(http.StatusOK)
+//
+//   // The session cookie set by /login is sent with this request
+//   ta.Get("/account").
+//     CmpStatus(http.StatusOK)
+func (t *TestAPI) UseCookieJar() *TestAPI {
+	return t.SetCookieJar(newCookieJar())
+}
+
+// SetCookieJar makes t use "jar" to store cookies set by responses
+// and to send them back with the following requests. A nil "jar"
+// disables cookies handling, the default.
+func (t *TestAPI) SetCookieJar(jar http.CookieJar) *TestAPI {
+	t.jar = jar
+	return t
+}
+
+// CookieJar returns the cookie jar used by t, or nil if cookies are
+// not handled.
+func (t *TestAPI) CookieJar() http.CookieJar {
+	return t.jar
+}
+
+// ClearCookies empties the cookie jar used by t, if any. Typically
+// useful to test a logout or a session expiration.
+//
+// As http.CookieJar interface does not allow to remove cookies, the
+// jar is in fact replaced by a new empty one, as UseCookieJar
+// does. It means that a jar set using SetCookieJar is replaced too
+// and so is no longer filled by the following responses: CookieJar
+// has to be called again to get the new one.
+func (t *TestAPI) ClearCookies() *TestAPI {
+	if t.jar != nil {
+		t.UseCookieJar()
+	}
+	return t
+}
+
+// AddCookies pre-seeds the cookie jar with "cookies", as if they
+// were set by a response to a request on "target". "target" is
+// resolved the same way requests ones are, so it can be a path like
+// "/" or an absolute URL. If t does not use a cookie jar yet,
+// UseCookieJar is called first.
+//
+//   ta := tdhttp.NewTestAPI(t, mux).
+//     AddCookies("/", &http.Cookie{Name: "session", Value: "1234"})
+func (t *TestAPI) AddCookies(target string, cookies ...*http.Cookie) *TestAPI {
+	if t.jar == nil {
+		t.UseCookieJar()
+	}
+	t.jar.SetCookies(jarURLOf(NewRequest("GET", target, nil)), cookies)
+	return t
+}
+
+// FollowRedirects makes t follow the 3xx redirections of the
+// following requests, up to "maxRedirects" ones. Each redirection
+// is sent to the tested handler, whatever the host of its
+// Location. A 0 "maxRedirects" disables redirections following, the
+// default.
+//
+// As a browser does, cookies are preserved across redirections,
+// even if no cookie jar is used (see UseCookieJar). 301, 302 and 303
+// redirections of other methods than GET and HEAD are followed using
+// a GET without body, 307 and 308 ones keep the method and the body.
+//
+// The Cmp* methods then check the final response, and CmpRedirects
+// the redirections chain:
+//
+//   ta := tdhttp.NewTestAPI(t, mux).FollowRedirects(5)
+//
+//   ta.PostForm("/login", url.Values{"user": []string{"bob"}}).
+//     CmpRedirects([]tdhttp.Redirect{
+//       {Status: http.StatusSeeOther, Location: "/home"},
+//     }).
+//     CmpStatus(http.StatusOK).
+//     CmpBody(td.All(td.Isa(""), td.Contains("Welcome Bob!")))
+//
+// If the tested handler still redirects after "maxRedirects"
+// redirections, the test fails and the last redirect response is
+// kept as the final one.
+//
+// Cmp*Response functions can follow redirections too, see
+// Response.Redirects.
+func (t *TestAPI) FollowRedirects(maxRedirects int) *TestAPI {
+	t.maxRedirects = maxRedirects
+	return t
+}
+
+// UseOpenAPI loads the OpenAPI 3 JSON document contained in "file"
+// (see LoadOpenAPI), then makes t check each following request and
+// its response against it. If the document cannot be loaded, the
+// test fails and stops.
+//
+//   ta := tdhttp.NewTestAPI(t, mux).UseOpenAPI("api/openapi.json")
+//
+//   // Fails if /person/{id} GET operation is not documented, or if
+//   // the response status, headers or body do not respect it
+//   ta.Get("/person/42").
+//     CmpStatus(http.StatusOK)
+//
+// Parameters (path, query, header and cookie ones), the request
+// body, the response status, the response headers and the response
+// body are checked. Only JSON bodies are validated against their
+// schema, the Content-Type of other ones has just to be documented.
+// When redirections are followed (see FollowRedirects), only the
+// last request and its response are checked.
+//
+// Cmp*Response functions can check requests and responses against
+// an OpenAPI document too, see Response.OpenAPI.
+//
+// Each contract violation is reported as a test failure, using the
+// JSON pointer of the faulty part of the body:
+//
+//   Failed test 'response should respect OpenAPI contract'
+//   	Response.Body/age: violates "minimum" keyword
+//   		expected ≥ 0, got -1
+func (t *TestAPI) UseOpenAPI(file string) *TestAPI {
+	t.t.Helper()
+
+	api, err := LoadOpenAPI(file)
+	if err != nil {
+		t.t.Fatalf("Cannot load OpenAPI document: %s", err)
+		return t
+	}
+	return t.SetOpenAPI(api)
+}
+
+// SetOpenAPI makes t check each following request and its response
+// against "api", typically loaded once using LoadOpenAPI and shared
+// by several tests. A nil "api" disables the checks, the default. See
+// UseOpenAPI for details.
+func (t *TestAPI) SetOpenAPI(api *OpenAPI) *TestAPI {
+	t.openAPI = api
+	return t
+}
+
+// OpenAPI returns the OpenAPI document used by t to check requests
+// and responses, or nil if none is used.
+func (t *TestAPI) OpenAPI() *OpenAPI {
+	return t.openAPI
+}
+
+// Request sends a new HTTP request to the tested API. Any Cmp* method
+// can then be used to check the response.
+//
+// If a cookie jar is used (see UseCookieJar), its cookies matching
+// "req" URL are added to "req", and cookies set by the response are
+// stored in it.
+//
+// If an OpenAPI document is used (see UseOpenAPI), "req" and its
+// response are checked against it.
+func (t *TestAPI) Request(req *http.Request) *TestAPI {
+	t.t.Helper()
+
+	// Keep the body to be able to dump the request in case of failure
+	t.reqBody = nil
+	if req.Body != nil {
+		body, err := ioutil.ReadAll(req.Body)
+		req.Body.Close() // nolint: errcheck
+		if err != nil {
+			t.t.Fatalf("Cannot read request body: %s", err)
+			return t
+		}
+		t.reqBody = body
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	var (
+		chain redirectChain
+		err   error
+	)
+	if t.maxRedirects > 0 {
+		chain, err = serveFollowingRedirects(t.handler, req, t.reqBody,
+			t.maxRedirects, t.jar)
+		if err != nil {
+			t.t.Fatal(err.Error())
+			return t
+		}
+	} else {
+		chain = redirectChain{
+			req:      req,
+			reqBody:  t.reqBody,
+			response: serve(t.handler, req, t.jar),
+		}
+	}
+	req = chain.req
+	t.reqBody = chain.reqBody
+	t.response = chain.response
+	t.redirects = chain.redirects
+	tooManyRedirects := chain.tooMany
+
+	t.sent = true
+	t.req = req
+	t.failed = false
+	t.statusMismatch = false
+	t.dumped = false
+
+	t.name = t.nextName
+	t.nextName = ""
+
+	if tooManyRedirects {
+		t.t.Errorf("%sstopped after %d redirects", t.name, t.maxRedirects)
+		t.checkResult(false)
+	}
+
+	if t.openAPI != nil {
+		t.checkOpenAPI()
+	}
+	return t
+}
+
+// checkOpenAPI checks the last request and its response against
+// the OpenAPI document.
+func (t *TestAPI) checkOpenAPI() {
+	t.t.Helper()
+
+	reqErr, respErr := t.openAPI.check(t.req, t.reqBody, t.response)
+	if reqErr != nil {
+		t.reportError(reqErr, "request should respect OpenAPI contract")
+	}
+	if respErr != nil {
+		t.reportError(respErr, "response should respect OpenAPI contract")
+	}
+}
+
+// reportError reports "err" as the failure of the test "name", the
+// same way td.T.Cmp does.
+func (t *TestAPI) reportError(err *ctxerr.Error, name string) {
+	t.t.Helper()
+	t.t.Error(formatFailure(err, t.name+name))
+	t.checkResult(false)
+}
+
+// formatFailure formats "err" as the failure of the test "name", the
+// same way td.T.Cmp does.
+func formatFailure(err *ctxerr.Error, name string) string {
+	var buf bytes.Buffer
+	ctxerr.ColorizeTestNameOn(&buf)
+	buf.WriteString("Failed test '")
+	buf.WriteString(name)
+	buf.WriteString("'\n")
+	ctxerr.ColorizeTestNameOff(&buf)
+	err.Append(&buf, "")
+	return buf.String()
+}
+
+// Get sends a HTTP GET to the tested API. Any Cmp* method can then be
+// used to check the response.
+//
+// "headers" can contain http.Header instances or pairs of strings
+// (key then value), added to the request header.
+func (t *TestAPI) Get(target string, headers ...interface{}) *TestAPI {
+	t.t.Helper()
+	return t.Request(newRequest("GET", target, nil, headers))
+}
+
+// Head sends a HTTP HEAD to the tested API. Any Cmp* method can then
+// be used to check the response.
+//
+// "headers" can contain http.Header instances or pairs of strings
+// (key then value), added to the request header.
+func (t *TestAPI) Head(target string, headers ...interface{}) *TestAPI {
+	t.t.Helper()
+	return t.Request(newRequest("HEAD", target, nil, headers))
+}
+
+// Post sends a HTTP POST to the tested API. Any Cmp* method can then
+// be used to check the response.
+//
+// "headers" can contain http.Header instances or pairs of strings
+// (key then value), added to the request header.
+func (t *TestAPI) Post(target string, body io.Reader, headers ...interface{}) *TestAPI {
+	t.t.Helper()
+	return t.Request(newRequest("POST", target, body, headers))
+}
+
+// Put sends a HTTP PUT to the tested API. Any Cmp* method can then be
+// used to check the response.
+//
+// "headers" can contain http.Header instances or pairs of strings
+// (key then value), added to the request header.
+func (t *TestAPI) Put(target string, body io.Reader, headers ...interface{}) *TestAPI {
+	t.t.Helper()
+	return t.Request(newRequest("PUT", target, body, headers))
+}
+
+// Patch sends a HTTP PATCH to the tested API. Any Cmp* method can
+// then be used to check the response.
+//
+// "headers" can contain http.Header instances or pairs of strings
+// (key then value), added to the request header.
+func (t *TestAPI) Patch(target string, body io.Reader, headers ...interface{}) *TestAPI {
+	t.t.Helper()
+	return t.Request(newRequest("PATCH", target, body, headers))
+}
+
+// Delete sends a HTTP DELETE to the tested API. Any Cmp* method can
+// then be used to check the response.
+//
+// "headers" can contain http.Header instances or pairs of strings
+// (key then value), added to the request header.
+func (t *TestAPI) Delete(target string, body io.Reader, headers ...interface{}) *TestAPI {
+	t.t.Helper()
+	return t.Request(newRequest("DELETE", target, body, headers))
+}
+
+// PostJSON sends a HTTP POST with "body" JSON-marshaled to the tested
+// API. Any Cmp* method can then be used to check the response.
+//
+// "headers" can contain http.Header instances or pairs of strings
+// (key then value), added to the request header.
+func (t *TestAPI) PostJSON(target string, body interface{}, headers ...interface{}) *TestAPI {
+	t.t.Helper()
+	return t.Request(NewJSONRequest("POST", target, body, headers...))
+}
+
+// PutJSON sends a HTTP PUT with "body" JSON-marshaled to the tested
+// API. Any Cmp* method can then be used to check the response.
+//
+// "headers" can contain http.Header instances or pairs of strings
+// (key then value), added to the request header.
+func (t *TestAPI) PutJSON(target string, body interface{}, headers ...interface{}) *TestAPI {
+	t.t.Helper()
+	return t.Request(NewJSONRequest("PUT", target, body, headers...))
+}
+
+// PatchJSON sends a HTTP PATCH with "body" JSON-marshaled to the
+// tested API. Any Cmp* method can then be used to check the response.
+//
+// "headers" can contain http.Header instances or pairs of strings
+// (key then value), added to the request header.
+func (t *TestAPI) PatchJSON(target string, body interface{}, headers ...interface{}) *TestAPI {
+	t.t.Helper()
+	return t.Request(NewJSONRequest("PATCH", target, body, headers...))
+}
+
+// PostXML sends a HTTP POST with "body" XML-marshaled to the tested
+// API. Any Cmp* method can then be used to check the response.
+//
+// "headers" can contain http.Header instances or pairs of strings
+// (key then value), added to the request header.
+func (t *TestAPI) PostXML(target string, body interface{}, headers ...interface{}) *TestAPI {
+	t.t.Helper()
+	return t.Request(NewXMLRequest("POST", target, body, headers...))
+}
+
+// PutXML sends a HTTP PUT with "body" XML-marshaled to the tested
+// API. Any Cmp* method can then be used to check the response.
+//
+// "headers" can contain http.Header instances or pairs of strings
+// (key then value), added to the request header.
+func (t *TestAPI) PutXML(target string, body interface{}, headers ...interface{}) *TestAPI {
+	t.t.Helper()
+	return t.Request(NewXMLRequest("PUT", target, body, headers...))
+}
+
+// PostForm sends a HTTP POST with "data" URL-encoded to the tested
+// API. "data" can be an url.Values, a map[string]string, a
+// map[string][]string or a struct, see AddQueryParams. Any Cmp*
+// method can then be used to check the response.
+//
+// "headers" can contain http.Header instances or pairs of strings
+// (key then value), added to the request header.
+func (t *TestAPI) PostForm(target string, data interface{}, headers ...interface{}) *TestAPI {
+	t.t.Helper()
+	return t.Request(NewFormRequest("POST", target, data, headers...))
+}
+
+// PostMultipart sends a HTTP POST with a multipart/form-data body
+// built from "parts" to the tested API. Any Cmp* method can then be
+// used to check the response.
+//
+// "headers" can contain http.Header instances or pairs of strings
+// (key then value), added to the request header.
+func (t *TestAPI) PostMultipart(target string, parts []MultipartPart, headers ...interface{}) *TestAPI {
+	t.t.Helper()
+	return t.Request(NewMultipartRequest("POST", target, parts, headers...))
+}
+
+// checkRequestSent fails the test if no request has been sent yet.
+func (t *TestAPI) checkRequestSent() bool {
+	t.t.Helper()
+
+	if !t.sent {
+		t.t.Error("A request must be sent before testing the response")
+		t.failed = true
+		return false
+	}
+	return true
+}
+
+// checkResult records the result of a check, and dumps the request
+// and its response on the first failure.
+func (t *TestAPI) checkResult(ok bool) {
+	t.t.Helper()
+
+	if ok {
+		return
+	}
+	t.failed = true
+
+	if !t.dumped {
+		t.dumped = true
+		t.dump()
+	}
+}
+
+// dump logs the last request sent and its response.
+func (t *TestAPI) dump() {
+	t.t.Helper()
+
+	var buf bytes.Buffer
+
+	if len(t.redirects) > 0 {
+		buf.WriteString("Redirects:\n")
+		for _, redirect := range t.redirects {
+			fmt.Fprintf(&buf, "%d → %s\n", redirect.Status, redirect.Location)
+		}
+		buf.WriteString("\n")
+	}
+
+	req := *t.req
+	if t.reqBody != nil {
+		req.Body = ioutil.NopCloser(bytes.NewReader(t.reqBody))
+	}
+	if dump, err := httputil.DumpRequest(&req, true); err == nil {
+		buf.WriteString("Request:\n")
+		buf.Write(bytes.TrimRight(dump, "\r\n"))
+		buf.WriteString("\n\n")
+	}
+
+	if dump, err := httputil.DumpResponse(t.response.Result(), true); err == nil {
+		buf.WriteString("Response:\n")
+		buf.Write(bytes.TrimRight(dump, "\r\n"))
+	}
+
+	t.t.Log(buf.String())
+}
+
+// CmpStatus tests the last request response status against
+// "expectedStatus". "expectedStatus" can be an int to match a fixed
+// HTTP status code, or a TestDeep operator.
+func (t *TestAPI) CmpStatus(expectedStatus interface{}) *TestAPI {
+	t.t.Helper()
+
+	if t.checkRequestSent() {
+		ok := t.t.RootName("Response.Status").
+			Cmp(t.response.Code, expectedStatus, t.name+"status code should match")
+		t.statusMismatch = !ok
+		t.checkResult(ok)
+	}
+	return t
+}
+
+// CmpHeader tests the last request response header against
+// "expectedHeader". "expectedHeader" can be a http.Header or a
+// TestDeep operator. Keep in mind that if it is a http.Header, it has
+// to match exactly the response header. Often only the presence of a
+// header key is needed:
+//
+//   ta := tdhttp.NewTestAPI(t, mux).
+//     Get("/test").
+//     CmpHeader(td.ContainsKey("X-Custom"))
+//
+// or some specific key, value pairs:
+//
+//   ta.CmpHeader(td.SuperMapOf(http.Header{"X-Account": []string{"Bob"}}, nil))
+func (t *TestAPI) CmpHeader(expectedHeader interface{}) *TestAPI {
+	t.t.Helper()
+
+	if t.checkRequestSent() {
+		t.checkResult(t.t.RootName("Response.Header").
+			Cmp(t.response.Header(), expectedHeader, t.name+"header should match"))
+	}
+	return t
+}
+
+// CmpCookies tests the last request response cookies, the ones set
+// using Set-Cookie header, against "expectedCookies".
+// "expectedCookies" can be a []*http.Cookie or a TestDeep operator:
+//
+//   ta := tdhttp.NewTestAPI(t, mux)
+//
+//   ta.Get("/login").
+//     CmpStatus(http.StatusOK).
+//     CmpCookies(td.SuperBagOf(td.Struct(&http.Cookie{Name: "session"},
+//       td.StructFields{
+//         "Value":    td.Len(32),
+//         "Path":     "/",
+//         "HttpOnly": true,
+//         "Secure":   true,
+//         "SameSite": http.SameSiteStrictMode,
+//         "Expires":  td.Between(time.Now(), time.Now().Add(time.Hour)),
+//       })))
+func (t *TestAPI) CmpCookies(expectedCookies interface{}) *TestAPI {
+	t.t.Helper()
+
+	if t.checkRequestSent() {
+		t.checkResult(t.t.RootName("Response.Cookie").
+			Cmp(t.response.Result().Cookies(), expectedCookies,
+				t.name+"cookies should match"))
+	}
+	return t
+}
+
+// CmpRedirects tests the redirections followed by the last request
+// (see FollowRedirects) against "expectedRedirects".
+// "expectedRedirects" can be a []Redirect or a TestDeep operator. An
+// empty chain is a []Redirect{}:
+//
+//   ta.Get("/old").
+//     CmpRedirects(td.Slice([]tdhttp.Redirect{}, td.ArrayEntries{
+//       0: tdhttp.Redirect{Status: http.StatusMovedPermanently, Location: "/new"},
+//       1: td.Struct(tdhttp.Redirect{Status: http.StatusFound},
+//         td.StructFields{"Location": td.HasPrefix("/login?")}),
+//     }))
+func (t *TestAPI) CmpRedirects(expectedRedirects interface{}) *TestAPI {
+	t.t.Helper()
+
+	if t.checkRequestSent() {
+		redirects := t.redirects
+		if redirects == nil {
+			redirects = []Redirect{}
+		}
+		t.checkResult(t.t.RootName("Response.Redirects").
+			Cmp(redirects, expectedRedirects, t.name+"redirects should match"))
+	}
+	return t
+}
+
+// cmpMarshaledBody tests the last request response body, unmarshaled
+// using dec, against expectedBody.
+func (t *TestAPI) cmpMarshaledBody(dec decoder, expectedBody interface{}) *TestAPI {
+	t.t.Helper()
+
+	if t.checkRequestSent() {
+		t.checkResult(cmpMarshaledBody(t.t, t.name, t.response.Header(),
+			t.response.Body.Bytes(), dec, expectedBody, t.statusMismatch))
+	}
+	return t
+}
+
+// CmpBody tests the last request response body against
+// "expectedBody". "expectedBody" can be a []byte, a string or a
+// TestDeep operator. If nil, the body is expected to be empty. The
+// body is decoded as CmpResponse does, depending on the response
+// Content-Encoding and Content-Type headers.
+//
+//   ta := tdhttp.NewTestAPI(t, mux)
+//
+//   ta.Get("/test").
+//     CmpStatus(http.StatusOK).
+//     CmpBody("OK!\n")
+//
+//   ta.Get("/test").
+//     CmpStatus(http.StatusOK).
+//     CmpBody(td.All(td.Isa(""), td.Contains("OK")))
+func (t *TestAPI) CmpBody(expectedBody interface{}) *TestAPI {
+	t.t.Helper()
+	return t.cmpMarshaledBody(rawDecoder, expectedBody)
+}
+
+// CmpJSONBody tests the last request response body against
+// "expectedBody". "expectedBody" can be a type in which the JSON body
+// is unmarshaled or a TestDeep operator. If nil, the body is expected
+// to be empty.
+//
+//   ta := tdhttp.NewTestAPI(t, mux)
+//
+//   ta.Get("/person/42").
+//     CmpStatus(http.StatusOK).
+//     CmpJSONBody(Person{
+//       ID:   42,
+//       Name: "Bob",
+//       Age:  26,
+//     })
+//
+//   ta.PostJSON("/person", Person{Name: "Bob", Age: 23}).
+//     CmpStatus(http.StatusCreated).
+//     CmpJSONBody(td.Struct(
+//       Person{
+//         Name: "Bob",
+//         Age:  26,
+//       },
+//       td.StructFields{
+//         "ID": td.NotZero(),
+//       }))
+func (t *TestAPI) CmpJSONBody(expectedBody interface{}) *TestAPI {
+	t.t.Helper()
+	return t.cmpMarshaledBody(jsonDecoder, expectedBody)
+}
+
+// CmpXMLBody tests the last request response body against
+// "expectedBody". "expectedBody" can be a type in which the XML body
+// is unmarshaled or a TestDeep operator. If nil, the body is expected
+// to be empty.
+func (t *TestAPI) CmpXMLBody(expectedBody interface{}) *TestAPI {
+	t.t.Helper()
+	return t.cmpMarshaledBody(xmlDecoder, expectedBody)
+}
+
+// CmpSSEBody tests the last request response body, parsed as
+// Server-Sent Events using ParseSSE, against "expectedBody".
+// "expectedBody" can be a []SSEEvent or a TestDeep operator. If nil,
+// the body is expected to be empty.
+//
+//   ta.Get("/events").
+//     CmpStatus(http.StatusOK).
+//     CmpSSEBody([]tdhttp.SSEEvent{
+//       {Event: "add", Data: "Bob"},
+//       {Event: "del", Data: "Alice"},
+//     })
+func (t *TestAPI) CmpSSEBody(expectedBody interface{}) *TestAPI {
+	t.t.Helper()
+	return t.cmpMarshaledBody(sseDecoder, expectedBody)
+}
+
+// CmpNDJSONBody tests the last request response body, as a stream
+// of newline-delimited JSON values, against "expectedBody".
+// "expectedBody" can be a slice in which each line is unmarshaled or
+// a TestDeep operator. If nil, the body is expected to be empty.
+//
+//   ta.Get("/persons").
+//     CmpStatus(http.StatusOK).
+//     CmpNDJSONBody([]Person{
+//       {ID: 1, Name: "Bob"},
+//       {ID: 2, Name: "Alice"},
+//     })
+//
+// As Bag operator does not know the type behind it, each line would
+// be unmarshaled in an interface{}. Isa operator can be used to
+// disambiguate:
+//
+//   ta.Get("/persons").
+//     CmpNDJSONBody(td.All(
+//       td.Isa([]Person{}),
+//       td.Bag(Person{ID: 2, Name: "Alice"}, Person{ID: 1, Name: "Bob"}),
+//     ))
+func (t *TestAPI) CmpNDJSONBody(expectedBody interface{}) *TestAPI {
+	t.t.Helper()
+	return t.cmpMarshaledBody(ndjsonDecoder, expectedBody)
+}
+
+// CmpChunks tests the last request response body chunks, as flushed
+// by the handler (see FlushRecorder), against "expectedChunks".
+// "expectedChunks" can be a []string or a TestDeep operator:
+//
+//   ta.Get("/events").
+//     CmpStatus(http.StatusOK).
+//     CmpChunks(td.Len(td.Gte(2))) // at least 2 chunks were sent
+//
+// When the tested handler is a Server, chunks are the ones received
+// from the connection, so their boundaries are not guaranteed (see
+// Server).
+func (t *TestAPI) CmpChunks(expectedChunks interface{}) *TestAPI {
+	t.t.Helper()
+
+	if t.checkRequestSent() {
+		t.checkResult(t.t.RootName("Response.Chunks").
+			Cmp(t.response.Chunks(), expectedChunks, t.name+"chunks should match"))
+	}
+	return t
+}
+
+// NoBody tests that the last request response body is empty.
+func (t *TestAPI) NoBody() *TestAPI {
+	t.t.Helper()
+	return t.CmpBody(nil)
+}
+
+// Failed returns true if any Cmp* or NoBody method failed since last
+// request sending.
+func (t *TestAPI) Failed() bool {
+	return t.failed
+}
+
+// newRequest creates a new request, adding "headers" to it.
+func newRequest(method, target string, body io.Reader, headers []interface{}) *http.Request {
+	req := NewRequest(method, target, body)
+	addHeaders(req, headers)
+	return req
+}
+
+// jarURLOf returns the URL of "req" to use with cookie jars. As the
+// tested handler is directly called, the connection is considered
+// secure and the scheme is always https, so Secure cookies are sent
+// back too.
+func jarURLOf(req *http.Request) *url.URL {
+	u := requestURL(req)
+	u.Scheme = "https"
+	return u
+}
+
+// requestURL returns the absolute URL of "req".
+func requestURL(req *http.Request) *url.URL {
+	u := *req.URL
+	if u.Host == "" {
+		u.Host = req.Host
+	}
+	if u.Scheme == "" {
+		if req.TLS != nil {
+			u.Scheme = "https"
+		} else {
+			u.Scheme = "http"
+		}
+	}
+	return &u
+}
@@ -0,0 +1,388 @@
+// Copyright (c) 2019, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package tdhttp_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	td "github.com/maxatome/go-testdeep"
+	"github.com/maxatome/go-testdeep/helpers/tdhttp"
+	"github.com/maxatome/go-testdeep/internal/test"
+)
+
+// recordT records errors and logs, to check them.
+type recordT struct {
+	test.TestingFT
+	errors []string
+	logs   []string
+}
+
+func (t *recordT) Error(args ...interface{}) {
+	t.TestingFT.Error(args...)
+	t.errors = append(t.errors, fmt.Sprint(args...))
+}
+
+func (t *recordT) Fatal(args ...interface{}) {
+	t.TestingFT.Fatal(args...)
+	t.errors = append(t.errors, fmt.Sprint(args...))
+}
+
+func (t *recordT) Errorf(format string, args ...interface{}) {
+	t.Error(fmt.Sprintf(format, args...))
+}
+
+func (t *recordT) Fatalf(format string, args ...interface{}) {
+	t.Fatal(fmt.Sprintf(format, args...))
+}
+
+func (t *recordT) Log(args ...interface{}) {
+	t.logs = append(t.logs, fmt.Sprint(args...))
+}
+
+func (t *recordT) Logf(format string, args ...interface{}) {
+	t.logs = append(t.logs, fmt.Sprintf(format, args...))
+}
+
+func (t *recordT) Errors() string {
+	return strings.Join(t.errors, "\n")
+}
+
+func (t *recordT) Logs() string {
+	return strings.Join(t.logs, "\n")
+}
+
+type Person struct {
+	ID   int64  `json:"id" xml:"id"`
+	Name string `json:"name" xml:"name"`
+}
+
+func personMux() *http.ServeMux {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/person", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != "POST" {
+			http.NotFound(w, req)
+			return
+		}
+
+		var p Person
+		if err := json.NewDecoder(req.Body).Decode(&p); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		p.ID = 42
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(p) // nolint: errcheck
+	})
+
+	mux.HandleFunc("/text", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("X-Method", req.Method)
+		w.Header().Set("X-Custom", req.Header.Get("X-Custom"))
+		if req.Method == "HEAD" || req.Method == "DELETE" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		fmt.Fprint(w, "Text result!")
+	})
+
+	mux.HandleFunc("/form", func(w http.ResponseWriter, req *http.Request) {
+		fmt.Fprintf(w, "Hello %s!", req.FormValue("name"))
+	})
+
+	mux.HandleFunc("/xml", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		fmt.Fprint(w, `<Person><id>12</id><name>Bob</name></Person>`)
+	})
+
+	return mux
+}
+
+func TestTestAPI(t *testing.T) {
+	mux := personMux()
+
+	t.Run("Success", func(t *testing.T) {
+		ta := tdhttp.NewTestAPI(t, mux)
+
+		ta.Get("/text", "X-Custom", "zip").
+			CmpStatus(http.StatusOK).
+			CmpHeader(td.SuperMapOf(http.Header{
+				"X-Method": []string{"GET"},
+				"X-Custom": []string{"zip"},
+			}, nil)).
+			CmpBody("Text result!")
+		td.CmpFalse(t, ta.Failed())
+
+		ta.Head("/text").
+			CmpStatus(http.StatusNoContent).
+			NoBody()
+		td.CmpFalse(t, ta.Failed())
+
+		ta.Delete("/text", nil, http.Header{"X-Custom": []string{"del"}}).
+			CmpStatus(http.StatusNoContent).
+			CmpHeader(td.SuperMapOf(http.Header{"X-Custom": []string{"del"}}, nil)).
+			NoBody()
+		td.CmpFalse(t, ta.Failed())
+
+		for _, method := range []string{"POST", "PUT", "PATCH"} {
+			var tap *tdhttp.TestAPI
+			switch method {
+			case "POST":
+				tap = ta.Post("/text", strings.NewReader("body"))
+			case "PUT":
+				tap = ta.Put("/text", strings.NewReader("body"))
+			case "PATCH":
+				tap = ta.Patch("/text", strings.NewReader("body"))
+			}
+			tap.CmpStatus(http.StatusOK).
+				CmpHeader(td.SuperMapOf(http.Header{"X-Method": []string{method}}, nil)).
+				CmpBody(td.All(td.Isa(""), td.Contains("result")))
+			td.CmpFalse(t, ta.Failed(), method)
+		}
+
+		ta.Name("Create %s", "Bob").
+			PostJSON("/person", Person{Name: "Bob"}).
+			CmpStatus(http.StatusCreated).
+			CmpJSONBody(Person{ID: 42, Name: "Bob"}).
+			CmpJSONBody(td.Struct(Person{Name: "Bob"}, td.StructFields{
+				"ID": td.NotZero(),
+			}))
+		td.CmpFalse(t, ta.Failed())
+
+		ta.PostForm("/form", map[string]string{"name": "Bob"}).
+			CmpStatus(http.StatusOK).
+			CmpBody("Hello Bob!")
+		td.CmpFalse(t, ta.Failed())
+
+		ta.PostMultipart("/form", []tdhttp.MultipartPart{
+			{Name: "name", Content: strings.NewReader("Alice")},
+		}).
+			CmpStatus(http.StatusOK).
+			CmpBody("Hello Alice!")
+		td.CmpFalse(t, ta.Failed())
+
+		ta.Get("/xml").
+			CmpStatus(http.StatusOK).
+			CmpXMLBody(Person{ID: 12, Name: "Bob"})
+		td.CmpFalse(t, ta.Failed())
+
+		td.Cmp(t, ta.T(), td.NotNil())
+	})
+
+	t.Run("Failures", func(t *testing.T) {
+		mockT := &recordT{}
+		ta := tdhttp.NewTestAPI(mockT, mux)
+
+		ta.CmpStatus(200)
+		td.CmpTrue(t, ta.Failed())
+		td.Cmp(t, mockT.Errors(), td.Contains("A request must be sent before testing the response"))
+
+		mockT = &recordT{}
+		ta = tdhttp.NewTestAPI(mockT, mux)
+		ta.Name("Create Bob").
+			PostJSON("/person", Person{Name: "Bob"}).
+			CmpStatus(http.StatusOK).
+			CmpHeader(td.ContainsKey("X-Unknown")).
+			CmpJSONBody(Person{ID: 43, Name: "Bob"})
+		td.CmpTrue(t, ta.Failed())
+		td.Cmp(t, mockT.errors, td.Len(3))
+		td.Cmp(t, mockT.Errors(), td.All(
+			td.Contains("Failed test 'Create Bob: status code should match'"),
+			td.Contains("Failed test 'Create Bob: header should match'"),
+			td.Contains("Failed test 'Create Bob: body contents is OK'"),
+		))
+		// Dumped only once
+		td.Cmp(t, mockT.logs, td.Len(1))
+		td.Cmp(t, mockT.Logs(), td.All(
+			td.Contains("Request:\nPOST /person HTTP/1.1"),
+			td.Contains(`{"id":0,"name":"Bob"}`),
+			td.Contains("Response:\nHTTP/1.1 201 Created"),
+			td.Contains(`{"id":42,"name":"Bob"}`),
+		))
+
+		// Name only applies to the request following it
+		mockT.errors = nil
+		ta.Get("/text").CmpStatus(http.StatusNotFound)
+		td.CmpTrue(t, ta.Failed())
+		td.Cmp(t, mockT.Errors(), td.Contains("Failed test 'status code should match'"))
+
+		// Failed() is reset by each request
+		ta.Get("/text").CmpStatus(http.StatusOK)
+		td.CmpFalse(t, ta.Failed())
+
+		mockT = &recordT{}
+		ta = tdhttp.NewTestAPI(mockT, mux)
+		ta.Get("/text").NoBody()
+		td.CmpTrue(t, ta.Failed())
+		td.Cmp(t, mockT.Errors(), td.Contains("Failed test 'body should be empty'"))
+	})
+
+	t.Run("Bad headers", func(t *testing.T) {
+		ta := tdhttp.NewTestAPI(t, mux)
+
+		td.CmpPanic(t, func() { ta.Get("/text", "X-Custom") },
+			td.HasPrefix("headers must be http.Header or string pairs, no value"))
+		td.CmpPanic(t, func() { ta.Get("/text", "X-Custom", 12) },
+			td.HasPrefix("headers must be http.Header or string pairs, "))
+		td.CmpPanic(t, func() { ta.Get("/text", 12) },
+			"headers must be http.Header or string pairs, not int")
+	})
+}
+
+func sessionMux() *http.ServeMux {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/login", func(w http.ResponseWriter, req *http.Request) {
+		http.SetCookie(w, &http.Cookie{
+			Name:     "session",
+			Value:    req.URL.Query().Get("user"),
+			Path:     "/",
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		})
+	})
+
+	mux.HandleFunc("/secure-login", func(w http.ResponseWriter, req *http.Request) {
+		http.SetCookie(w, &http.Cookie{
+			Name:     "session",
+			Value:    req.URL.Query().Get("user"),
+			Path:     "/",
+			Secure:   true,
+			HttpOnly: true,
+		})
+	})
+
+	mux.HandleFunc("/logout", func(w http.ResponseWriter, req *http.Request) {
+		http.SetCookie(w, &http.Cookie{
+			Name:   "session",
+			Path:   "/",
+			MaxAge: -1,
+		})
+	})
+
+	mux.HandleFunc("/whoami", func(w http.ResponseWriter, req *http.Request) {
+		cookie, err := req.Cookie("session")
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, cookie.Value)
+	})
+
+	return mux
+}
+
+func TestTestAPICookies(t *testing.T) {
+	mux := sessionMux()
+
+	t.Run("No jar", func(t *testing.T) {
+		ta := tdhttp.NewTestAPI(t, mux)
+		td.CmpNil(t, ta.CookieJar())
+
+		ta.Get("/login?user=bob").
+			CmpStatus(http.StatusOK).
+			CmpCookies([]*http.Cookie{{
+				Name:     "session",
+				Value:    "bob",
+				Path:     "/",
+				HttpOnly: true,
+				SameSite: http.SameSiteLaxMode,
+				Raw:      "session=bob; Path=/; HttpOnly; SameSite=Lax",
+			}})
+
+		ta.Get("/whoami").
+			CmpStatus(http.StatusUnauthorized)
+
+		// ClearCookies does nothing without jar
+		td.CmpNil(t, ta.ClearCookies().CookieJar())
+	})
+
+	t.Run("Jar", func(t *testing.T) {
+		ta := tdhttp.NewTestAPI(t, mux).UseCookieJar()
+		td.CmpNotNil(t, ta.CookieJar())
+
+		ta.Get("/login?user=bob").
+			CmpStatus(http.StatusOK).
+			CmpCookies(td.Bag(td.Struct(&http.Cookie{Name: "session", Value: "bob"},
+				td.StructFields{
+					"HttpOnly": true,
+					"Secure":   false,
+					"SameSite": http.SameSiteLaxMode,
+				})))
+
+		ta.Get("/whoami").
+			CmpStatus(http.StatusOK).
+			CmpBody("bob").
+			CmpCookies(td.Empty())
+
+		ta.Get("/logout").
+			CmpStatus(http.StatusOK).
+			CmpCookies(td.Bag(td.Struct(&http.Cookie{Name: "session", MaxAge: -1}, nil)))
+
+		ta.Get("/whoami").
+			CmpStatus(http.StatusUnauthorized)
+
+		ta.Get("/login?user=alice")
+		ta.Get("/whoami").CmpBody("alice")
+
+		jar := ta.CookieJar()
+		ta.ClearCookies()
+		td.CmpNotNil(t, ta.CookieJar())
+		td.Cmp(t, ta.CookieJar(), td.Not(td.Shallow(jar)), "jar is replaced")
+		ta.Get("/whoami").CmpStatus(http.StatusUnauthorized)
+
+		// Secure cookies are sent back too
+		ta.Get("/secure-login?user=carol").
+			CmpCookies(td.Bag(td.Struct(&http.Cookie{Name: "session", Secure: true}, nil)))
+		ta.Get("/whoami").
+			CmpStatus(http.StatusOK).
+			CmpBody("carol")
+
+		ta.SetCookieJar(nil)
+		td.CmpNil(t, ta.CookieJar())
+	})
+
+	t.Run("Pre-seeding", func(t *testing.T) {
+		ta := tdhttp.NewTestAPI(t, mux).
+			AddCookies("/", &http.Cookie{Name: "session", Value: "carol"})
+		td.CmpNotNil(t, ta.CookieJar())
+
+		ta.Get("/whoami").
+			CmpStatus(http.StatusOK).
+			CmpBody("carol")
+
+		ta.AddCookies("http://example.com/", &http.Cookie{Name: "session", Value: "dave"})
+		ta.Get("/whoami").CmpBody("dave")
+
+		// Not the same host
+		ta.ClearCookies().
+			AddCookies("http://other.com/", &http.Cookie{Name: "session", Value: "eve"})
+		ta.Get("/whoami").CmpStatus(http.StatusUnauthorized)
+	})
+
+	t.Run("Failure", func(t *testing.T) {
+		mockT := &recordT{}
+		ta := tdhttp.NewTestAPI(mockT, mux)
+
+		ta.CmpCookies(td.Empty())
+		td.Cmp(t, mockT.Errors(),
+			td.Contains("A request must be sent before testing the response"))
+
+		mockT = &recordT{}
+		ta = tdhttp.NewTestAPI(mockT, mux)
+		ta.Get("/login?user=bob").
+			CmpCookies(td.Empty())
+		td.CmpTrue(t, ta.Failed())
+		td.Cmp(t, mockT.Errors(), td.Contains("Failed test 'cookies should match'"))
+		td.Cmp(t, mockT.Logs(), td.Contains("Set-Cookie: session=bob; Path=/; HttpOnly; SameSite=Lax"))
+	})
+}
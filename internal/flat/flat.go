@@ -0,0 +1,65 @@
+// Copyright (c) 2019, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package flat
+
+import (
+	"reflect"
+
+	"github.com/maxatome/go-testdeep/helpers/tdutil"
+)
+
+// Slice is a slice, an array or a map whose items have to be
+// flattened in the list of items it belongs to.
+type Slice struct {
+	Slice interface{}
+}
+
+// appendValuesTo appends the items of "s" to "values". Map values
+// are appended sorted by their keys. Items that are Slice instances
+// are recursively flattened.
+func (s Slice) appendValuesTo(values []reflect.Value) []reflect.Value {
+	v := reflect.ValueOf(s.Slice)
+
+	switch v.Kind() {
+	case reflect.Array, reflect.Slice:
+		for i, l := 0, v.Len(); i < l; i++ {
+			values = appendValueTo(values, v.Index(i))
+		}
+
+	default: // reflect.Map
+		for _, k := range tdutil.MapSortedKeys(v) {
+			values = appendValueTo(values, v.MapIndex(k))
+		}
+	}
+	return values
+}
+
+func appendValueTo(values []reflect.Value, v reflect.Value) []reflect.Value {
+	if v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	if v.IsValid() && v.CanInterface() {
+		if s, ok := v.Interface().(Slice); ok {
+			return s.appendValuesTo(values)
+		}
+	}
+	return append(values, v)
+}
+
+// Values returns "items" as a slice of reflect.Value, Slice items
+// being flattened.
+func Values(items []interface{}) []reflect.Value {
+	values := make([]reflect.Value, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(Slice); ok {
+			values = s.appendValuesTo(values)
+			continue
+		}
+		values = append(values, reflect.ValueOf(item))
+	}
+	return values
+}
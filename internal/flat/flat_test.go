@@ -0,0 +1,66 @@
+// Copyright (c) 2019, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package flat_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/internal/flat"
+	"github.com/maxatome/go-testdeep/internal/test"
+)
+
+func checkValues(t *testing.T, got []reflect.Value, expected ...interface{}) {
+	t.Helper()
+
+	if !test.EqualInt(t, len(got), len(expected)) {
+		return
+	}
+	for i, v := range got {
+		var gotItem interface{}
+		if v.IsValid() {
+			gotItem = v.Interface()
+		}
+		if !reflect.DeepEqual(gotItem, expected[i]) {
+			t.Errorf("item #%d: got %#v, expected %#v", i, gotItem, expected[i])
+		}
+	}
+}
+
+func TestValues(t *testing.T) {
+	checkValues(t, flat.Values(nil))
+	checkValues(t, flat.Values([]interface{}{1, "a", nil}), 1, "a", nil)
+
+	checkValues(t,
+		flat.Values([]interface{}{
+			0,
+			flat.Slice{Slice: []int{1, 2}},
+			flat.Slice{Slice: [2]string{"a", "b"}},
+			flat.Slice{Slice: []int(nil)},
+			3,
+		}),
+		0, 1, 2, "a", "b", 3)
+
+	// Map values are sorted by their keys
+	checkValues(t,
+		flat.Values([]interface{}{
+			flat.Slice{Slice: map[string]int{"b": 2, "c": 3, "a": 1}},
+		}),
+		1, 2, 3)
+
+	// Recursively flattened, other slices are kept as is
+	checkValues(t,
+		flat.Values([]interface{}{
+			flat.Slice{Slice: []interface{}{
+				1,
+				flat.Slice{Slice: []int{2, 3}},
+				[]int{4, 5},
+				nil,
+			}},
+		}),
+		1, 2, 3, []int{4, 5}, nil)
+}
@@ -0,0 +1,359 @@
+// Copyright (c) 2019, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+// Package jsonschema implements a JSON Schema validator, handling a
+// subset of the draft 2020-12. It is used by the JSONSchema operator
+// and by the tdhttp OpenAPI validation.
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Schema is a JSON Schema, or a sub-schema of a loaded document.
+type Schema struct {
+	node interface{}
+	doc  *document
+}
+
+// document is a loaded JSON document, a schema or a document
+// containing schemas, like an OpenAPI one.
+type document struct {
+	file    string // absolute path, empty if not loaded from a file
+	root    interface{}
+	anchors map[string]interface{}
+	refs    map[string]*Schema
+	loader  *loader
+}
+
+// loader keeps all documents loaded when resolving references, so
+// each file is loaded only once.
+type loader struct {
+	docs     map[string]*document
+	regexps  map[string]*regexp.Regexp
+	nullable bool
+}
+
+// Options are the options used when loading a schema.
+type Options struct {
+	// Nullable enables the OpenAPI 3.0 "nullable" keyword.
+	Nullable bool
+}
+
+// Decode decodes "data" as a JSON value, keeping numbers as
+// json.Number to not lose precision.
+func Decode(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var value interface{}
+	err := dec.Decode(&value)
+	if err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("invalid character after top-level value")
+	}
+	return value, nil
+}
+
+// ToJSON converts "value" to its JSON representation, as Decode
+// returns it.
+func ToJSON(value interface{}) (interface{}, error) {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return Decode(b)
+}
+
+// Parse parses "data" as a JSON Schema. References to other files
+// are resolved relatively to the current directory.
+func Parse(data []byte, opts Options) (*Schema, error) {
+	root, err := Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse JSON Schema: %s", err)
+	}
+	return newLoader(opts).addDocument("", root)
+}
+
+// Load loads the JSON Schema contained in "file". References to
+// other files are resolved relatively to the directory of "file".
+func Load(file string, opts Options) (*Schema, error) {
+	return newLoader(opts).load(file)
+}
+
+func newLoader(opts Options) *loader {
+	return &loader{
+		docs:     map[string]*document{},
+		regexps:  map[string]*regexp.Regexp{},
+		nullable: opts.Nullable,
+	}
+}
+
+func (l *loader) load(file string) (*Schema, error) {
+	file, err := filepath.Abs(file)
+	if err != nil {
+		return nil, err
+	}
+	if doc := l.docs[file]; doc != nil {
+		return &Schema{node: doc.root, doc: doc}, nil
+	}
+
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse %s: %s", file, err)
+	}
+	return l.addDocument(file, root)
+}
+
+// addDocument registers "root" document loaded from "file", then
+// resolves all its references.
+func (l *loader) addDocument(file string, root interface{}) (*Schema, error) {
+	doc := &document{
+		file:    file,
+		root:    root,
+		anchors: map[string]interface{}{},
+		refs:    map[string]*Schema{},
+		loader:  l,
+	}
+	if file != "" {
+		l.docs[file] = doc
+	}
+
+	var refs []string
+	err := doc.walk(root, "", &refs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ref := range refs {
+		if doc.refs[ref] != nil {
+			continue
+		}
+		target, err := doc.resolve(ref)
+		if err != nil {
+			return nil, err
+		}
+		doc.refs[ref] = target
+	}
+
+	return &Schema{node: root, doc: doc}, nil
+}
+
+// walk collects the anchors and the references of "node", and
+// compiles its regexps.
+func (d *document) walk(node interface{}, pointer string, refs *[]string) error {
+	switch node := node.(type) {
+	case []interface{}:
+		for i, item := range node {
+			err := d.walk(item, pointer+"/"+strconv.Itoa(i), refs)
+			if err != nil {
+				return err
+			}
+		}
+
+	case map[string]interface{}:
+		if ref, ok := node["$ref"].(string); ok {
+			*refs = append(*refs, ref)
+		}
+		if anchor, ok := node["$anchor"].(string); ok {
+			d.anchors[anchor] = node
+		}
+		if pattern, ok := node["pattern"].(string); ok {
+			_, err := d.loader.regexp(pattern)
+			if err != nil {
+				return fmt.Errorf("%s: %s", d.location(pointer+"/pattern"), err)
+			}
+		}
+		if props, ok := node["patternProperties"].(map[string]interface{}); ok {
+			for pattern := range props {
+				_, err := d.loader.regexp(pattern)
+				if err != nil {
+					return fmt.Errorf("%s: %s",
+						d.location(pointer+"/patternProperties"), err)
+				}
+			}
+		}
+
+		keys := make([]string, 0, len(node))
+		for key := range node {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			err := d.walk(node[key], pointer+"/"+escapePointer(key), refs)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// location returns the location of "pointer" in d, for error
+// messages.
+func (d *document) location(pointer string) string {
+	return d.file + "#" + pointer
+}
+
+// resolve returns the schema referenced by "ref" from d.
+func (d *document) resolve(ref string) (*Schema, error) {
+	file, fragment := ref, ""
+	if pos := strings.IndexByte(ref, '#'); pos >= 0 {
+		file, fragment = ref[:pos], ref[pos+1:]
+	}
+
+	doc := d
+	if file != "" {
+		if u, err := url.Parse(file); err == nil && u.Scheme != "" {
+			return nil, fmt.Errorf(
+				"cannot resolve $ref %q: only local references are supported", ref)
+		}
+
+		if !filepath.IsAbs(file) && d.file != "" {
+			file = filepath.Join(filepath.Dir(d.file), file)
+		}
+		schema, err := d.loader.load(file)
+		if err != nil {
+			return nil, fmt.Errorf("cannot resolve $ref %q: %s", ref, err)
+		}
+		doc = schema.doc
+	}
+
+	fragment, err := url.PathUnescape(fragment)
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve $ref %q: %s", ref, err)
+	}
+
+	if fragment == "" || fragment[0] == '/' {
+		node, err := lookupPointer(doc.root, fragment)
+		if err != nil {
+			return nil, fmt.Errorf("cannot resolve $ref %q: %s", ref, err)
+		}
+		return &Schema{node: node, doc: doc}, nil
+	}
+
+	node, ok := doc.anchors[fragment]
+	if !ok {
+		return nil, fmt.Errorf("cannot resolve $ref %q: anchor %q not found",
+			ref, fragment)
+	}
+	return &Schema{node: node, doc: doc}, nil
+}
+
+// Pointer returns the sub-schema of s located at JSON pointer
+// "pointer".
+func (s *Schema) Pointer(pointer string) (*Schema, error) {
+	node, err := lookupPointer(s.node, pointer)
+	if err != nil {
+		return nil, err
+	}
+	return &Schema{node: node, doc: s.doc}, nil
+}
+
+// Node returns the JSON value of s.
+func (s *Schema) Node() interface{} {
+	return s.node
+}
+
+// Sub returns the sub-schema "node" of s, that is a value returned by
+// s.Node or by a Node call on one of its sub-schemas. Its references
+// are resolved in the document of s.
+func (s *Schema) Sub(node interface{}) *Schema {
+	return &Schema{node: node, doc: s.doc}
+}
+
+// Deref follows the "$ref" of s, if any. Typically useful for
+// non-schema objects containing references, like OpenAPI ones.
+func (s *Schema) Deref() *Schema {
+	for i := 0; i < maxDepth; i++ {
+		m, ok := s.node.(map[string]interface{})
+		if !ok {
+			break
+		}
+		ref, ok := m["$ref"].(string)
+		if !ok {
+			break
+		}
+		target := s.doc.refs[ref]
+		if target == nil {
+			break
+		}
+		s = target
+	}
+	return s
+}
+
+func lookupPointer(node interface{}, pointer string) (interface{}, error) {
+	if pointer == "" {
+		return node, nil
+	}
+	if pointer[0] != '/' {
+		return nil, fmt.Errorf("invalid JSON pointer %q", pointer)
+	}
+
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = unescapePointer(token)
+
+		switch n := node.(type) {
+		case map[string]interface{}:
+			var ok bool
+			if node, ok = n[token]; ok {
+				continue
+			}
+
+		case []interface{}:
+			idx, err := strconv.Atoi(token)
+			if err == nil && idx >= 0 && idx < len(n) {
+				node = n[idx]
+				continue
+			}
+		}
+		return nil, fmt.Errorf("JSON pointer %q not found", pointer)
+	}
+	return node, nil
+}
+
+var (
+	pointerEscaper   = strings.NewReplacer("~", "~0", "/", "~1")
+	pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+)
+
+func escapePointer(token string) string {
+	return pointerEscaper.Replace(token)
+}
+
+func unescapePointer(token string) string {
+	return pointerUnescaper.Replace(token)
+}
+
+func (l *loader) regexp(pattern string) (*regexp.Regexp, error) {
+	re, ok := l.regexps[pattern]
+	if !ok {
+		var err error
+		re, err = regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		l.regexps[pattern] = re
+	}
+	return re, nil
+}
@@ -0,0 +1,175 @@
+// Copyright (c) 2019, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package jsonschema_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/internal/jsonschema"
+	"github.com/maxatome/go-testdeep/internal/test"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "testdeep")
+	if err != nil {
+		t.Fatalf("TempDir failed: %s", err)
+	}
+
+	for name, content := range files {
+		name = filepath.Join(dir, name)
+		err := os.MkdirAll(filepath.Dir(name), 0755)
+		if err == nil {
+			err = ioutil.WriteFile(name, []byte(content), 0644)
+		}
+		if err != nil {
+			os.RemoveAll(dir) // nolint: errcheck
+			t.Fatalf("Cannot write %s: %s", name, err)
+		}
+	}
+	return dir
+}
+
+func TestLoad(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"person.json": `{
+  "type": "object",
+  "properties": {
+    "name":    {"type": "string"},
+    "age":     {"$ref": "defs/common.json#/$defs/age"},
+    "friends": {"type": "array", "items": {"$ref": "#"}}
+  },
+  "required": ["name"]
+}`,
+		"defs/common.json": `{
+  "$defs": {
+    "age": {"$ref": "#/$defs/positive", "maximum": 150},
+    "positive": {"type": "integer", "minimum": 0}
+  }
+}`,
+		"bad_ref.json":     `{"$ref": "common.json#/$defs/unknown"}`,
+		"bad_pointer.json": `{"$ref": "#/$defs/unknown"}`,
+		"bad_anchor.json":  `{"$ref": "#unknown"}`,
+		"remote.json":      `{"$ref": "http://example.com/schema.json"}`,
+		"bad_re.json":      `{"properties": {"a": {"pattern": "("}}}`,
+		"bad_json.json":    `{"type": }`,
+	})
+	defer os.RemoveAll(dir) // nolint: errcheck
+
+	schema, err := jsonschema.Load(filepath.Join(dir, "person.json"), jsonschema.Options{})
+	if err != nil {
+		t.Fatalf("Load failed: %s", err)
+	}
+
+	value, err := jsonschema.ToJSON(map[string]interface{}{
+		"name": "Bob",
+		"age":  -1,
+		"friends": []interface{}{
+			map[string]interface{}{"name": "Alice", "age": 151},
+			map[string]interface{}{"age": 12},
+		},
+	})
+	if err != nil {
+		t.Fatalf("ToJSON failed: %s", err)
+	}
+
+	var got []string
+	for _, v := range schema.Validate(value) {
+		got = append(got, v.Path+" "+v.Keyword+": "+v.Message)
+	}
+	test.EqualStr(t, strings.Join(got, "\n"), strings.Join([]string{
+		"/age minimum: expected ≥ 0, got -1",
+		"/friends/0/age maximum: expected ≤ 150, got 151",
+		`/friends/1 required: missing required property "name"`,
+	}, "\n"))
+
+	for file, expected := range map[string]string{
+		"bad_ref.json":     `cannot resolve $ref "common.json#/$defs/unknown": `,
+		"bad_pointer.json": `cannot resolve $ref "#/$defs/unknown": JSON pointer "/$defs/unknown" not found`,
+		"bad_anchor.json":  `cannot resolve $ref "#unknown": anchor "unknown" not found`,
+		"remote.json":      `cannot resolve $ref "http://example.com/schema.json": only local references are supported`,
+		"bad_re.json":      "bad_re.json#/properties/a/pattern: error parsing regexp",
+		"bad_json.json":    "cannot parse ",
+		"unknown.json":     "unknown.json",
+	} {
+		_, err := jsonschema.Load(filepath.Join(dir, file), jsonschema.Options{})
+		if test.IsTrue(t, err != nil, file) {
+			test.IsTrue(t, strings.Contains(err.Error(), expected),
+				"%s: %q should contain %q", file, err, expected)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	_, err := jsonschema.Parse([]byte(`{"type":"string"} {}`), jsonschema.Options{})
+	if test.IsTrue(t, err != nil) {
+		test.EqualStr(t, err.Error(),
+			"cannot parse JSON Schema: invalid character after top-level value")
+	}
+}
+
+func TestPointer(t *testing.T) {
+	doc, err := jsonschema.Parse([]byte(`{
+  "components": {
+    "schemas": {
+      "Person": {"type": "object", "required": ["id"]}
+    }
+  },
+  "paths": {
+    "/person/{id}": {
+      "get": {"schema": {"$ref": "#/components/schemas/Person"}}
+    }
+  }
+}`), jsonschema.Options{})
+	if err != nil {
+		t.Fatalf("Parse failed: %s", err)
+	}
+
+	op, err := doc.Pointer("/paths/~1person~1{id}/get")
+	if err != nil {
+		t.Fatalf("Pointer failed: %s", err)
+	}
+
+	schema := op.Sub(op.Node().(map[string]interface{})["schema"])
+	violations := schema.Validate(map[string]interface{}{})
+	if test.EqualInt(t, len(violations), 1) {
+		test.EqualStr(t, violations[0].Message, `missing required property "id"`)
+	}
+
+	person := schema.Deref().Node().(map[string]interface{})
+	test.EqualStr(t, person["type"].(string), "object")
+
+	_, err = doc.Pointer("/paths/~1unknown")
+	if test.IsTrue(t, err != nil) {
+		test.EqualStr(t, err.Error(), `JSON pointer "/paths/~1unknown" not found`)
+	}
+
+	_, err = doc.Pointer("paths")
+	if test.IsTrue(t, err != nil) {
+		test.EqualStr(t, err.Error(), `invalid JSON pointer "paths"`)
+	}
+}
+
+func TestToJSON(t *testing.T) {
+	value, err := jsonschema.ToJSON(struct {
+		Num float64 `json:"num"`
+	}{Num: 1.5})
+	if err != nil {
+		t.Fatalf("ToJSON failed: %s", err)
+	}
+	test.EqualStr(t,
+		string(value.(map[string]interface{})["num"].(json.Number)), "1.5")
+
+	_, err = jsonschema.ToJSON(func() {})
+	test.IsTrue(t, err != nil)
+}
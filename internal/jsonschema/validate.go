@@ -0,0 +1,609 @@
+// Copyright (c) 2019, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxDepth is the maximum number of nested $ref followed, to avoid
+// infinite recursions.
+const maxDepth = 256
+
+// Violation is a JSON Schema violation found by Validate.
+type Violation struct {
+	// Path is the JSON pointer of the invalid value, "" for the root
+	Path string
+	// Keyword is the schema keyword not respected by the value
+	Keyword string
+	// Message describes the violation
+	Message string
+}
+
+type validator struct {
+	violations []Violation
+	depth      int
+}
+
+// Validate validates "value", as returned by Decode or ToJSON,
+// against s. It returns the violations found, or nil if "value" is
+// valid.
+//
+// Handled keywords are: $ref, $anchor, type, enum, const,
+// multipleOf, maximum, exclusiveMaximum, minimum, exclusiveMinimum,
+// maxLength, minLength, pattern, format, prefixItems, items,
+// additionalItems, maxItems, minItems, uniqueItems, contains,
+// maxContains, minContains, properties, patternProperties,
+// additionalProperties, required, maxProperties, minProperties,
+// dependentRequired, dependentSchemas, propertyNames, allOf, anyOf,
+// oneOf, not, if, then and else. Other keywords are ignored.
+func (s *Schema) Validate(value interface{}) []Violation {
+	var v validator
+	v.validate(s, value, "")
+	return v.violations
+}
+
+func (v *validator) report(path, keyword, format string, args ...interface{}) {
+	v.violations = append(v.violations, Violation{
+		Path:    path,
+		Keyword: keyword,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// valid returns true if "value" is valid against "s", without
+// reporting any violation.
+func (v *validator) valid(s *Schema, value interface{}, path string) bool {
+	sub := validator{depth: v.depth}
+	sub.validate(s, value, path)
+	return len(sub.violations) == 0
+}
+
+func (v *validator) validate(s *Schema, value interface{}, path string) {
+	var node map[string]interface{}
+	switch n := s.node.(type) {
+	case bool:
+		if !n {
+			v.report(path, "false", "no value allowed")
+		}
+		return
+	case map[string]interface{}:
+		node = n
+	default:
+		return // not a schema
+	}
+
+	if ref, ok := node["$ref"].(string); ok {
+		if v.depth >= maxDepth {
+			v.report(path, "$ref", "too many nested references")
+			return
+		}
+		if target := s.doc.refs[ref]; target != nil {
+			v.depth++
+			v.validate(target, value, path)
+			v.depth--
+		}
+	}
+
+	if value == nil && s.doc.loader.nullable {
+		if nullable, _ := node["nullable"].(bool); nullable {
+			return
+		}
+	}
+
+	v.validateType(node, value, path)
+
+	switch value := value.(type) {
+	case json.Number:
+		v.validateNumber(node, value, path)
+	case string:
+		v.validateString(s, node, value, path)
+	case []interface{}:
+		v.validateArray(s, node, value, path)
+	case map[string]interface{}:
+		v.validateObject(s, node, value, path)
+	}
+
+	v.validateCombinators(s, node, value, path)
+}
+
+func (v *validator) validateType(node map[string]interface{}, value interface{}, path string) {
+	var expected []string
+	switch t := node["type"].(type) {
+	case string:
+		expected = []string{t}
+	case []interface{}:
+		for _, item := range t {
+			if str, ok := item.(string); ok {
+				expected = append(expected, str)
+			}
+		}
+	}
+
+	if len(expected) > 0 && !matchType(expected, value) {
+		v.report(path, "type", "expected %s, got %s",
+			strings.Join(expected, " or "), jsonType(value))
+	}
+
+	if enum, ok := node["enum"].([]interface{}); ok {
+		found := false
+		for _, item := range enum {
+			if equal(value, item) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			v.report(path, "enum", "expected one of %s, got %s",
+				toString(enum), toString(value))
+		}
+	}
+
+	if expected, ok := node["const"]; ok && !equal(value, expected) {
+		v.report(path, "const", "expected %s, got %s",
+			toString(expected), toString(value))
+	}
+}
+
+func (v *validator) validateNumber(node map[string]interface{}, num json.Number, path string) {
+	r := toRat(num)
+	if r == nil {
+		return
+	}
+
+	if multiple, str := ratKeyword(node, "multipleOf"); multiple != nil &&
+		multiple.Sign() > 0 && !new(big.Rat).Quo(r, multiple).IsInt() {
+		v.report(path, "multipleOf", "expected multiple of %s, got %s", str, num)
+	}
+
+	if max, str := ratKeyword(node, "maximum"); max != nil {
+		// OpenAPI 3.0 / draft 4 boolean form
+		if exclusive, _ := node["exclusiveMaximum"].(bool); exclusive {
+			if r.Cmp(max) >= 0 {
+				v.report(path, "exclusiveMaximum", "expected < %s, got %s", str, num)
+			}
+		} else if r.Cmp(max) > 0 {
+			v.report(path, "maximum", "expected ≤ %s, got %s", str, num)
+		}
+	}
+	if max, str := ratKeyword(node, "exclusiveMaximum"); max != nil && r.Cmp(max) >= 0 {
+		v.report(path, "exclusiveMaximum", "expected < %s, got %s", str, num)
+	}
+
+	if min, str := ratKeyword(node, "minimum"); min != nil {
+		// OpenAPI 3.0 / draft 4 boolean form
+		if exclusive, _ := node["exclusiveMinimum"].(bool); exclusive {
+			if r.Cmp(min) <= 0 {
+				v.report(path, "exclusiveMinimum", "expected > %s, got %s", str, num)
+			}
+		} else if r.Cmp(min) < 0 {
+			v.report(path, "minimum", "expected ≥ %s, got %s", str, num)
+		}
+	}
+	if min, str := ratKeyword(node, "exclusiveMinimum"); min != nil && r.Cmp(min) <= 0 {
+		v.report(path, "exclusiveMinimum", "expected > %s, got %s", str, num)
+	}
+
+	// OpenAPI integer formats
+	if format, ok := node["format"].(string); ok {
+		var min, max int64
+		switch format {
+		case "int32":
+			min, max = math.MinInt32, math.MaxInt32
+		case "int64":
+			min, max = math.MinInt64, math.MaxInt64
+		default:
+			return
+		}
+		if !r.IsInt() || !r.Num().IsInt64() ||
+			r.Num().Int64() < min || r.Num().Int64() > max {
+			v.report(path, "format", "expected %s format, got %s", format, num)
+		}
+	}
+}
+
+func (v *validator) validateString(s *Schema, node map[string]interface{}, str string, path string) {
+	length := utf8.RuneCountInString(str)
+
+	if max, ok := intKeyword(node, "maxLength"); ok && length > max {
+		v.report(path, "maxLength",
+			"expected at most %d characters, got %d", max, length)
+	}
+	if min, ok := intKeyword(node, "minLength"); ok && length < min {
+		v.report(path, "minLength",
+			"expected at least %d characters, got %d", min, length)
+	}
+
+	if pattern, ok := node["pattern"].(string); ok {
+		if re := s.doc.loader.regexps[pattern]; re != nil && !re.MatchString(str) {
+			v.report(path, "pattern", "expected to match %q, got %s",
+				pattern, toString(str))
+		}
+	}
+
+	if format, ok := node["format"].(string); ok && !checkFormat(format, str) {
+		v.report(path, "format", "expected %s format, got %s",
+			format, toString(str))
+	}
+}
+
+func (v *validator) validateArray(s *Schema, node map[string]interface{}, arr []interface{}, path string) {
+	itemPath := func(i int) string {
+		return path + "/" + strconv.Itoa(i)
+	}
+
+	validateTuple := func(tuple []interface{}) int {
+		for i := 0; i < len(tuple) && i < len(arr); i++ {
+			v.validate(s.Sub(tuple[i]), arr[i], itemPath(i))
+		}
+		return len(tuple)
+	}
+
+	next := 0
+	if prefix, ok := node["prefixItems"].([]interface{}); ok {
+		next = validateTuple(prefix)
+	}
+
+	additional, hasAdditional := node["items"]
+	// Before draft 2020-12 (and so in OpenAPI 3.0), items could be
+	// an array of schemas, followed by additionalItems
+	if tuple, ok := additional.([]interface{}); ok {
+		next = validateTuple(tuple)
+		additional, hasAdditional = node["additionalItems"]
+	}
+	if hasAdditional {
+		for i := next; i < len(arr); i++ {
+			v.validate(s.Sub(additional), arr[i], itemPath(i))
+		}
+	}
+
+	if max, ok := intKeyword(node, "maxItems"); ok && len(arr) > max {
+		v.report(path, "maxItems", "expected at most %d items, got %d", max, len(arr))
+	}
+	if min, ok := intKeyword(node, "minItems"); ok && len(arr) < min {
+		v.report(path, "minItems", "expected at least %d items, got %d", min, len(arr))
+	}
+
+	if unique, _ := node["uniqueItems"].(bool); unique {
+	uniqueItems:
+		for i := 0; i < len(arr); i++ {
+			for j := i + 1; j < len(arr); j++ {
+				if equal(arr[i], arr[j]) {
+					v.report(path, "uniqueItems", "items #%d and #%d are equal", i, j)
+					break uniqueItems
+				}
+			}
+		}
+	}
+
+	if contains, ok := node["contains"]; ok {
+		count := 0
+		for i, item := range arr {
+			if v.valid(s.Sub(contains), item, itemPath(i)) {
+				count++
+			}
+		}
+
+		min, keyword := 1, "contains"
+		if minContains, ok := intKeyword(node, "minContains"); ok {
+			min, keyword = minContains, "minContains"
+		}
+		if count < min {
+			v.report(path, keyword,
+				"expected at least %d matching items, got %d", min, count)
+		}
+		if max, ok := intKeyword(node, "maxContains"); ok && count > max {
+			v.report(path, "maxContains",
+				"expected at most %d matching items, got %d", max, count)
+		}
+	}
+}
+
+func (v *validator) validateObject(s *Schema, node map[string]interface{}, obj map[string]interface{}, path string) {
+	if required, ok := node["required"].([]interface{}); ok {
+		for _, name := range required {
+			if name, ok := name.(string); ok {
+				if _, exists := obj[name]; !exists {
+					v.report(path, "required", "missing required property %q", name)
+				}
+			}
+		}
+	}
+
+	props, _ := node["properties"].(map[string]interface{})
+	patternProps, _ := node["patternProperties"].(map[string]interface{})
+	patterns := sortedKeys(patternProps)
+	additional, hasAdditional := node["additionalProperties"]
+	propertyNames, hasPropertyNames := node["propertyNames"]
+
+	for _, key := range sortedKeys(obj) {
+		keyPath := path + "/" + escapePointer(key)
+
+		if hasPropertyNames && !v.valid(s.Sub(propertyNames), key, keyPath) {
+			v.report(keyPath, "propertyNames", "invalid property name %q", key)
+		}
+
+		matched := false
+		if prop, ok := props[key]; ok {
+			matched = true
+			v.validate(s.Sub(prop), obj[key], keyPath)
+		}
+		for _, pattern := range patterns {
+			if re := s.doc.loader.regexps[pattern]; re != nil && re.MatchString(key) {
+				matched = true
+				v.validate(s.Sub(patternProps[pattern]), obj[key], keyPath)
+			}
+		}
+
+		if !matched && hasAdditional {
+			if allowed, ok := additional.(bool); ok && !allowed {
+				v.report(keyPath, "additionalProperties", "property not allowed")
+			} else {
+				v.validate(s.Sub(additional), obj[key], keyPath)
+			}
+		}
+	}
+
+	if max, ok := intKeyword(node, "maxProperties"); ok && len(obj) > max {
+		v.report(path, "maxProperties",
+			"expected at most %d properties, got %d", max, len(obj))
+	}
+	if min, ok := intKeyword(node, "minProperties"); ok && len(obj) < min {
+		v.report(path, "minProperties",
+			"expected at least %d properties, got %d", min, len(obj))
+	}
+
+	if deps, ok := node["dependentRequired"].(map[string]interface{}); ok {
+		for _, key := range sortedKeys(deps) {
+			if _, exists := obj[key]; !exists {
+				continue
+			}
+			names, _ := deps[key].([]interface{})
+			for _, name := range names {
+				if name, ok := name.(string); ok {
+					if _, exists := obj[name]; !exists {
+						v.report(path, "dependentRequired",
+							"missing property %q, required when %q is present", name, key)
+					}
+				}
+			}
+		}
+	}
+
+	if deps, ok := node["dependentSchemas"].(map[string]interface{}); ok {
+		for _, key := range sortedKeys(deps) {
+			if _, exists := obj[key]; exists {
+				v.validate(s.Sub(deps[key]), obj, path)
+			}
+		}
+	}
+}
+
+func (v *validator) validateCombinators(s *Schema, node map[string]interface{}, value interface{}, path string) {
+	if allOf, ok := node["allOf"].([]interface{}); ok {
+		for _, sub := range allOf {
+			v.validate(s.Sub(sub), value, path)
+		}
+	}
+
+	if anyOf, ok := node["anyOf"].([]interface{}); ok {
+		matched := false
+		for _, sub := range anyOf {
+			if v.valid(s.Sub(sub), value, path) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			v.report(path, "anyOf",
+				"expected to match at least one of %d schemas, got none", len(anyOf))
+		}
+	}
+
+	if oneOf, ok := node["oneOf"].([]interface{}); ok {
+		count := 0
+		for _, sub := range oneOf {
+			if v.valid(s.Sub(sub), value, path) {
+				count++
+			}
+		}
+		if count != 1 {
+			got := "none"
+			if count > 1 {
+				got = strconv.Itoa(count)
+			}
+			v.report(path, "oneOf",
+				"expected to match exactly one of %d schemas, got %s", len(oneOf), got)
+		}
+	}
+
+	if not, ok := node["not"]; ok && v.valid(s.Sub(not), value, path) {
+		v.report(path, "not", "expected not to match schema")
+	}
+
+	if cond, ok := node["if"]; ok {
+		if v.valid(s.Sub(cond), value, path) {
+			if then, ok := node["then"]; ok {
+				v.validate(s.Sub(then), value, path)
+			}
+		} else if els, ok := node["else"]; ok {
+			v.validate(s.Sub(els), value, path)
+		}
+	}
+}
+
+// matchType returns true if "value" type is one of "types".
+func matchType(types []string, value interface{}) bool {
+	got := jsonType(value)
+	for _, t := range types {
+		if t == got || (t == "number" && got == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonType returns the JSON Schema type of "value".
+func jsonType(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	case json.Number:
+		if r := toRat(value); r != nil && r.IsInt() {
+			return "integer"
+		}
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// equal returns true if JSON values "a" and "b" are equal. Numbers
+// are compared by value, so 1 and 1.0 are equal.
+func equal(a, b interface{}) bool {
+	switch a := a.(type) {
+	case json.Number:
+		bn, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		ra, rb := toRat(a), toRat(bn)
+		return ra != nil && rb != nil && ra.Cmp(rb) == 0
+
+	case []interface{}:
+		bs, ok := b.([]interface{})
+		if !ok || len(a) != len(bs) {
+			return false
+		}
+		for i := range a {
+			if !equal(a[i], bs[i]) {
+				return false
+			}
+		}
+		return true
+
+	case map[string]interface{}:
+		bm, ok := b.(map[string]interface{})
+		if !ok || len(a) != len(bm) {
+			return false
+		}
+		for key, val := range a {
+			bval, ok := bm[key]
+			if !ok || !equal(val, bval) {
+				return false
+			}
+		}
+		return true
+	}
+	return a == b
+}
+
+func toRat(num json.Number) *big.Rat {
+	r, ok := new(big.Rat).SetString(string(num))
+	if !ok {
+		return nil
+	}
+	return r
+}
+
+func ratKeyword(node map[string]interface{}, keyword string) (*big.Rat, string) {
+	if num, ok := node[keyword].(json.Number); ok {
+		if r := toRat(num); r != nil {
+			return r, string(num)
+		}
+	}
+	return nil, ""
+}
+
+func intKeyword(node map[string]interface{}, keyword string) (int, bool) {
+	if num, ok := node[keyword].(json.Number); ok {
+		if n, err := num.Int64(); err == nil {
+			return int(n), true
+		}
+	}
+	return 0, false
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// toString returns the JSON representation of "value", truncated if
+// too long.
+func toString(value interface{}) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(value); err != nil {
+		return fmt.Sprint(value)
+	}
+
+	b := bytes.TrimRight(buf.Bytes(), "\n")
+	if len(b) > 64 {
+		return string(b[:61]) + "..."
+	}
+	return string(b)
+}
+
+var uuidRe = regexp.MustCompile(
+	`^[[:xdigit:]]{8}-[[:xdigit:]]{4}-[[:xdigit:]]{4}-[[:xdigit:]]{4}-[[:xdigit:]]{12}$`)
+
+// checkFormat returns false if "str" does not respect "format". Only
+// date-time, date, time, email, ipv4, ipv6, uri and uuid formats are
+// checked, others are always respected.
+func checkFormat(format, str string) bool {
+	var err error
+	switch format {
+	case "date-time":
+		_, err = time.Parse(time.RFC3339, str)
+	case "date":
+		_, err = time.Parse("2006-01-02", str)
+	case "time":
+		_, err = time.Parse("15:04:05Z07:00", str)
+	case "email":
+		var addr *mail.Address
+		addr, err = mail.ParseAddress(str)
+		return err == nil && addr.Address == str
+	case "ipv4":
+		ip := net.ParseIP(str)
+		return ip != nil && ip.To4() != nil && !strings.Contains(str, ":")
+	case "ipv6":
+		return net.ParseIP(str) != nil && strings.Contains(str, ":")
+	case "uri":
+		var u *url.URL
+		u, err = url.Parse(str)
+		return err == nil && u.IsAbs()
+	case "uuid":
+		return uuidRe.MatchString(str)
+	}
+	return err == nil
+}
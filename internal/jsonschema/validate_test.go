@@ -0,0 +1,394 @@
+// Copyright (c) 2019, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package jsonschema_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/internal/jsonschema"
+	"github.com/maxatome/go-testdeep/internal/test"
+)
+
+// violations returns the violations of JSON "value" against JSON
+// "schema", each formatted as "PATH KEYWORD: MESSAGE".
+func violations(t *testing.T, schema, value string, opts jsonschema.Options) []string {
+	t.Helper()
+
+	s, err := jsonschema.Parse([]byte(schema), opts)
+	if err != nil {
+		t.Fatalf("Parse(%s) failed: %s", schema, err)
+	}
+
+	v, err := jsonschema.Decode([]byte(value))
+	if err != nil {
+		t.Fatalf("Decode(%s) failed: %s", value, err)
+	}
+
+	var ret []string
+	for _, violation := range s.Validate(v) {
+		ret = append(ret, fmt.Sprintf("%s %s: %s",
+			violation.Path, violation.Keyword, violation.Message))
+	}
+	return ret
+}
+
+func TestValidate(t *testing.T) {
+	for i, tc := range []struct {
+		schema   string
+		value    string
+		expected []string
+	}{
+		// Boolean schemas
+		{schema: `true`, value: `12`},
+		{schema: `false`, value: `12`, expected: []string{" false: no value allowed"}},
+		{schema: `{}`, value: `{"a":[1]}`},
+
+		// type
+		{schema: `{"type":"integer"}`, value: `12`},
+		{schema: `{"type":"integer"}`, value: `12.0`},
+		{schema: `{"type":"number"}`, value: `12`},
+		{
+			schema:   `{"type":"integer"}`,
+			value:    `12.5`,
+			expected: []string{" type: expected integer, got number"},
+		},
+		{
+			schema:   `{"type":["string","null"]}`,
+			value:    `true`,
+			expected: []string{" type: expected string or null, got boolean"},
+		},
+		{schema: `{"type":["string","null"]}`, value: `null`},
+
+		// enum & const
+		{schema: `{"enum":[1,"a",{"b":[2]}]}`, value: `1.0`},
+		{schema: `{"enum":[1,"a",{"b":[2]}]}`, value: `{"b":[2]}`},
+		{
+			schema:   `{"enum":[1,"a"]}`,
+			value:    `"b"`,
+			expected: []string{` enum: expected one of [1,"a"], got "b"`},
+		},
+		{schema: `{"const":null}`, value: `null`},
+		{
+			schema:   `{"const":[1]}`,
+			value:    `[1,2]`,
+			expected: []string{" const: expected [1], got [1,2]"},
+		},
+
+		// numbers
+		{schema: `{"multipleOf":0.1}`, value: `1.3`},
+		{
+			schema:   `{"multipleOf":3}`,
+			value:    `7`,
+			expected: []string{" multipleOf: expected multiple of 3, got 7"},
+		},
+		{schema: `{"minimum":1,"maximum":3}`, value: `3`},
+		{
+			schema:   `{"minimum":1,"maximum":3}`,
+			value:    `4`,
+			expected: []string{" maximum: expected ≤ 3, got 4"},
+		},
+		{
+			schema:   `{"minimum":1,"maximum":3}`,
+			value:    `0`,
+			expected: []string{" minimum: expected ≥ 1, got 0"},
+		},
+		{
+			schema: `{"exclusiveMinimum":1,"exclusiveMaximum":3}`,
+			value:  `[1,3]`, // not a number, ignored
+		},
+		{
+			schema:   `{"exclusiveMinimum":1,"exclusiveMaximum":3}`,
+			value:    `3`,
+			expected: []string{" exclusiveMaximum: expected < 3, got 3"},
+		},
+		{
+			schema:   `{"exclusiveMinimum":1,"exclusiveMaximum":3}`,
+			value:    `1`,
+			expected: []string{" exclusiveMinimum: expected > 1, got 1"},
+		},
+		{ // OpenAPI 3.0 form
+			schema:   `{"maximum":3,"exclusiveMaximum":true}`,
+			value:    `3`,
+			expected: []string{" exclusiveMaximum: expected < 3, got 3"},
+		},
+		{ // OpenAPI 3.0 form
+			schema:   `{"minimum":1,"exclusiveMinimum":true}`,
+			value:    `1`,
+			expected: []string{" exclusiveMinimum: expected > 1, got 1"},
+		},
+		{schema: `{"format":"int32"}`, value: `2147483647`},
+		{
+			schema:   `{"format":"int32"}`,
+			value:    `2147483648`,
+			expected: []string{" format: expected int32 format, got 2147483648"},
+		},
+		{
+			schema:   `{"format":"int64"}`,
+			value:    `1.5`,
+			expected: []string{" format: expected int64 format, got 1.5"},
+		},
+
+		// strings
+		{schema: `{"minLength":2,"maxLength":3}`, value: `"été"`},
+		{
+			schema:   `{"minLength":2,"maxLength":3}`,
+			value:    `"a"`,
+			expected: []string{" minLength: expected at least 2 characters, got 1"},
+		},
+		{
+			schema:   `{"minLength":2,"maxLength":3}`,
+			value:    `"abcd"`,
+			expected: []string{" maxLength: expected at most 3 characters, got 4"},
+		},
+		{schema: `{"pattern":"^a+$"}`, value: `"aaa"`},
+		{
+			schema:   `{"pattern":"^a+$"}`,
+			value:    `"aab"`,
+			expected: []string{` pattern: expected to match "^a+$", got "aab"`},
+		},
+		{schema: `{"format":"date-time"}`, value: `"2019-11-22T12:34:56.789+01:00"`},
+		{schema: `{"format":"date"}`, value: `"2019-11-22"`},
+		{schema: `{"format":"time"}`, value: `"12:34:56Z"`},
+		{schema: `{"format":"email"}`, value: `"bob@example.com"`},
+		{schema: `{"format":"ipv4"}`, value: `"127.0.0.1"`},
+		{schema: `{"format":"ipv6"}`, value: `"::1"`},
+		{schema: `{"format":"uri"}`, value: `"http://example.com/a"`},
+		{schema: `{"format":"uuid"}`, value: `"01234567-89ab-cdef-0123-456789ABCDEF"`},
+		{schema: `{"format":"unknown"}`, value: `"whatever"`},
+		{
+			schema:   `{"format":"date"}`,
+			value:    `"2019-13-22"`,
+			expected: []string{` format: expected date format, got "2019-13-22"`},
+		},
+		{
+			schema:   `{"format":"email"}`,
+			value:    `"Bob <bob@example.com>"`,
+			expected: []string{` format: expected email format, got "Bob <bob@example.com>"`},
+		},
+		{
+			schema:   `{"format":"ipv4"}`,
+			value:    `"::1"`,
+			expected: []string{` format: expected ipv4 format, got "::1"`},
+		},
+		{
+			schema:   `{"format":"uri"}`,
+			value:    `"/a"`,
+			expected: []string{` format: expected uri format, got "/a"`},
+		},
+
+		// arrays
+		{
+			schema: `{"items":{"type":"integer"}}`,
+			value:  `[1,"a",2,true]`,
+			expected: []string{
+				"/1 type: expected integer, got string",
+				"/3 type: expected integer, got boolean",
+			},
+		},
+		{
+			schema:   `{"prefixItems":[{"type":"string"}],"items":{"type":"integer"}}`,
+			value:    `["a",1,"b"]`,
+			expected: []string{"/2 type: expected integer, got string"},
+		},
+		{
+			schema:   `{"prefixItems":[{"type":"string"}],"items":false}`,
+			value:    `["a",1]`,
+			expected: []string{"/1 false: no value allowed"},
+		},
+		{ // draft 7 / OpenAPI 3.0 form
+			schema:   `{"items":[{"type":"string"}],"additionalItems":false}`,
+			value:    `[1,2]`,
+			expected: []string{"/0 type: expected string, got integer", "/1 false: no value allowed"},
+		},
+		{
+			schema:   `{"minItems":2,"maxItems":3}`,
+			value:    `[1]`,
+			expected: []string{" minItems: expected at least 2 items, got 1"},
+		},
+		{
+			schema:   `{"minItems":2,"maxItems":3}`,
+			value:    `[1,2,3,4]`,
+			expected: []string{" maxItems: expected at most 3 items, got 4"},
+		},
+		{schema: `{"uniqueItems":true}`, value: `[1,"1",[1]]`},
+		{
+			schema:   `{"uniqueItems":true}`,
+			value:    `[{"a":1},2,{"a":1.0}]`,
+			expected: []string{" uniqueItems: items #0 and #2 are equal"},
+		},
+		{schema: `{"contains":{"type":"string"}}`, value: `[1,"a"]`},
+		{
+			schema:   `{"contains":{"type":"string"}}`,
+			value:    `[1,2]`,
+			expected: []string{" contains: expected at least 1 matching items, got 0"},
+		},
+		{
+			schema: `{"contains":{"type":"string"},"minContains":2,"maxContains":2}`,
+			value:  `["a","b","c"]`,
+			expected: []string{
+				" maxContains: expected at most 2 matching items, got 3",
+			},
+		},
+		{
+			schema: `{"contains":{"type":"string"},"minContains":2,"maxContains":2}`,
+			value:  `["a",1]`,
+			expected: []string{
+				" minContains: expected at least 2 matching items, got 1",
+			},
+		},
+
+		// objects
+		{
+			schema: `{"properties":{"a":{"type":"string"}},"required":["a","b"]}`,
+			value:  `{"a":1}`,
+			expected: []string{
+				` required: missing required property "b"`,
+				"/a type: expected string, got integer",
+			},
+		},
+		{
+			schema: `{
+  "properties":        {"a":{"type":"string"}},
+  "patternProperties": {"^x-":{"type":"integer"}},
+  "additionalProperties": false
+}`,
+			value: `{"a":"a","x-1":2,"x-2":"b","c/d~":3}`,
+			expected: []string{
+				"/c~1d~0 additionalProperties: property not allowed",
+				"/x-2 type: expected integer, got string",
+			},
+		},
+		{
+			schema:   `{"additionalProperties":{"type":"boolean"}}`,
+			value:    `{"a":true,"b":1}`,
+			expected: []string{"/b type: expected boolean, got integer"},
+		},
+		{
+			schema:   `{"propertyNames":{"maxLength":2}}`,
+			value:    `{"ab":1,"abc":2}`,
+			expected: []string{`/abc propertyNames: invalid property name "abc"`},
+		},
+		{
+			schema:   `{"minProperties":2,"maxProperties":3}`,
+			value:    `{"a":1}`,
+			expected: []string{" minProperties: expected at least 2 properties, got 1"},
+		},
+		{
+			schema:   `{"minProperties":2,"maxProperties":3}`,
+			value:    `{"a":1,"b":2,"c":3,"d":4}`,
+			expected: []string{" maxProperties: expected at most 3 properties, got 4"},
+		},
+		{
+			schema:   `{"dependentRequired":{"a":["b"]}}`,
+			value:    `{"a":1}`,
+			expected: []string{` dependentRequired: missing property "b", required when "a" is present`},
+		},
+		{schema: `{"dependentRequired":{"a":["b"]}}`, value: `{"c":1}`},
+		{
+			schema:   `{"dependentSchemas":{"a":{"required":["b"]}}}`,
+			value:    `{"a":1}`,
+			expected: []string{` required: missing required property "b"`},
+		},
+
+		// combinators
+		{
+			schema: `{"allOf":[{"type":"integer"},{"minimum":3}]}`,
+			value:  `2.5`,
+			expected: []string{
+				" type: expected integer, got number",
+				" minimum: expected ≥ 3, got 2.5",
+			},
+		},
+		{schema: `{"anyOf":[{"type":"integer"},{"minimum":3}]}`, value: `2`},
+		{
+			schema:   `{"anyOf":[{"type":"integer"},{"minimum":3}]}`,
+			value:    `2.5`,
+			expected: []string{" anyOf: expected to match at least one of 2 schemas, got none"},
+		},
+		{schema: `{"oneOf":[{"type":"integer"},{"minimum":3}]}`, value: `2`},
+		{
+			schema:   `{"oneOf":[{"type":"integer"},{"minimum":3}]}`,
+			value:    `3`,
+			expected: []string{" oneOf: expected to match exactly one of 2 schemas, got 2"},
+		},
+		{
+			schema:   `{"oneOf":[{"type":"integer"},{"minimum":3}]}`,
+			value:    `2.5`,
+			expected: []string{" oneOf: expected to match exactly one of 2 schemas, got none"},
+		},
+		{
+			schema:   `{"not":{"type":"string"}}`,
+			value:    `"a"`,
+			expected: []string{" not: expected not to match schema"},
+		},
+		{
+			schema:   `{"if":{"type":"string"},"then":{"minLength":2},"else":{"minimum":0}}`,
+			value:    `"a"`,
+			expected: []string{" minLength: expected at least 2 characters, got 1"},
+		},
+		{
+			schema:   `{"if":{"type":"string"},"then":{"minLength":2},"else":{"minimum":0}}`,
+			value:    `-1`,
+			expected: []string{" minimum: expected ≥ 0, got -1"},
+		},
+
+		// $ref
+		{
+			schema: `{
+  "$defs": {
+    "pos":  {"type": "integer", "minimum": 0},
+    "list": {"$anchor": "list", "type": "array", "items": {"$ref": "#/$defs/pos"}}
+  },
+  "properties": {
+    "a": {"$ref": "#list"},
+    "b": {"$ref": "#/$defs/list", "maxItems": 1}
+  }
+}`,
+			value: `{"a":[1,-2],"b":[3,4]}`,
+			expected: []string{
+				"/a/1 minimum: expected ≥ 0, got -2",
+				"/b maxItems: expected at most 1 items, got 2",
+			},
+		},
+		{ // recursive schema
+			schema: `{
+  "type": "object",
+  "properties": {
+    "name":     {"type": "string"},
+    "children": {"type": "array", "items": {"$ref": "#"}}
+  }
+}`,
+			value:    `{"name":"a","children":[{"name":"b","children":[{"name":3}]}]}`,
+			expected: []string{"/children/0/children/0/name type: expected string, got integer"},
+		},
+		{
+			schema:   `{"$ref":"#"}`,
+			value:    `1`,
+			expected: []string{" $ref: too many nested references"},
+		},
+	} {
+		got := violations(t, tc.schema, tc.value, jsonschema.Options{})
+		test.EqualStr(t, strings.Join(got, "\n"), strings.Join(tc.expected, "\n"),
+			"#%d: %s against %s", i, tc.value, tc.schema)
+	}
+}
+
+func TestValidateNullable(t *testing.T) {
+	const schema = `{"type":"integer","nullable":true}`
+
+	test.EqualStr(t,
+		strings.Join(violations(t, schema, `null`, jsonschema.Options{}), "\n"),
+		" type: expected integer, got null")
+
+	test.EqualInt(t,
+		len(violations(t, schema, `null`, jsonschema.Options{Nullable: true})), 0)
+	test.EqualStr(t,
+		strings.Join(violations(t, schema, `"a"`, jsonschema.Options{Nullable: true}), "\n"),
+		" type: expected integer, got string")
+}
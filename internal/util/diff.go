@@ -0,0 +1,83 @@
+// Copyright (c) 2019, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package util
+
+import (
+	"bytes"
+	"strings"
+)
+
+// maxDiffCells limits the memory used by DiffLines.
+const maxDiffCells = 4 * 1024 * 1024
+
+// DiffLines returns a line-by-line diff between "expected" and
+// "got". Each line is prefixed by "-" if only in "expected", by "+"
+// if only in "got" or by " " if common to both. Returns false if
+// texts are too big to be compared.
+func DiffLines(expected, got string) (string, bool) {
+	exp := strings.SplitAfter(expected, "\n")
+	gt := strings.SplitAfter(got, "\n")
+
+	if len(exp)*len(gt) > maxDiffCells {
+		return "", false
+	}
+
+	// lcs[i][j] = length of the longest common subsequence of exp[i:]
+	// and gt[j:]
+	lcs := make([][]int, len(exp)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(gt)+1)
+	}
+	for i := len(exp) - 1; i >= 0; i-- {
+		for j := len(gt) - 1; j >= 0; j-- {
+			switch {
+			case exp[i] == gt[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	writeLine := func(prefix byte, line string) {
+		if line == "" {
+			return
+		}
+		buf.WriteByte(prefix)
+		buf.WriteString(line)
+		if !strings.HasSuffix(line, "\n") {
+			buf.WriteString("\n\\ No newline at end\n")
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(exp) && j < len(gt) {
+		switch {
+		case exp[i] == gt[j]:
+			writeLine(' ', exp[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			writeLine('-', exp[i])
+			i++
+		default:
+			writeLine('+', gt[j])
+			j++
+		}
+	}
+	for ; i < len(exp); i++ {
+		writeLine('-', exp[i])
+	}
+	for ; j < len(gt); j++ {
+		writeLine('+', gt[j])
+	}
+
+	return strings.TrimSuffix(buf.String(), "\n"), true
+}
@@ -0,0 +1,37 @@
+// Copyright (c) 2019, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package util_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/internal/test"
+	"github.com/maxatome/go-testdeep/internal/util"
+)
+
+func TestDiffLines(t *testing.T) {
+	for _, curTest := range []struct {
+		expected, got, diff string
+	}{
+		{expected: "", got: "", diff: ""},
+		{expected: "a\nb\n", got: "a\nb\n", diff: " a\n b"},
+		{expected: "a\nb\nc\n", got: "a\nc\n", diff: " a\n-b\n c"},
+		{expected: "a\nc\n", got: "a\nb\nc\n", diff: " a\n+b\n c"},
+		{expected: "a\nb\n", got: "a\nB\n", diff: " a\n-b\n+B"},
+		{expected: "a\nb", got: "a\nb\n", diff: " a\n-b\n\\ No newline at end\n+b"},
+	} {
+		diff, ok := util.DiffLines(curTest.expected, curTest.got)
+		if test.IsTrue(t, ok) {
+			test.EqualStr(t, diff, curTest.diff)
+		}
+	}
+
+	big := strings.Repeat("x\n", 3000)
+	_, ok := util.DiffLines(big, big)
+	test.IsFalse(t, ok)
+}
@@ -0,0 +1,25 @@
+// Copyright (c) 2019, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+//go:build go1.21
+// +build go1.21
+
+package testdeep
+
+import (
+	"runtime"
+)
+
+// isPanicNil returns true if "panicParam" is the value recovered
+// after a panic(nil) call. Since go1.21, panic(nil) is turned into a
+// *runtime.PanicNilError panic, except if GODEBUG=panicnil=1.
+func isPanicNil(panicParam interface{}) bool {
+	if panicParam == nil {
+		return true
+	}
+	_, ok := panicParam.(*runtime.PanicNilError)
+	return ok
+}
@@ -0,0 +1,16 @@
+// Copyright (c) 2019, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+//go:build !go1.21
+// +build !go1.21
+
+package testdeep
+
+// isPanicNil returns true if "panicParam" is the value recovered
+// after a panic(nil) call.
+func isPanicNil(panicParam interface{}) bool {
+	return panicParam == nil
+}
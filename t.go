@@ -186,6 +186,40 @@ func (t *T) ContainsKey(got interface{}, expectedValue interface{}, args ...inte
 	return t.Cmp(got, ContainsKey(expectedValue), args...)
 }
 
+// ContainsSeq is a shortcut for:
+//
+//   t.Cmp(got, ContainsSeq(expectedItems...), args...)
+//
+// See https://godoc.org/github.com/maxatome/go-testdeep#ContainsSeq for details.
+//
+// Returns true if the test is OK, false if it fails.
+//
+// "args..." are optional and allow to name the test. This name is
+// logged as is in case of failure. If len(args) > 1 and the first
+// item of args is a string and contains a '%' rune then fmt.Fprintf
+// is used to compose the name, else args are passed to fmt.Fprint.
+func (t *T) ContainsSeq(got interface{}, expectedItems []interface{}, args ...interface{}) bool {
+	t.Helper()
+	return t.Cmp(got, ContainsSeq(expectedItems...), args...)
+}
+
+// Count is a shortcut for:
+//
+//   t.Cmp(got, Count(filter, expectedCount), args...)
+//
+// See https://godoc.org/github.com/maxatome/go-testdeep#Count for details.
+//
+// Returns true if the test is OK, false if it fails.
+//
+// "args..." are optional and allow to name the test. This name is
+// logged as is in case of failure. If len(args) > 1 and the first
+// item of args is a string and contains a '%' rune then fmt.Fprintf
+// is used to compose the name, else args are passed to fmt.Fprint.
+func (t *T) Count(got interface{}, filter interface{}, expectedCount interface{}, args ...interface{}) bool {
+	t.Helper()
+	return t.Cmp(got, Count(filter, expectedCount), args...)
+}
+
 // Empty is a shortcut for:
 //
 //   t.Cmp(got, Empty(), args...)
@@ -203,6 +237,40 @@ func (t *T) Empty(got interface{}, args ...interface{}) bool {
 	return t.Cmp(got, Empty(), args...)
 }
 
+// First is a shortcut for:
+//
+//   t.Cmp(got, First(filter, expectedValue), args...)
+//
+// See https://godoc.org/github.com/maxatome/go-testdeep#First for details.
+//
+// Returns true if the test is OK, false if it fails.
+//
+// "args..." are optional and allow to name the test. This name is
+// logged as is in case of failure. If len(args) > 1 and the first
+// item of args is a string and contains a '%' rune then fmt.Fprintf
+// is used to compose the name, else args are passed to fmt.Fprint.
+func (t *T) First(got interface{}, filter interface{}, expectedValue interface{}, args ...interface{}) bool {
+	t.Helper()
+	return t.Cmp(got, First(filter, expectedValue), args...)
+}
+
+// Grep is a shortcut for:
+//
+//   t.Cmp(got, Grep(filter, expectedValue), args...)
+//
+// See https://godoc.org/github.com/maxatome/go-testdeep#Grep for details.
+//
+// Returns true if the test is OK, false if it fails.
+//
+// "args..." are optional and allow to name the test. This name is
+// logged as is in case of failure. If len(args) > 1 and the first
+// item of args is a string and contains a '%' rune then fmt.Fprintf
+// is used to compose the name, else args are passed to fmt.Fprint.
+func (t *T) Grep(got interface{}, filter interface{}, expectedValue interface{}, args ...interface{}) bool {
+	t.Helper()
+	return t.Cmp(got, Grep(filter, expectedValue), args...)
+}
+
 // Gt is a shortcut for:
 //
 //   t.Cmp(got, Gt(val), args...)
@@ -288,6 +356,23 @@ func (t *T) Isa(got interface{}, model interface{}, args ...interface{}) bool {
 	return t.Cmp(got, Isa(model), args...)
 }
 
+// JSONSchema is a shortcut for:
+//
+//   t.Cmp(got, JSONSchema(schema), args...)
+//
+// See https://godoc.org/github.com/maxatome/go-testdeep#JSONSchema for details.
+//
+// Returns true if the test is OK, false if it fails.
+//
+// "args..." are optional and allow to name the test. This name is
+// logged as is in case of failure. If len(args) > 1 and the first
+// item of args is a string and contains a '%' rune then fmt.Fprintf
+// is used to compose the name, else args are passed to fmt.Fprint.
+func (t *T) JSONSchema(got interface{}, schema interface{}, args ...interface{}) bool {
+	t.Helper()
+	return t.Cmp(got, JSONSchema(schema), args...)
+}
+
 // Keys is a shortcut for:
 //
 //   t.Cmp(got, Keys(val), args...)
@@ -305,6 +390,23 @@ func (t *T) Keys(got interface{}, val interface{}, args ...interface{}) bool {
 	return t.Cmp(got, Keys(val), args...)
 }
 
+// Last is a shortcut for:
+//
+//   t.Cmp(got, Last(filter, expectedValue), args...)
+//
+// See https://godoc.org/github.com/maxatome/go-testdeep#Last for details.
+//
+// Returns true if the test is OK, false if it fails.
+//
+// "args..." are optional and allow to name the test. This name is
+// logged as is in case of failure. If len(args) > 1 and the first
+// item of args is a string and contains a '%' rune then fmt.Fprintf
+// is used to compose the name, else args are passed to fmt.Fprint.
+func (t *T) Last(got interface{}, filter interface{}, expectedValue interface{}, args ...interface{}) bool {
+	t.Helper()
+	return t.Cmp(got, Last(filter, expectedValue), args...)
+}
+
 // Len is a shortcut for:
 //
 //   t.Cmp(got, Len(val), args...)
@@ -322,6 +424,23 @@ func (t *T) Len(got interface{}, val interface{}, args ...interface{}) bool {
 	return t.Cmp(got, Len(val), args...)
 }
 
+// Lines is a shortcut for:
+//
+//   t.Cmp(got, Lines(val), args...)
+//
+// See https://godoc.org/github.com/maxatome/go-testdeep#Lines for details.
+//
+// Returns true if the test is OK, false if it fails.
+//
+// "args..." are optional and allow to name the test. This name is
+// logged as is in case of failure. If len(args) > 1 and the first
+// item of args is a string and contains a '%' rune then fmt.Fprintf
+// is used to compose the name, else args are passed to fmt.Fprint.
+func (t *T) Lines(got interface{}, val interface{}, args ...interface{}) bool {
+	t.Helper()
+	return t.Cmp(got, Lines(val), args...)
+}
+
 // Lt is a shortcut for:
 //
 //   t.Cmp(got, Lt(val), args...)
@@ -390,6 +509,40 @@ func (t *T) MapEach(got interface{}, expectedValue interface{}, args ...interfac
 	return t.Cmp(got, MapEach(expectedValue), args...)
 }
 
+// Max is a shortcut for:
+//
+//   t.Cmp(got, Max(expectedValue, fieldsPath...), args...)
+//
+// See https://godoc.org/github.com/maxatome/go-testdeep#Max for details.
+//
+// Returns true if the test is OK, false if it fails.
+//
+// "args..." are optional and allow to name the test. This name is
+// logged as is in case of failure. If len(args) > 1 and the first
+// item of args is a string and contains a '%' rune then fmt.Fprintf
+// is used to compose the name, else args are passed to fmt.Fprint.
+func (t *T) Max(got interface{}, expectedValue interface{}, fieldsPath []string, args ...interface{}) bool {
+	t.Helper()
+	return t.Cmp(got, Max(expectedValue, fieldsPath...), args...)
+}
+
+// Min is a shortcut for:
+//
+//   t.Cmp(got, Min(expectedValue, fieldsPath...), args...)
+//
+// See https://godoc.org/github.com/maxatome/go-testdeep#Min for details.
+//
+// Returns true if the test is OK, false if it fails.
+//
+// "args..." are optional and allow to name the test. This name is
+// logged as is in case of failure. If len(args) > 1 and the first
+// item of args is a string and contains a '%' rune then fmt.Fprintf
+// is used to compose the name, else args are passed to fmt.Fprint.
+func (t *T) Min(got interface{}, expectedValue interface{}, fieldsPath []string, args ...interface{}) bool {
+	t.Helper()
+	return t.Cmp(got, Min(expectedValue, fieldsPath...), args...)
+}
+
 // N is a shortcut for:
 //
 //   t.Cmp(got, N(num, tolerance), args...)
@@ -636,6 +789,23 @@ func (t *T) ReAll(got interface{}, reg interface{}, capture interface{}, args ..
 	return t.Cmp(got, ReAll(reg, capture), args...)
 }
 
+// Reduce is a shortcut for:
+//
+//   t.Cmp(got, Reduce(fn, init, expectedValue), args...)
+//
+// See https://godoc.org/github.com/maxatome/go-testdeep#Reduce for details.
+//
+// Returns true if the test is OK, false if it fails.
+//
+// "args..." are optional and allow to name the test. This name is
+// logged as is in case of failure. If len(args) > 1 and the first
+// item of args is a string and contains a '%' rune then fmt.Fprintf
+// is used to compose the name, else args are passed to fmt.Fprint.
+func (t *T) Reduce(got interface{}, fn interface{}, init interface{}, expectedValue interface{}, args ...interface{}) bool {
+	t.Helper()
+	return t.Cmp(got, Reduce(fn, init, expectedValue), args...)
+}
+
 // Set is a shortcut for:
 //
 //   t.Cmp(got, Set(expectedItems...), args...)
@@ -704,6 +874,40 @@ func (t *T) Smuggle(got interface{}, fn interface{}, expectedValue interface{},
 	return t.Cmp(got, Smuggle(fn, expectedValue), args...)
 }
 
+// Sort is a shortcut for:
+//
+//   t.Cmp(got, Sort(keys, expectedValue), args...)
+//
+// See https://godoc.org/github.com/maxatome/go-testdeep#Sort for details.
+//
+// Returns true if the test is OK, false if it fails.
+//
+// "args..." are optional and allow to name the test. This name is
+// logged as is in case of failure. If len(args) > 1 and the first
+// item of args is a string and contains a '%' rune then fmt.Fprintf
+// is used to compose the name, else args are passed to fmt.Fprint.
+func (t *T) Sort(got interface{}, keys interface{}, expectedValue interface{}, args ...interface{}) bool {
+	t.Helper()
+	return t.Cmp(got, Sort(keys, expectedValue), args...)
+}
+
+// Sorted is a shortcut for:
+//
+//   t.Cmp(got, Sorted(keys...), args...)
+//
+// See https://godoc.org/github.com/maxatome/go-testdeep#Sorted for details.
+//
+// Returns true if the test is OK, false if it fails.
+//
+// "args..." are optional and allow to name the test. This name is
+// logged as is in case of failure. If len(args) > 1 and the first
+// item of args is a string and contains a '%' rune then fmt.Fprintf
+// is used to compose the name, else args are passed to fmt.Fprint.
+func (t *T) Sorted(got interface{}, keys []string, args ...interface{}) bool {
+	t.Helper()
+	return t.Cmp(got, Sorted(keys...), args...)
+}
+
 // String is a shortcut for:
 //
 //   t.Cmp(got, String(expected), args...)
@@ -789,6 +993,40 @@ func (t *T) SubSetOf(got interface{}, expectedItems []interface{}, args ...inter
 	return t.Cmp(got, SubSetOf(expectedItems...), args...)
 }
 
+// Subsequence is a shortcut for:
+//
+//   t.Cmp(got, Subsequence(expectedItems...), args...)
+//
+// See https://godoc.org/github.com/maxatome/go-testdeep#Subsequence for details.
+//
+// Returns true if the test is OK, false if it fails.
+//
+// "args..." are optional and allow to name the test. This name is
+// logged as is in case of failure. If len(args) > 1 and the first
+// item of args is a string and contains a '%' rune then fmt.Fprintf
+// is used to compose the name, else args are passed to fmt.Fprint.
+func (t *T) Subsequence(got interface{}, expectedItems []interface{}, args ...interface{}) bool {
+	t.Helper()
+	return t.Cmp(got, Subsequence(expectedItems...), args...)
+}
+
+// Sum is a shortcut for:
+//
+//   t.Cmp(got, Sum(expectedValue, fieldsPath...), args...)
+//
+// See https://godoc.org/github.com/maxatome/go-testdeep#Sum for details.
+//
+// Returns true if the test is OK, false if it fails.
+//
+// "args..." are optional and allow to name the test. This name is
+// logged as is in case of failure. If len(args) > 1 and the first
+// item of args is a string and contains a '%' rune then fmt.Fprintf
+// is used to compose the name, else args are passed to fmt.Fprint.
+func (t *T) Sum(got interface{}, expectedValue interface{}, fieldsPath []string, args ...interface{}) bool {
+	t.Helper()
+	return t.Cmp(got, Sum(expectedValue, fieldsPath...), args...)
+}
+
 // SuperBagOf is a shortcut for:
 //
 //   t.Cmp(got, SuperBagOf(expectedItems...), args...)
@@ -840,6 +1078,23 @@ func (t *T) SuperSetOf(got interface{}, expectedItems []interface{}, args ...int
 	return t.Cmp(got, SuperSetOf(expectedItems...), args...)
 }
 
+// SuperSliceOf is a shortcut for:
+//
+//   t.Cmp(got, SuperSliceOf(model, expectedEntries), args...)
+//
+// See https://godoc.org/github.com/maxatome/go-testdeep#SuperSliceOf for details.
+//
+// Returns true if the test is OK, false if it fails.
+//
+// "args..." are optional and allow to name the test. This name is
+// logged as is in case of failure. If len(args) > 1 and the first
+// item of args is a string and contains a '%' rune then fmt.Fprintf
+// is used to compose the name, else args are passed to fmt.Fprint.
+func (t *T) SuperSliceOf(got interface{}, model interface{}, expectedEntries ArrayEntries, args ...interface{}) bool {
+	t.Helper()
+	return t.Cmp(got, SuperSliceOf(model, expectedEntries), args...)
+}
+
 // TruncTime is a shortcut for:
 //
 //   t.Cmp(got, TruncTime(expectedTime, trunc), args...)
@@ -861,6 +1116,23 @@ func (t *T) TruncTime(got interface{}, expectedTime interface{}, trunc time.Dura
 	return t.Cmp(got, TruncTime(expectedTime, trunc), args...)
 }
 
+// Unique is a shortcut for:
+//
+//   t.Cmp(got, Unique(keyFn...), args...)
+//
+// See https://godoc.org/github.com/maxatome/go-testdeep#Unique for details.
+//
+// Returns true if the test is OK, false if it fails.
+//
+// "args..." are optional and allow to name the test. This name is
+// logged as is in case of failure. If len(args) > 1 and the first
+// item of args is a string and contains a '%' rune then fmt.Fprintf
+// is used to compose the name, else args are passed to fmt.Fprint.
+func (t *T) Unique(got interface{}, keyFn []interface{}, args ...interface{}) bool {
+	t.Helper()
+	return t.Cmp(got, Unique(keyFn...), args...)
+}
+
 // Values is a shortcut for:
 //
 //   t.Cmp(got, Values(val), args...)
@@ -6,7 +6,10 @@
 
 package testdeep
 
-import "testing"
+import (
+	"log"
+	"testing"
+)
 
 // T is a type that encapsulates *testing.T (in fact TestingFT
 // interface which is implemented by *testing.T) allowing to easily
@@ -287,7 +290,8 @@ func (t *T) CmpNoError(got error, args ...interface{}) bool {
 // are fulfilled.
 //
 // Note that calling panic(nil) in "fn" body is detected as a panic
-// (in this case "expectedPanic" has to be nil.)
+// (in this case "expectedPanic" has to be nil.) Use CmpPanicInfo to
+// distinguish panic(nil) from other panics.
 //
 // "args..." are optional and allow to name the test. This name is
 // logged as is in case of failure. If len(args) > 1 and the first
@@ -298,6 +302,98 @@ func (t *T) CmpPanic(fn func(), expected interface{}, args ...interface{}) bool
 	return cmpPanic(newContextWithConfig(t.Config), t, fn, expected, args...)
 }
 
+// CmpPanicInfo calls "fn" and checks a panic() occurred. It then
+// compares the PanicInfo describing this panic against
+// "expectedInfo". It returns true only if both conditions are
+// fulfilled.
+//
+// See CmpPanicInfo function for details.
+//
+// "args..." are optional and allow to name the test. This name is
+// logged as is in case of failure. If len(args) > 1 and the first
+// item of args is a string and contains a '%' rune then fmt.Fprintf
+// is used to compose the name, else args are passed to fmt.Fprint.
+func (t *T) CmpPanicInfo(fn func(), expectedInfo interface{}, args ...interface{}) bool {
+	t.Helper()
+	return cmpPanicInfo(newContextWithConfig(t.Config), t, fn, expectedInfo, args...)
+}
+
+// CmpPanicInGoroutine calls "fn" and checks a panic() occurred in
+// "fn" or in one of the goroutines it started using "goFn", with the
+// "expectedPanic" parameter. It returns true only if both conditions
+// are fulfilled.
+//
+// See CmpPanicInGoroutine function for details.
+//
+// "args..." are optional and allow to name the test. This name is
+// logged as is in case of failure. If len(args) > 1 and the first
+// item of args is a string and contains a '%' rune then fmt.Fprintf
+// is used to compose the name, else args are passed to fmt.Fprint.
+func (t *T) CmpPanicInGoroutine(fn func(goFn func(func())), expectedPanic interface{},
+	args ...interface{}) bool {
+	t.Helper()
+	return cmpPanicInGoroutine(newContextWithConfig(t.Config), t, fn, expectedPanic, args...)
+}
+
+// CmpNoLeak calls "fn" and checks that all goroutines it started
+// are terminated when it returns.
+//
+// See CmpNoLeak function for details.
+//
+// "args..." are optional and allow to name the test. This name is
+// logged as is in case of failure. If len(args) > 1 and the first
+// item of args is a string and contains a '%' rune then fmt.Fprintf
+// is used to compose the name, else args are passed to fmt.Fprint.
+func (t *T) CmpNoLeak(fn func(), args ...interface{}) bool {
+	t.Helper()
+
+	snapshot := newGoroutinesSnapshot(DefaultLeakConfig)
+	fn()
+	return cmpNoLeak(newContextWithConfig(t.Config), t, snapshot, args...)
+}
+
+// NoGoroutineLeak records the running goroutines and returns a
+// function checking that the goroutines started in the meantime are
+// terminated. Typically used as:
+//
+//   func TestServer(tt *testing.T) {
+//     t := td.NewT(tt)
+//     defer t.NoGoroutineLeak()()
+//
+//     srv := StartServer()
+//     defer srv.Close()
+//     // ...
+//   }
+//
+// "config" is an optional argument and, if passed, must be
+// unique. It allows to set the grace period and the goroutines to
+// ignore (see LeakConfig). DefaultLeakConfig is used if it is
+// missing.
+//
+//   defer t.NoGoroutineLeak(td.LeakConfig{
+//     GracePeriod: 5 * time.Second,
+//     Ignore:      []string{`^created by github\.com/my/pool\.`},
+//   })()
+//
+// The returned function returns true if no goroutine leaked, false
+// otherwise. See CmpNoLeak function for details.
+func (t *T) NoGoroutineLeak(config ...LeakConfig) func() bool {
+	var snapshot goroutinesSnapshot
+	switch len(config) {
+	case 0:
+		snapshot = newGoroutinesSnapshot(DefaultLeakConfig)
+	case 1:
+		snapshot = newGoroutinesSnapshot(config[0])
+	default:
+		panic("usage: NoGoroutineLeak([LeakConfig])")
+	}
+
+	return func() bool {
+		t.Helper()
+		return cmpNoLeak(newContextWithConfig(t.Config), t, snapshot)
+	}
+}
+
 // CmpNotPanic calls "fn" and checks no panic() occurred. If a panic()
 // occurred false is returned then the panic() parameter and the stack
 // trace appear in the test report.
@@ -313,6 +409,55 @@ func (t *T) CmpNotPanic(fn func(), args ...interface{}) bool {
 	return cmpNotPanic(newContextWithConfig(t.Config), t, fn, args...)
 }
 
+// CmpDir checks the contents of "gotDir" directory against
+// "expected", which can be the path of a directory containing the
+// expected files or a map[string]interface{} whose keys are the
+// slash-separated paths of the expected files, relative to "gotDir".
+//
+// See CmpDir function for details.
+//
+// "args..." are optional and allow to name the test. This name is
+// logged as is in case of failure. If len(args) > 1 and the first
+// item of args is a string and contains a '%' rune then fmt.Fprintf
+// is used to compose the name, else args are passed to fmt.Fprint.
+func (t *T) CmpDir(gotDir string, expected interface{}, args ...interface{}) bool {
+	t.Helper()
+	return cmpDir(newContextWithConfig(t.Config), t, gotDir, expected, args...)
+}
+
+// CmpOutput calls "fn" and checks that all it wrote on os.Stdout
+// matches "expectedStdout" and all it wrote on os.Stderr matches
+// "expectedStderr". A nil expectation means the corresponding output
+// is not checked.
+//
+// See CmpOutput function for details.
+//
+// "args..." are optional and allow to name the test. This name is
+// logged as is in case of failure. If len(args) > 1 and the first
+// item of args is a string and contains a '%' rune then fmt.Fprintf
+// is used to compose the name, else args are passed to fmt.Fprint.
+func (t *T) CmpOutput(fn func(), expectedStdout, expectedStderr interface{},
+	args ...interface{}) bool {
+	t.Helper()
+	return cmpOutput(t.Config, t, fn, expectedStdout, expectedStderr, args...)
+}
+
+// CmpLog calls "fn" and checks that all "logger" logged in the
+// meantime matches "expected". If "logger" is nil, the standard
+// logger is used.
+//
+// See CmpLog function for details.
+//
+// "args..." are optional and allow to name the test. This name is
+// logged as is in case of failure. If len(args) > 1 and the first
+// item of args is a string and contains a '%' rune then fmt.Fprintf
+// is used to compose the name, else args are passed to fmt.Fprint.
+func (t *T) CmpLog(logger *log.Logger, fn func(), expected interface{},
+	args ...interface{}) bool {
+	t.Helper()
+	return cmpLog(t.Config, t, logger, fn, expected, args...)
+}
+
 // Run runs "f" as a subtest of t called "name". It runs "f" in a separate
 // goroutine and blocks until "f" returns or calls t.Parallel to become
 // a parallel test. Run reports whether "f" succeeded (or at least did
@@ -8,7 +8,12 @@ package testdeep
 
 import (
 	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 )
 
 func ExampleT_True() {
@@ -131,6 +136,162 @@ func ExampleT_CmpPanic() {
 	// checks a panic occurred: false
 }
 
+func ExampleT_CmpPanicInfo() {
+	t := NewT(&testing.T{})
+
+	ok := t.CmpPanicInfo(
+		func() { panic("I am panicking!") },
+		Struct(PanicInfo{Value: "I am panicking!"}, nil))
+	fmt.Println("checks panic() value:", ok)
+
+	// Can check panics raised by the runtime, and where they occurred
+	ok = t.CmpPanicInfo(
+		func() {
+			var m map[string]int
+			m["foo"] = 42
+		},
+		Struct(PanicInfo{Runtime: true}, StructFields{
+			"Value": Contains("nil map"),
+			"Stack": Smuggle(
+				func(stack []Frame) string { return stack[0].Func },
+				HasSuffix(".ExampleT_CmpPanicInfo.func2")),
+		}))
+	fmt.Println("checks runtime panic() and its location:", ok)
+
+	// Can distinguish panic(nil) from other panics
+	ok = t.CmpPanicInfo(
+		func() { panic(nil) },
+		Struct(PanicInfo{Nil: true}, nil))
+	fmt.Println("checks for panic(nil):", ok)
+
+	// Of course, do not panic = test failure
+	ok = t.CmpPanicInfo(func() {}, Ignore())
+	fmt.Println("checks a panic occurred:", ok)
+
+	// Output:
+	// checks panic() value: true
+	// checks runtime panic() and its location: true
+	// checks for panic(nil): true
+	// checks a panic occurred: false
+}
+
+func ExampleT_CmpPanicInGoroutine() {
+	t := NewT(&testing.T{})
+
+	ok := t.CmpPanicInGoroutine(
+		func(goFn func(func())) {
+			goFn(func() { panic("I am panicking!") })
+		},
+		"I am panicking!")
+	fmt.Println("checks panic() in a goroutine:", ok)
+
+	// Of course, no goroutine panicking = test failure
+	ok = t.CmpPanicInGoroutine(
+		func(goFn func(func())) {
+			goFn(func() {})
+		},
+		Ignore())
+	fmt.Println("checks a panic occurred:", ok)
+
+	// Output:
+	// checks panic() in a goroutine: true
+	// checks a panic occurred: false
+}
+
+func ExampleT_CmpNoLeak() {
+	t := NewT(&testing.T{})
+
+	ok := t.CmpNoLeak(func() {
+		done := make(chan struct{})
+		go func() { close(done) }()
+		<-done
+	})
+	fmt.Println("no goroutine leaked:", ok)
+
+	// Output:
+	// no goroutine leaked: true
+}
+
+func ExampleT_NoGoroutineLeak() {
+	t := NewT(&testing.T{})
+
+	stop := make(chan struct{})
+	defer close(stop)
+
+	checkLeaks := t.NoGoroutineLeak(LeakConfig{GracePeriod: 10 * time.Millisecond})
+	go func() { <-stop }() // leaks until the end of the example
+	fmt.Println("no goroutine leaked:", checkLeaks())
+
+	// Output:
+	// no goroutine leaked: false
+}
+
+func ExampleT_CmpDir() {
+	t := NewT(&testing.T{})
+
+	dir, err := ioutil.TempDir("", "testdeep")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir) // nolint: errcheck
+
+	// Generates a file
+	ioutil.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0644) // nolint: errcheck
+
+	ok := t.CmpDir(dir, map[string]interface{}{
+		"main.go": DirFile{
+			Mode:    os.FileMode(0644),
+			Content: "package main\n",
+		},
+	})
+	fmt.Println("checks main.go mode and content:", ok)
+
+	// Output:
+	// checks main.go mode and content: true
+}
+
+func ExampleT_CmpOutput() {
+	t := NewT(&testing.T{})
+
+	ok := t.CmpOutput(
+		func() {
+			fmt.Println("Hello!")
+			fmt.Fprintln(os.Stderr, "Beware!")
+		},
+		"Hello!\n", Contains("Beware"))
+	fmt.Println("checks stdout and stderr:", ok)
+
+	// Outputs can be checked line by line, nil expectation ignores output
+	ok = t.CmpOutput(
+		func() {
+			fmt.Println("2 errors")
+			fmt.Println("1 warning")
+		},
+		Lines(Bag("1 warning", Re(`^\d+ errors$`))), nil)
+	fmt.Println("checks stdout lines:", ok)
+
+	// Output:
+	// checks stdout and stderr: true
+	// checks stdout lines: true
+}
+
+func ExampleT_CmpLog() {
+	t := NewT(&testing.T{})
+
+	logger := log.New(os.Stderr, "app: ", 0)
+
+	ok := t.CmpLog(logger,
+		func() {
+			logger.Println("start")
+			logger.Println("end")
+		},
+		Lines([]string{"app: start", "app: end"}))
+	fmt.Println("checks logged lines:", ok)
+
+	// Output:
+	// checks logged lines: true
+}
+
 func ExampleT_CmpNotPanic() {
 	t := NewT(&testing.T{})
 
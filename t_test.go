@@ -542,6 +542,60 @@ func ExampleT_ContainsKey_nil() {
 	// map contains *byte nil key: false
 }
 
+func ExampleT_ContainsSeq() {
+	t := NewT(&testing.T{})
+
+	events := []string{"start", "login", "buy", "logout", "stop"}
+
+	ok := t.ContainsSeq(events, []interface{}{"login", "buy", "logout"})
+	fmt.Println("login, buy then logout in a row:", ok)
+
+	ok = t.ContainsSeq(events, []interface{}{"login", "logout"})
+	fmt.Println("login then logout in a row:", ok)
+
+	ok = t.ContainsSeq([]int{1, 15, 2, 18}, []interface{}{Gt(10), Lt(10)})
+	fmt.Println("a number greater than 10 followed by a lesser one:", ok)
+
+	ok = t.ContainsSeq("Hello World!", []interface{}{'W', 'o'})
+	fmt.Println("'W' followed by 'o':", ok)
+
+	// Output:
+	// login, buy then logout in a row: true
+	// login then logout in a row: false
+	// a number greater than 10 followed by a lesser one: true
+	// 'W' followed by 'o': true
+}
+
+func ExampleT_Count() {
+	t := NewT(&testing.T{})
+
+	got := []int{-3, -2, -1, 0, 1, 2, 3}
+
+	ok := t.Count(got, Gt(0), 3)
+	fmt.Println("3 positive numbers:", ok)
+
+	ok = t.Count(got, Between(-1, 1), Between(2, 5))
+	fmt.Println("between 2 and 5 numbers in [-1 .. 1]:", ok)
+
+	isEven := func(x int) bool { return x%2 == 0 }
+
+	ok = t.Count(got, isEven, 3)
+	fmt.Println("3 even numbers:", ok)
+
+	ok = t.Count("Hello World!", 'o', 2)
+	fmt.Println("2 'o' in string:", ok)
+
+	ok = t.Count(map[string]int{"a": 1, "b": 15, "c": 2}, Gt(10), 2)
+	fmt.Println("2 map values greater than 10:", ok)
+
+	// Output:
+	// 3 positive numbers: true
+	// between 2 and 5 numbers in [-1 .. 1]: true
+	// 3 even numbers: true
+	// 2 'o' in string: true
+	// 2 map values greater than 10: false
+}
+
 func ExampleT_Empty() {
 	t := NewT(&testing.T{})
 
@@ -625,6 +679,59 @@ func ExampleT_Empty_pointers() {
 	// false
 }
 
+func ExampleT_First() {
+	t := NewT(&testing.T{})
+
+	got := []int{-3, -2, -1, 0, 1, 2, 3}
+
+	ok := t.First(got, Gt(0), 1)
+	fmt.Println("first positive number is 1:", ok)
+
+	isEven := func(x int) bool { return x%2 == 0 }
+
+	ok = t.First(got, isEven, -2)
+	fmt.Println("first even number is -2:", ok)
+
+	ok = t.First(got, Gt(3), Gt(3))
+	fmt.Println("first number greater than 3 is found:", ok)
+
+	// Output:
+	// first positive number is 1: true
+	// first even number is -2: true
+	// first number greater than 3 is found: false
+}
+
+func ExampleT_Grep() {
+	t := NewT(&testing.T{})
+
+	type Order struct {
+		Status string
+		Amount int
+	}
+
+	got := []Order{
+		{Status: "pending", Amount: 5},
+		{Status: "paid", Amount: 12},
+		{Status: "paid", Amount: 18},
+	}
+
+	ok := t.Grep([]int{-3, -2, -1, 0, 1, 2, 3}, Gt(0), []int{1, 2, 3})
+	fmt.Println("positive numbers:", ok)
+
+	isPaid := func(o Order) bool { return o.Status == "paid" }
+
+	ok = t.Grep(got, isPaid, ArrayEach(Smuggle("Amount", Gt(10))))
+	fmt.Println("all paid orders amount is greater than 10:", ok)
+
+	ok = t.Grep(got, Smuggle("Status", "paid"), Len(2))
+	fmt.Println("there are 2 paid orders:", ok)
+
+	// Output:
+	// positive numbers: true
+	// all paid orders amount is greater than 10: true
+	// there are 2 paid orders: true
+}
+
 func ExampleT_Gt_int() {
 	t := NewT(&testing.T{})
 
@@ -832,6 +939,43 @@ func ExampleT_Isa_interface() {
 	// true
 }
 
+func ExampleT_JSONSchema() {
+	t := NewT(&testing.T{})
+
+	type Person struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+
+	schema := `{
+    "type": "object",
+    "properties": {
+      "name": {"type": "string", "minLength": 1},
+      "age":  {"type": "integer", "minimum": 0}
+    },
+    "required": ["name", "age"]
+  }`
+
+	got := Person{Name: "Bob", Age: 42}
+	ok := t.JSONSchema(got, schema)
+	fmt.Println("Bob respects the schema:", ok)
+
+	// Raw JSON documents are validated as is
+	doc := json.RawMessage(`{"name":"Alice","age":-1}`)
+	ok = t.JSONSchema(doc, schema)
+	fmt.Println("Alice respects the schema:", ok)
+
+	// As well as any value marshaled to JSON
+	brian := map[string]interface{}{"name": "Brian"}
+	ok = t.JSONSchema(brian, schema)
+	fmt.Println("Brian respects the schema:", ok)
+
+	// Output:
+	// Bob respects the schema: true
+	// Alice respects the schema: false
+	// Brian respects the schema: false
+}
+
 func ExampleT_Keys() {
 	t := NewT(&testing.T{})
 
@@ -860,6 +1004,28 @@ func ExampleT_Keys() {
 	// Each key is 3 bytes long: true
 }
 
+func ExampleT_Last() {
+	t := NewT(&testing.T{})
+
+	got := []int{-3, -2, -1, 0, 1, 2, 3}
+
+	ok := t.Last(got, Lt(0), -1)
+	fmt.Println("last negative number is -1:", ok)
+
+	isEven := func(x int) bool { return x%2 == 0 }
+
+	ok = t.Last(got, isEven, 2)
+	fmt.Println("last even number is 2:", ok)
+
+	ok = t.Last(got, Lt(-3), Lt(-3))
+	fmt.Println("last number lesser than -3 is found:", ok)
+
+	// Output:
+	// last negative number is -1: true
+	// last even number is 2: true
+	// last number lesser than -3 is found: false
+}
+
 func ExampleT_Len_slice() {
 	t := NewT(&testing.T{})
 
@@ -938,6 +1104,34 @@ func ExampleT_Len_operatorMap() {
 	// true
 }
 
+func ExampleT_Lines() {
+	t := NewT(&testing.T{})
+
+	got := "Once upon a time,\nthere was\na gopher.\n"
+
+	ok := t.Lines(got, []string{"Once upon a time,", "there was", "a gopher."})
+	fmt.Println("All lines are found in order:", ok)
+
+	// Unordered lines can be checked using Bag operator
+	ok = t.Lines(got, Bag("a gopher.", "there was", Re(`^Once`)))
+	fmt.Println("All lines are found, with the help of Bag operator:", ok)
+
+	// Count lines
+	ok = t.Lines(got, Len(3))
+	fmt.Println("There are 3 lines:", ok)
+
+	// Works with errors and fmt.Stringer too
+	err := errors.New("1st error\n2nd error")
+	ok = t.Lines(err, ArrayEach(HasSuffix(" error")))
+	fmt.Println("Each error line ends with \" error\":", ok)
+
+	// Output:
+	// All lines are found in order: true
+	// All lines are found, with the help of Bag operator: true
+	// There are 3 lines: true
+	// Each error line ends with " error": true
+}
+
 func ExampleT_Lt_int() {
 	t := NewT(&testing.T{})
 
@@ -1063,6 +1257,25 @@ func ExampleT_Map_typedMap() {
 	// true
 }
 
+func ExampleT_Map_operatorKeys() {
+	t := NewT(&testing.T{})
+
+	got := map[string]int{"id": 42, "tmp-a": 1, "tmp-b": 2}
+
+	ok := t.Map(got, map[string]int{"id": 42}, MapEntries{Re("^tmp-"): Between(1, 2)},
+		"checks map %v", got)
+	fmt.Println(ok)
+
+	ok = t.Cmp(got,
+		SuperMapOf(map[string]int{}, MapEntries{Re("^tmp-"): Lt(2)}),
+		"checks map %v", got)
+	fmt.Println(ok)
+
+	// Output:
+	// true
+	// false
+}
+
 func ExampleT_MapEach_map() {
 	t := NewT(&testing.T{})
 
@@ -1096,6 +1309,65 @@ func ExampleT_MapEach_typedMap() {
 	// true
 }
 
+func ExampleT_Max() {
+	t := NewT(&testing.T{})
+
+	type Order struct {
+		ID     int
+		Amount float64
+	}
+
+	got := []Order{
+		{ID: 1, Amount: 12.5},
+		{ID: 2, Amount: 99.9},
+		{ID: 3, Amount: 7.5},
+	}
+
+	ok := t.Max([]int{3, 8, 2}, 8, nil)
+	fmt.Println("max of ints is 8:", ok)
+
+	ok = t.Max(got, Lt(100.0), []string{"Amount"})
+	fmt.Println("no amount reaches 100:", ok)
+
+	ok = t.Cmp(map[string]time.Duration{"a": time.Second, "b": time.Minute},
+		Max(time.Minute))
+	fmt.Println("max duration is 1 minute:", ok)
+
+	// Output:
+	// max of ints is 8: true
+	// no amount reaches 100: true
+	// max duration is 1 minute: true
+}
+
+func ExampleT_Min() {
+	t := NewT(&testing.T{})
+
+	type Event struct {
+		Name string
+		Date time.Time
+	}
+
+	start := time.Date(2019, time.March, 1, 0, 0, 0, 0, time.UTC)
+	got := []Event{
+		{Name: "launch", Date: start.Add(2 * time.Hour)},
+		{Name: "landing", Date: start.Add(5 * time.Hour)},
+	}
+
+	ok := t.Min([]int{3, 8, 2}, 2, nil)
+	fmt.Println("min of ints is 2:", ok)
+
+	ok = t.Min(got, Gte(start), []string{"Date"})
+	fmt.Println("no event before start:", ok)
+
+	ok = t.Min([]string{"pear", "apple", "plum"}, "apple", nil)
+	fmt.Println("min string is apple:", ok)
+
+	// Output:
+	// min of ints is 2: true
+	// no event before start: true
+	// min string is apple: true
+}
+
 func ExampleT_N() {
 	t := NewT(&testing.T{})
 
@@ -1689,6 +1961,25 @@ func ExampleT_ReAll_compiledCaptureComplex() {
 	// false
 }
 
+func ExampleT_Reduce() {
+	t := NewT(&testing.T{})
+
+	ok := t.Reduce([]int{1, 2, 3, 4}, func(acc, n int) int { return acc * n }, 1, 24)
+	fmt.Println("product is 24:", ok)
+
+	ok = t.Reduce([]string{"a", "b", "c"}, func(acc, s string) string { return acc + s }, "", "abc")
+	fmt.Println("concatenation is abc:", ok)
+
+	// Map values are passed sorted by their keys
+	ok = t.Reduce(map[int]string{2: "b", 1: "a"}, func(acc []string, s string) []string { return append(acc, s) }, nil, []string{"a", "b"})
+	fmt.Println("values sorted by keys:", ok)
+
+	// Output:
+	// product is 24: true
+	// concatenation is abc: true
+	// values sorted by keys: true
+}
+
 func ExampleT_Set() {
 	t := NewT(&testing.T{})
 
@@ -1828,6 +2119,32 @@ func ExampleT_Slice_typedSlice() {
 	// true
 }
 
+func ExampleT_Slice_negativeIndexes() {
+	t := NewT(&testing.T{})
+
+	got := []int{42, 58, 26, 12}
+
+	ok := t.Slice(got, []int{42}, ArrayEntries{
+		1:  ArrayRange{From: 1, To: -2, Expected: Between(20, 60)},
+		-1: 12,
+	},
+		"checks slice %v", got)
+	fmt.Println(ok)
+
+	// Whatever the length of the slice is
+	got = []int{42, 12}
+	ok = t.Slice(got, []int{42}, ArrayEntries{
+		1:  ArrayRange{From: 1, To: -2, Expected: Between(20, 60)},
+		-1: 12,
+	},
+		"checks slice %v", got)
+	fmt.Println(ok)
+
+	// Output:
+	// true
+	// true
+}
+
 func ExampleT_Smuggle_convert() {
 	t := NewT(&testing.T{})
 
@@ -2036,6 +2353,72 @@ func ExampleT_Smuggle_field_path() {
 	// check Num using an other fields-path: true
 }
 
+func ExampleT_Sort() {
+	t := NewT(&testing.T{})
+
+	type Person struct {
+		Name string
+		Age  int
+	}
+
+	got := []Person{
+		{Name: "Bob", Age: 42},
+		{Name: "Alice", Age: 24},
+		{Name: "Brian", Age: 42},
+	}
+
+	ok := t.Sort([]int{3, 1, 2}, nil, []int{1, 2, 3})
+	fmt.Println("sorted ints:", ok)
+
+	ok = t.Sort(got, "Name", []Person{
+		{Name: "Alice", Age: 24},
+		{Name: "Bob", Age: 42},
+		{Name: "Brian", Age: 42},
+	})
+	fmt.Println("sorted by name:", ok)
+
+	ok = t.Sort(got, []string{"-Age", "-Name"}, Smuggle(func(persons []Person) string { return persons[0].Name }, "Brian"))
+	fmt.Println("oldest, then last by name, is Brian:", ok)
+
+	// Output:
+	// sorted ints: true
+	// sorted by name: true
+	// oldest, then last by name, is Brian: true
+}
+
+func ExampleT_Sorted() {
+	t := NewT(&testing.T{})
+
+	type Person struct {
+		Name string
+		Age  int
+	}
+
+	got := []Person{
+		{Name: "Bob", Age: 42},
+		{Name: "Brian", Age: 42},
+		{Name: "Alice", Age: 24},
+	}
+
+	ok := t.Sorted([]int{1, 2, 2, 5}, nil)
+	fmt.Println("ascending:", ok)
+
+	ok = t.Sorted([]int{5, 2, 2, 1}, []string{"-"})
+	fmt.Println("descending:", ok)
+
+	ok = t.Sorted(got, []string{"-Age", "Name"})
+	fmt.Println("older first, then by name:", ok)
+
+	ok = t.Sorted(got, []string{"Name"})
+	fmt.Println("by name:", ok)
+
+	// Output:
+	// ascending: true
+	// descending: true
+	// older first, then by name: true
+	// by name: false
+}
+
 func ExampleT_String() {
 	t := NewT(&testing.T{})
 
@@ -2129,6 +2512,48 @@ func ExampleT_Struct() {
 	// true
 }
 
+func ExampleT_Struct_patterns() {
+	t := NewT(&testing.T{})
+
+	type Person struct {
+		Firstname string
+		Lastname  string
+		Surname   string
+		CreatedAt time.Time
+		UpdatedAt time.Time
+	}
+
+	now := time.Now()
+	got := Person{
+		Firstname: "Maxime",
+		Lastname:  "Foo",
+		Surname:   "Max",
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	ok := t.Struct(got, Person{Firstname: "Maxime"}, StructFields{
+		"=*At":     Lte(time.Now()),
+		`~name\z`:  Re(`^[A-Z][a-z]+\z`),
+		"Lastname": "Foo",
+	},
+		"mix of explicit names and patterns")
+	fmt.Println(ok)
+
+	// Globs are applied before regexps: CreatedAt and UpdatedAt are
+	// not checked against the regexp pattern
+	ok = t.Struct(got, Person{}, StructFields{
+		"=*At": Ignore(),
+		"~.":   NotZero(),
+	},
+		"all fields are set")
+	fmt.Println(ok)
+
+	// Output:
+	// true
+	// true
+}
+
 func ExampleT_SubBagOf() {
 	t := NewT(&testing.T{})
 
@@ -2209,6 +2634,58 @@ func ExampleT_SubSetOf() {
 	// true
 }
 
+func ExampleT_Subsequence() {
+	t := NewT(&testing.T{})
+
+	events := []string{"start", "login", "buy", "logout", "stop"}
+
+	ok := t.Subsequence(events, []interface{}{"start", "buy", "stop"})
+	fmt.Println("start, buy then stop:", ok)
+
+	ok = t.Subsequence(events, []interface{}{"start", "stop", "buy"})
+	fmt.Println("start, stop then buy:", ok)
+
+	ok = t.Subsequence(events, []interface{}{HasPrefix("log"), "buy", HasPrefix("log")})
+	fmt.Println("buy between two log* events:", ok)
+
+	ok = t.Subsequence("Hello World!", []interface{}{'H', 'W', '!'})
+	fmt.Println("'H', 'W' then '!':", ok)
+
+	// Output:
+	// start, buy then stop: true
+	// start, stop then buy: false
+	// buy between two log* events: true
+	// 'H', 'W' then '!': true
+}
+
+func ExampleT_Sum() {
+	t := NewT(&testing.T{})
+
+	type Order struct {
+		ID     int
+		Amount float64
+	}
+
+	got := []Order{
+		{ID: 1, Amount: 12.5},
+		{ID: 2, Amount: 7.5},
+	}
+
+	ok := t.Sum([]int{1, 2, 3}, 6, nil)
+	fmt.Println("sum of ints is 6:", ok)
+
+	ok = t.Sum(got, Between(19.0, 21.0), []string{"Amount"})
+	fmt.Println("total amount is about 20:", ok)
+
+	ok = t.Cmp([]time.Duration{time.Second, time.Minute}, Sum(61*time.Second))
+	fmt.Println("total duration is 61s:", ok)
+
+	// Output:
+	// sum of ints is 6: true
+	// total amount is about 20: true
+	// total duration is 61s: true
+}
+
 func ExampleT_SuperBagOf() {
 	t := NewT(&testing.T{})
 
@@ -2279,6 +2756,31 @@ func ExampleT_SuperSetOf() {
 	// true
 }
 
+func ExampleT_SuperSliceOf() {
+	t := NewT(&testing.T{})
+
+	got := []int{42, 58, 26, 12}
+
+	ok := t.SuperSliceOf(got, []int{42}, ArrayEntries{-1: 12},
+		"checks first and last items of %v", got)
+	fmt.Println(ok)
+
+	ok = t.SuperSliceOf(got, []int{}, ArrayEntries{
+		1: ArrayRange{From: 1, To: 2, Expected: Between(20, 60)},
+	},
+		"checks 2nd and 3rd items of %v", got)
+	fmt.Println(ok)
+
+	ok = t.SuperSliceOf(got, []int{42, 0, 0, 0, 12}, nil,
+		"checks %v has at least 5 items", got)
+	fmt.Println(ok)
+
+	// Output:
+	// true
+	// true
+	// false
+}
+
 func ExampleT_TruncTime() {
 	t := NewT(&testing.T{})
 
@@ -2316,6 +2818,39 @@ func ExampleT_TruncTime() {
 	// true
 }
 
+func ExampleT_Unique() {
+	t := NewT(&testing.T{})
+
+	type User struct {
+		ID    int
+		Email string
+	}
+
+	users := []User{
+		{ID: 1, Email: "bob@example.com"},
+		{ID: 2, Email: "Alice@example.com"},
+		{ID: 3, Email: "alice@example.com"},
+	}
+
+	ok := t.Unique([]int{1, 2, 3}, nil)
+	fmt.Println("distinct numbers:", ok)
+
+	ok = t.Unique([]int{1, 2, 1}, nil)
+	fmt.Println("distinct numbers with duplicates:", ok)
+
+	ok = t.Unique(users, []interface{}{"ID"})
+	fmt.Println("distinct IDs:", ok)
+
+	ok = t.Unique(users, []interface{}{func(u User) int { return u.ID % 2 }})
+	fmt.Println("distinct ID parities:", ok)
+
+	// Output:
+	// distinct numbers: true
+	// distinct numbers with duplicates: false
+	// distinct IDs: true
+	// distinct ID parities: false
+}
+
 func ExampleT_Values() {
 	t := NewT(&testing.T{})
 
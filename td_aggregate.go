@@ -0,0 +1,541 @@
+// Copyright (c) 2019, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package testdeep
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/maxatome/go-testdeep/helpers/tdutil"
+	"github.com/maxatome/go-testdeep/internal/ctxerr"
+	"github.com/maxatome/go-testdeep/internal/dark"
+	"github.com/maxatome/go-testdeep/internal/types"
+	"github.com/maxatome/go-testdeep/internal/util"
+)
+
+// aggItem is an item of an aggregated collection.
+type aggItem struct {
+	ctx   ctxerr.Context
+	value reflect.Value
+}
+
+// aggItems returns the items of "got", an array, a slice or a map (in
+// this case values are returned sorted by their keys). If "got" is
+// none of them, it returns false and the error to return.
+func aggItems(ctx ctxerr.Context, got reflect.Value) ([]aggItem, bool, *ctxerr.Error) {
+	var items []aggItem
+
+	switch got.Kind() {
+	case reflect.Array, reflect.Slice:
+		items = make([]aggItem, got.Len())
+		for idx := range items {
+			items[idx] = aggItem{
+				ctx:   ctx.AddArrayIndex(idx),
+				value: got.Index(idx),
+			}
+		}
+
+	case reflect.Map:
+		items = make([]aggItem, 0, got.Len())
+		for _, k := range tdutil.MapSortedKeys(got) {
+			items = append(items, aggItem{
+				ctx:   ctx.AddMapKey(k),
+				value: got.MapIndex(k),
+			})
+		}
+
+	default:
+		if ctx.BooleanError {
+			return nil, false, ctxerr.BooleanError
+		}
+		var gotType types.RawString
+		if got.IsValid() {
+			gotType = types.RawString(got.Type().String())
+		} else {
+			gotType = "nil"
+		}
+		return nil, false, ctx.CollectError(&ctxerr.Error{
+			Message:  "bad type",
+			Got:      gotType,
+			Expected: types.RawString("Array, Slice or Map"),
+		})
+	}
+
+	for i, item := range items {
+		// Resolve only one interface{} dereference
+		if item.value.Kind() == reflect.Interface {
+			items[i].value = item.value.Elem()
+		}
+	}
+	return items, true, nil
+}
+
+// tdAggregateBase is the base of Sum, Min and Max operators.
+type tdAggregateBase struct {
+	tdSmugglerBase
+	name   string
+	path   string
+	pathFn func(interface{}) (smuggleValue, error)
+}
+
+func newAggregateBase(name string, expectedValue interface{}, fieldsPath []string) (a tdAggregateBase) {
+	a.tdSmugglerBase = newSmugglerBase(expectedValue, 5)
+	if !a.isTestDeeper {
+		a.expectedValue = reflect.ValueOf(expectedValue)
+	}
+	a.name = name
+
+	usage := name + "(TESTDEEP_OPERATOR|EXPECTED_VALUE[, FIELDS_PATH])"
+	switch len(fieldsPath) {
+	case 0:
+	case 1:
+		fn, err := buildStructFieldFn(fieldsPath[0])
+		if err != nil {
+			panic(usage + ": " + err.Error())
+		}
+		a.path = fieldsPath[0]
+		a.pathFn = fn
+	default:
+		panic("usage: " + usage)
+	}
+	return
+}
+
+// values returns the values to aggregate, applying the fields-path
+// if any.
+func (a *tdAggregateBase) values(ctx ctxerr.Context, got reflect.Value) ([]aggItem, bool, *ctxerr.Error) {
+	items, ok, err := aggItems(ctx, got)
+	if !ok || a.pathFn == nil {
+		return items, ok, err
+	}
+
+	for i, item := range items {
+		value, ok, err := a.fieldValue(item)
+		if !ok {
+			return nil, false, err
+		}
+		items[i].ctx = item.ctx.AddCustomLevel("." + a.path)
+		items[i].value = value
+	}
+	return items, true, nil
+}
+
+// fieldValue returns the value reached by the fields-path in
+// "item". If it cannot be reached, false and an error are returned.
+func (a *tdAggregateBase) fieldValue(item aggItem) (reflect.Value, bool, *ctxerr.Error) {
+	var (
+		smv smuggleValue
+		err error
+	)
+	if item.value.IsValid() {
+		smv, err = a.pathFn(dark.MustGetInterface(item.value))
+	} else {
+		err = errors.New("it is nil")
+	}
+	if err != nil {
+		return reflect.Value{}, false, a.error(item.ctx, &ctxerr.Error{
+			Message: "cannot get field",
+			Summary: ctxerr.NewSummary(err.Error()),
+		})
+	}
+
+	// Resolve only one interface{} dereference
+	if smv.Value.Kind() == reflect.Interface {
+		return smv.Value.Elem(), true, nil
+	}
+	return smv.Value, true, nil
+}
+
+// fieldType returns the type of the field reached by the fields-path
+// in items of type "typ", or nil if it cannot be statically
+// determined (for example when an interface is traversed).
+func (a *tdAggregateBase) fieldType(typ reflect.Type) reflect.Type {
+	for _, fieldName := range strings.Split(a.path, ".") {
+		for typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		if typ.Kind() != reflect.Struct {
+			return nil
+		}
+		field, ok := typ.FieldByName(fieldName)
+		if !ok {
+			return nil
+		}
+		typ = field.Type
+	}
+	return typ
+}
+
+func (a *tdAggregateBase) error(ctx ctxerr.Context, err *ctxerr.Error) *ctxerr.Error {
+	if ctx.BooleanError {
+		return ctxerr.BooleanError
+	}
+	return ctx.CollectError(err)
+}
+
+// badType reports that "item" cannot be aggregated.
+func (a *tdAggregateBase) badType(item aggItem, expected string) *ctxerr.Error {
+	var got types.RawString
+	if item.value.IsValid() {
+		got = types.RawString(item.value.Type().String())
+	} else {
+		got = "nil"
+	}
+	return a.error(item.ctx, &ctxerr.Error{
+		Message:  "cannot compute " + a.name,
+		Got:      got,
+		Expected: types.RawString(expected),
+	})
+}
+
+// checkSameType checks all items have the same type as the first
+// one. If not, false and the error to return are returned.
+func (a *tdAggregateBase) checkSameType(items []aggItem) (bool, *ctxerr.Error) {
+	typ := items[0].value.Type()
+	for _, item := range items[1:] {
+		if !item.value.IsValid() || item.value.Type() != typ {
+			return false, a.badType(item, typ.String())
+		}
+	}
+	return true, nil
+}
+
+func (a *tdAggregateBase) compare(ctx ctxerr.Context, got reflect.Value) *ctxerr.Error {
+	return deepValueEqual(ctx.AddFunctionCall(a.name), got, a.expectedValue)
+}
+
+func (a *tdAggregateBase) HandleInvalid() bool {
+	return true // Knows how to handle untyped nil values (aka. invalid values)
+}
+
+func (a *tdAggregateBase) String() string {
+	prefix := a.name
+	if a.path != "" {
+		prefix += fmt.Sprintf("(%q)", a.path)
+	}
+	if a.isTestDeeper {
+		return prefix + ": " + a.expectedValue.Interface().(TestDeep).String()
+	}
+	return prefix + "=" + util.ToString(a.expectedValue)
+}
+
+type tdSum struct {
+	tdAggregateBase
+}
+
+var _ TestDeep = &tdSum{}
+
+// Sum is a smuggler operator. It takes an array, a slice or a map
+// (only values are summed) of numbers, computes the sum of its items
+// and compares it to "expectedValue".
+//
+// If "fieldsPath" is passed, items must be structs (or pointers on
+// structs), and the values summed are the ones reached through this
+// fields-path, as in Smuggle operator.
+//
+// All summed values must have the same type, as the sum has this
+// type too. The sum of an empty array, slice or map is the zero
+// value of its items type (or of the field reached by "fieldsPath"
+// in its items type) if it is numeric, else int 0.
+//
+//   Cmp(t, []int{1, 2, 3}, Sum(6))                       // succeeds
+//   Cmp(t, []float64{1.5, 2.5}, Sum(Between(3.9, 4.1)))  // succeeds
+//   Cmp(t, orders, Sum(Gt(100.0), "Amount"))             // sum of orders amounts
+//   Cmp(t, []time.Duration{time.Second, time.Minute}, Sum(61*time.Second))
+func Sum(expectedValue interface{}, fieldsPath ...string) TestDeep {
+	return &tdSum{
+		tdAggregateBase: newAggregateBase("sum", expectedValue, fieldsPath),
+	}
+}
+
+func isSummable(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Uintptr, reflect.Float32, reflect.Float64,
+		reflect.Complex64, reflect.Complex128:
+		return true
+	}
+	return false
+}
+
+func (s *tdSum) Match(ctx ctxerr.Context, got reflect.Value) *ctxerr.Error {
+	items, ok, err := s.values(ctx, got)
+	if !ok {
+		return err
+	}
+
+	if len(items) == 0 {
+		typ := got.Type().Elem()
+		if s.pathFn != nil {
+			typ = s.fieldType(typ)
+		}
+		if typ != nil && isSummable(typ.Kind()) {
+			return s.compare(ctx, reflect.Zero(typ))
+		}
+		return s.compare(ctx, reflect.ValueOf(0))
+	}
+
+	if !items[0].value.IsValid() || !isSummable(items[0].value.Kind()) {
+		return s.badType(items[0], "number")
+	}
+	if ok, err := s.checkSameType(items); !ok {
+		return err
+	}
+
+	sum := reflect.New(items[0].value.Type()).Elem()
+	for _, item := range items {
+		switch sum.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			sum.SetInt(sum.Int() + item.value.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Uintptr:
+			sum.SetUint(sum.Uint() + item.value.Uint())
+		case reflect.Float32, reflect.Float64:
+			sum.SetFloat(sum.Float() + item.value.Float())
+		default: // complex
+			sum.SetComplex(sum.Complex() + item.value.Complex())
+		}
+	}
+	return s.compare(ctx, sum)
+}
+
+type tdMinMax struct {
+	tdAggregateBase
+	max bool
+}
+
+var _ TestDeep = &tdMinMax{}
+
+// Min is a smuggler operator. It takes an array, a slice or a map
+// (only values are considered) of numbers, strings or time.Time,
+// computes the minimum of its items and compares it to
+// "expectedValue".
+//
+// If "fieldsPath" is passed, items must be structs (or pointers on
+// structs), and the values considered are the ones reached through
+// this fields-path, as in Smuggle operator.
+//
+// All values must have the same type. Min fails on an empty array,
+// slice or map.
+//
+//   Cmp(t, []int{3, 1, 2}, Min(1))                       // succeeds
+//   Cmp(t, []time.Duration{time.Second, time.Minute}, Min(time.Second))
+//   Cmp(t, events, Min(Gte(start), "Date"))              // all events after start
+func Min(expectedValue interface{}, fieldsPath ...string) TestDeep {
+	return &tdMinMax{
+		tdAggregateBase: newAggregateBase("min", expectedValue, fieldsPath),
+	}
+}
+
+// Max is a smuggler operator. It takes an array, a slice or a map
+// (only values are considered) of numbers, strings or time.Time,
+// computes the maximum of its items and compares it to
+// "expectedValue".
+//
+// If "fieldsPath" is passed, items must be structs (or pointers on
+// structs), and the values considered are the ones reached through
+// this fields-path, as in Smuggle operator.
+//
+// All values must have the same type. Max fails on an empty array,
+// slice or map.
+//
+//   Cmp(t, []int{3, 1, 2}, Max(3))                       // succeeds
+//   Cmp(t, []time.Duration{time.Second, time.Minute}, Max(time.Minute))
+//   Cmp(t, orders, Max(Lte(1000.0), "Amount"))           // no order above 1000
+func Max(expectedValue interface{}, fieldsPath ...string) TestDeep {
+	return &tdMinMax{
+		tdAggregateBase: newAggregateBase("max", expectedValue, fieldsPath),
+		max:             true,
+	}
+}
+
+// minMaxLess returns true if "a" < "b". Both must have the same
+// type, accepted by isMinMaxable.
+func minMaxLess(a, b reflect.Value) bool {
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() < b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Uintptr:
+		return a.Uint() < b.Uint()
+	case reflect.Float32, reflect.Float64:
+		return a.Float() < b.Float()
+	case reflect.String:
+		return a.String() < b.String()
+	default: // time.Time
+		return dark.MustGetInterface(a).(time.Time).
+			Before(dark.MustGetInterface(b).(time.Time))
+	}
+}
+
+func isMinMaxable(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Uintptr, reflect.Float32, reflect.Float64, reflect.String:
+		return true
+	case reflect.Struct:
+		return v.Type() == timeType
+	}
+	return false
+}
+
+func (m *tdMinMax) Match(ctx ctxerr.Context, got reflect.Value) *ctxerr.Error {
+	items, ok, err := m.values(ctx, got)
+	if !ok {
+		return err
+	}
+
+	if len(items) == 0 {
+		return m.error(ctx, &ctxerr.Error{
+			Message: "cannot compute " + m.name,
+			Summary: ctxerr.NewSummary("no items"),
+		})
+	}
+
+	if !items[0].value.IsValid() || !isMinMaxable(items[0].value) {
+		return m.badType(items[0], "number, string or time.Time")
+	}
+	if ok, err := m.checkSameType(items); !ok {
+		return err
+	}
+
+	res := items[0].value
+	for _, item := range items[1:] {
+		if m.max {
+			if minMaxLess(res, item.value) {
+				res = item.value
+			}
+		} else if minMaxLess(item.value, res) {
+			res = item.value
+		}
+	}
+	return m.compare(ctx, res)
+}
+
+type tdReduce struct {
+	tdSmugglerBase
+	fn   reflect.Value
+	init reflect.Value
+}
+
+var _ TestDeep = &tdReduce{}
+
+// Reduce is a smuggler operator. It takes an array, a slice or a map
+// (only values are used, sorted by their keys), and calls "fn" for
+// each item, passing it the accumulator (initialized with "init")
+// and the item, the returned value becoming the new accumulator. The
+// final accumulator is compared to "expectedValue".
+//
+// "fn" must be a function taking two parameters: the accumulator,
+// and an item whose type must be convertible to the type of data
+// items. It must return one value assignable to the accumulator
+// type. "init" must be convertible to the accumulator type.
+//
+//   Cmp(t, []int{1, 2, 3, 4},
+//     Reduce(func(acc, n int) int { return acc * n }, 1, 24)) // succeeds
+//   Cmp(t, []string{"a", "b", "c"},
+//     Reduce(func(acc string, s string) string { return acc + s }, "", "abc"))
+func Reduce(fn interface{}, init interface{}, expectedValue interface{}) TestDeep {
+	const usage = "Reduce(FUNC, INIT, TESTDEEP_OPERATOR|EXPECTED_VALUE)"
+
+	vfn := reflect.ValueOf(fn)
+	if vfn.Kind() != reflect.Func {
+		panic("usage: " + usage)
+	}
+
+	fnType := vfn.Type()
+	if fnType.IsVariadic() || fnType.NumIn() != 2 || fnType.NumOut() != 1 ||
+		!fnType.Out(0).AssignableTo(fnType.In(0)) {
+		panic(usage + ": FUNC must take two arguments and return one value assignable to the first one")
+	}
+
+	accType := fnType.In(0)
+	vinit := reflect.ValueOf(init)
+	if vinit.IsValid() {
+		if !vinit.Type().ConvertibleTo(accType) {
+			panic(usage + ": INIT must be convertible to " + accType.String())
+		}
+		vinit = vinit.Convert(accType)
+	} else {
+		switch accType.Kind() {
+		case reflect.Chan, reflect.Func, reflect.Interface,
+			reflect.Map, reflect.Ptr, reflect.Slice:
+			vinit = reflect.Zero(accType)
+		default:
+			panic(usage + ": INIT must be convertible to " + accType.String())
+		}
+	}
+
+	r := tdReduce{
+		tdSmugglerBase: newSmugglerBase(expectedValue),
+		fn:             vfn,
+		init:           vinit,
+	}
+	if !r.isTestDeeper {
+		r.expectedValue = reflect.ValueOf(expectedValue)
+	}
+	return &r
+}
+
+func (r *tdReduce) Match(ctx ctxerr.Context, got reflect.Value) *ctxerr.Error {
+	items, ok, err := aggItems(ctx, got)
+	if !ok {
+		return err
+	}
+
+	itemType := r.fn.Type().In(1)
+	acc := r.init
+	for _, item := range items {
+		value := item.value
+		if !value.IsValid() {
+			if itemType.Kind() == reflect.Interface {
+				value = reflect.Zero(itemType)
+			}
+		} else if !value.CanInterface() {
+			if iface, ok := dark.GetInterface(value, true); ok {
+				value = reflect.ValueOf(iface)
+			}
+		}
+
+		if !value.IsValid() || !value.Type().ConvertibleTo(itemType) {
+			if ctx.BooleanError {
+				return ctxerr.BooleanError
+			}
+			err := ctxerr.Error{
+				Message:  "incompatible parameter type",
+				Expected: types.RawString(itemType.String()),
+			}
+			if value.IsValid() {
+				err.Got = types.RawString(value.Type().String())
+			} else {
+				err.Got = types.RawString("nil")
+			}
+			return item.ctx.CollectError(&err)
+		}
+
+		acc = r.fn.Call([]reflect.Value{acc, value.Convert(itemType)})[0]
+	}
+
+	return deepValueEqual(ctx.AddFunctionCall("reduce"), acc, r.expectedValue)
+}
+
+func (r *tdReduce) HandleInvalid() bool {
+	return true // Knows how to handle untyped nil values (aka. invalid values)
+}
+
+func (r *tdReduce) String() string {
+	prefix := "reduce(" + r.fn.Type().String() + ")"
+	if r.isTestDeeper {
+		return prefix + ": " + r.expectedValue.Interface().(TestDeep).String()
+	}
+	return prefix + "=" + util.ToString(r.expectedValue)
+}
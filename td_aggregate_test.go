@@ -0,0 +1,339 @@
+// Copyright (c) 2019, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package testdeep_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep"
+	"github.com/maxatome/go-testdeep/internal/test"
+)
+
+type aggOrder struct {
+	ID     int
+	Amount float64
+	Date   time.Time
+	Items  interface{}
+}
+
+func TestSum(t *testing.T) {
+	checkOK(t, []int{1, 2, 3}, testdeep.Sum(6))
+	checkOK(t, [3]int{1, 2, 3}, testdeep.Sum(testdeep.Between(5, 7)))
+	checkOK(t, []uint8{200, 50}, testdeep.Sum(uint8(250)))
+	checkOK(t, []float64{1.5, 2.5}, testdeep.Sum(4.0))
+	checkOK(t, []complex128{1 + 2i, 3 - 1i}, testdeep.Sum(4+1i))
+	checkOK(t, []time.Duration{time.Second, time.Minute},
+		testdeep.Sum(61*time.Second))
+	checkOK(t, map[string]int{"a": 1, "b": 2}, testdeep.Sum(3))
+	checkOK(t, []interface{}{1, 2}, testdeep.Sum(3))
+	checkOK(t, []int{}, testdeep.Sum(0))
+	checkOK(t, []float64(nil), testdeep.Sum(0.0))
+	checkOK(t, []string{}, testdeep.Sum(0))
+
+	orders := []aggOrder{
+		{ID: 1, Amount: 12.5},
+		{ID: 2, Amount: 7.5},
+	}
+	checkOK(t, orders, testdeep.Sum(20.0, "Amount"))
+	checkOK(t, []*aggOrder{&orders[0], &orders[1]}, testdeep.Sum(3, "ID"))
+	checkOK(t, []aggOrder{}, testdeep.Sum(0.0, "Amount"))
+	checkOK(t, []*aggOrder{}, testdeep.Sum(0, "ID"))
+	checkOK(t, []aggOrder{}, testdeep.Sum(0, "Items")) // interface{} field
+	checkOK(t, []aggOrder{}, testdeep.Sum(0, "Date"))  // not summable field
+	checkOK(t, []aggOrder{{Items: 3}, {Items: 4}}, testdeep.Sum(7, "Items"))
+
+	checkError(t, []int{1, 2, 3}, testdeep.Sum(7),
+		expectedError{
+			Message:  mustBe("values differ"),
+			Path:     mustBe("sum(DATA)"),
+			Got:      mustBe("6"),
+			Expected: mustBe("7"),
+		})
+
+	checkError(t, orders, testdeep.Sum(testdeep.Gt(30.0), "Amount"),
+		expectedError{
+			Message:  mustBe("values differ"),
+			Path:     mustBe("sum(DATA)"),
+			Got:      mustBe("20"),
+			Expected: mustBe("> 30"),
+		})
+
+	checkError(t, []string{"a"}, testdeep.Sum(0),
+		expectedError{
+			Message:  mustBe("cannot compute sum"),
+			Path:     mustBe("DATA[0]"),
+			Got:      mustBe("string"),
+			Expected: mustBe("number"),
+		})
+
+	checkError(t, []interface{}{1, 2.5}, testdeep.Sum(0),
+		expectedError{
+			Message:  mustBe("cannot compute sum"),
+			Path:     mustBe("DATA[1]"),
+			Got:      mustBe("float64"),
+			Expected: mustBe("int"),
+		})
+
+	checkError(t, []interface{}{1, nil}, testdeep.Sum(0),
+		expectedError{
+			Message:  mustBe("cannot compute sum"),
+			Path:     mustBe("DATA[1]"),
+			Got:      mustBe("nil"),
+			Expected: mustBe("int"),
+		})
+
+	checkError(t, []aggOrder{{}}, testdeep.Sum(0, "Date"),
+		expectedError{
+			Message:  mustBe("cannot compute sum"),
+			Path:     mustBe("DATA[0].Date"),
+			Got:      mustBe("time.Time"),
+			Expected: mustBe("number"),
+		})
+
+	checkError(t, []int{1}, testdeep.Sum(0, "Amount"),
+		expectedError{
+			Message: mustBe("cannot get field"),
+			Path:    mustBe("DATA[0]"),
+			Summary: mustContain("it is not a struct and should be"),
+		})
+
+	checkError(t, []*aggOrder{nil}, testdeep.Sum(0, "Amount"),
+		expectedError{
+			Message: mustBe("cannot get field"),
+			Path:    mustBe("DATA[0]"),
+		})
+
+	checkError(t, []interface{}{nil}, testdeep.Sum(0, "Amount"),
+		expectedError{
+			Message: mustBe("cannot get field"),
+			Path:    mustBe("DATA[0]"),
+			Summary: mustContain("it is nil"),
+		})
+
+	checkError(t, 12, testdeep.Sum(12),
+		expectedError{
+			Message:  mustBe("bad type"),
+			Path:     mustBe("DATA"),
+			Got:      mustBe("int"),
+			Expected: mustBe("Array, Slice or Map"),
+		})
+
+	checkError(t, nil, testdeep.Sum(12),
+		expectedError{
+			Message:  mustBe("bad type"),
+			Path:     mustBe("DATA"),
+			Got:      mustBe("nil"),
+			Expected: mustBe("Array, Slice or Map"),
+		})
+
+	//
+	// Bad usage
+	test.CheckPanic(t, func() { testdeep.Sum(1, "Amount", "ID") },
+		"usage: sum(TESTDEEP_OPERATOR|EXPECTED_VALUE[, FIELDS_PATH])")
+	test.CheckPanic(t, func() { testdeep.Sum(1, "9ID") },
+		"sum(TESTDEEP_OPERATOR|EXPECTED_VALUE[, FIELDS_PATH]): bad field name `9ID' in FIELDS_PATH")
+
+	//
+	// String
+	test.EqualStr(t, testdeep.Sum(6).String(), "sum=6")
+	test.EqualStr(t, testdeep.Sum(testdeep.Gt(6)).String(), "sum: > 6")
+	test.EqualStr(t, testdeep.Sum(6, "ID").String(), `sum("ID")=6`)
+}
+
+func TestMinMax(t *testing.T) {
+	checkOK(t, []int{3, 1, 2}, testdeep.Min(1))
+	checkOK(t, []int{3, 1, 2}, testdeep.Max(3))
+	checkOK(t, [3]uint{3, 1, 2}, testdeep.Max(uint(3)))
+	checkOK(t, []float64{3.5, -1.5, 2}, testdeep.Min(-1.5))
+	checkOK(t, []string{"b", "a", "c"}, testdeep.Min("a"))
+	checkOK(t, []string{"b", "a", "c"}, testdeep.Max("c"))
+	checkOK(t, []time.Duration{time.Minute, time.Second},
+		testdeep.Min(time.Second))
+	checkOK(t, map[string]int{"a": 4, "b": 2, "c": 8},
+		testdeep.Max(testdeep.Between(5, 10)))
+	checkOK(t, []interface{}{4, 2}, testdeep.Min(2))
+	checkOK(t, []int{42}, testdeep.Min(42))
+	checkOK(t, []int{42}, testdeep.Max(42))
+
+	now := time.Now()
+	orders := []aggOrder{
+		{ID: 3, Amount: 12.5, Date: now.Add(time.Hour)},
+		{ID: 1, Amount: 7.5, Date: now},
+		{ID: 2, Amount: 25, Date: now.Add(-time.Hour)},
+	}
+	checkOK(t, orders, testdeep.Min(1, "ID"))
+	checkOK(t, orders, testdeep.Max(25.0, "Amount"))
+	checkOK(t, orders, testdeep.Min(now.Add(-time.Hour), "Date"))
+	checkOK(t, orders, testdeep.Max(now.Add(time.Hour), "Date"))
+	checkOK(t, []time.Time{now, now.Add(time.Second)}, testdeep.Max(testdeep.Gt(now)))
+
+	checkError(t, []int{3, 1, 2}, testdeep.Min(2),
+		expectedError{
+			Message:  mustBe("values differ"),
+			Path:     mustBe("min(DATA)"),
+			Got:      mustBe("1"),
+			Expected: mustBe("2"),
+		})
+
+	checkError(t, orders, testdeep.Max(testdeep.Lt(20.0), "Amount"),
+		expectedError{
+			Message:  mustBe("values differ"),
+			Path:     mustBe("max(DATA)"),
+			Got:      mustBe("25"),
+			Expected: mustBe("< 20"),
+		})
+
+	checkError(t, []int{}, testdeep.Min(0),
+		expectedError{
+			Message: mustBe("cannot compute min"),
+			Path:    mustBe("DATA"),
+			Summary: mustContain("no items"),
+		})
+
+	checkError(t, map[string]int{}, testdeep.Max(0),
+		expectedError{
+			Message: mustBe("cannot compute max"),
+			Path:    mustBe("DATA"),
+			Summary: mustContain("no items"),
+		})
+
+	checkError(t, []bool{true}, testdeep.Max(true),
+		expectedError{
+			Message:  mustBe("cannot compute max"),
+			Path:     mustBe("DATA[0]"),
+			Got:      mustBe("bool"),
+			Expected: mustBe("number, string or time.Time"),
+		})
+
+	checkError(t, []interface{}{1, "a"}, testdeep.Min(1),
+		expectedError{
+			Message:  mustBe("cannot compute min"),
+			Path:     mustBe("DATA[1]"),
+			Got:      mustBe("string"),
+			Expected: mustBe("int"),
+		})
+
+	checkError(t, orders, testdeep.Min(0, "Items"),
+		expectedError{
+			Message:  mustBe("cannot compute min"),
+			Path:     mustBe("DATA[0].Items"),
+			Got:      mustBe("nil"),
+			Expected: mustBe("number, string or time.Time"),
+		})
+
+	checkError(t, "foo", testdeep.Max("o"),
+		expectedError{
+			Message:  mustBe("bad type"),
+			Path:     mustBe("DATA"),
+			Got:      mustBe("string"),
+			Expected: mustBe("Array, Slice or Map"),
+		})
+
+	//
+	// Bad usage
+	test.CheckPanic(t, func() { testdeep.Min(1, "A", "B") },
+		"usage: min(TESTDEEP_OPERATOR|EXPECTED_VALUE[, FIELDS_PATH])")
+	test.CheckPanic(t, func() { testdeep.Max(1, "A", "B") },
+		"usage: max(TESTDEEP_OPERATOR|EXPECTED_VALUE[, FIELDS_PATH])")
+
+	//
+	// String
+	test.EqualStr(t, testdeep.Min(6).String(), "min=6")
+	test.EqualStr(t, testdeep.Max(testdeep.Gt(6)).String(), "max: > 6")
+	test.EqualStr(t, testdeep.Max(6, "ID").String(), `max("ID")=6`)
+}
+
+func TestReduce(t *testing.T) {
+	product := func(acc, n int) int { return acc * n }
+
+	checkOK(t, []int{1, 2, 3, 4}, testdeep.Reduce(product, 1, 24))
+	checkOK(t, [4]int{1, 2, 3, 4}, testdeep.Reduce(product, 1, testdeep.Gt(20)))
+	checkOK(t, []int{}, testdeep.Reduce(product, 1, 1))
+	checkOK(t, []int8{1, 2, 3}, testdeep.Reduce(product, 1, 6))
+	checkOK(t, map[string]int{"a": 2, "b": 3}, testdeep.Reduce(product, 1, 6))
+	checkOK(t, []string{"a", "b", "c"},
+		testdeep.Reduce(func(acc, s string) string { return acc + s }, "", "abc"))
+
+	// map values are passed sorted by their keys
+	checkOK(t, map[int]string{3: "c", 1: "a", 2: "b"},
+		testdeep.Reduce(func(acc []string, s string) []string {
+			return append(acc, s)
+		}, nil, []string{"a", "b", "c"}))
+
+	// init is converted to accumulator type
+	checkOK(t, []float64{1.5, 2.5},
+		testdeep.Reduce(func(acc, f float64) float64 { return acc + f }, 1, 5.0))
+
+	// nil items are passed to interface parameters
+	checkOK(t, []interface{}{1, nil, "a"},
+		testdeep.Reduce(func(acc int, v interface{}) int {
+			if v == nil {
+				return acc
+			}
+			return acc + 1
+		}, 0, 2))
+
+	checkError(t, []int{1, 2, 3}, testdeep.Reduce(product, 1, 7),
+		expectedError{
+			Message:  mustBe("values differ"),
+			Path:     mustBe("reduce(DATA)"),
+			Got:      mustBe("6"),
+			Expected: mustBe("7"),
+		})
+
+	checkError(t, []string{"a"}, testdeep.Reduce(product, 1, 1),
+		expectedError{
+			Message:  mustBe("incompatible parameter type"),
+			Path:     mustBe("DATA[0]"),
+			Got:      mustBe("string"),
+			Expected: mustBe("int"),
+		})
+
+	checkError(t, []interface{}{1, nil}, testdeep.Reduce(product, 1, 1),
+		expectedError{
+			Message:  mustBe("incompatible parameter type"),
+			Path:     mustBe("DATA[1]"),
+			Got:      mustBe("nil"),
+			Expected: mustBe("int"),
+		})
+
+	checkError(t, 12, testdeep.Reduce(product, 1, 1),
+		expectedError{
+			Message:  mustBe("bad type"),
+			Path:     mustBe("DATA"),
+			Got:      mustBe("int"),
+			Expected: mustBe("Array, Slice or Map"),
+		})
+
+	//
+	// Bad usage
+	const usage = "Reduce(FUNC, INIT, TESTDEEP_OPERATOR|EXPECTED_VALUE)"
+	test.CheckPanic(t, func() { testdeep.Reduce(42, 1, 1) }, "usage: "+usage)
+	test.CheckPanic(t, func() { testdeep.Reduce(func(int) int { return 0 }, 1, 1) },
+		usage+": FUNC must take two arguments and return one value assignable to the first one")
+	test.CheckPanic(t, func() { testdeep.Reduce(func(int, int) string { return "" }, 1, 1) },
+		usage+": FUNC must take two arguments and return one value assignable to the first one")
+	test.CheckPanic(t, func() { testdeep.Reduce(product, "1", 1) },
+		usage+": INIT must be convertible to int")
+	test.CheckPanic(t, func() { testdeep.Reduce(product, nil, 1) },
+		usage+": INIT must be convertible to int")
+
+	//
+	// String
+	test.EqualStr(t, testdeep.Reduce(product, 1, 24).String(),
+		"reduce(func(int, int) int)=24")
+	test.EqualStr(t, testdeep.Reduce(product, 1, testdeep.Gt(20)).String(),
+		"reduce(func(int, int) int): > 20")
+}
+
+func TestAggregateTypeBehind(t *testing.T) {
+	equalTypes(t, testdeep.Sum(6), nil)
+	equalTypes(t, testdeep.Min(6), nil)
+	equalTypes(t, testdeep.Max(6), nil)
+	equalTypes(t, testdeep.Reduce(func(a, b int) int { return a }, 0, 6), nil)
+}
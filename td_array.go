@@ -10,40 +10,146 @@ import (
 	"bytes"
 	"fmt"
 	"reflect"
+	"sort"
+	"strconv"
 
 	"github.com/maxatome/go-testdeep/internal/ctxerr"
 	"github.com/maxatome/go-testdeep/internal/types"
 	"github.com/maxatome/go-testdeep/internal/util"
 )
 
+type arrayKind uint8
+
+const (
+	arrayArray arrayKind = iota
+	arraySlice
+	arraySuperSlice
+)
+
 type tdArray struct {
 	tdExpectedType
+	kind            arrayKind
 	expectedEntries []reflect.Value
+	entryNames      []string     // "" for items not explicitly expected
+	relEntries      []arrayEntry // entries depending on the slice length
 }
 
 var _ TestDeep = &tdArray{}
 
 // ArrayEntries allows to pass array or slice entries to check in
-// functions Array and Slice. It is a map whose each key is the item
-// index and the corresponding value the expected item value (which
-// can be a TestDeep operator as well as a zero value.)
+// functions Array, Slice and SuperSliceOf. It is a map whose each
+// key is the item index and the corresponding value the expected
+// item value (which can be a TestDeep operator as well as a zero
+// value.)
+//
+// A negative index refers to an item from the end: -1 is the last
+// item, -2 the one before, and so on.
+//
+// A value can also be an ArrayRange, to check several contiguous
+// items against the same expected value. In this case, the key must
+// be the same as the From field of the ArrayRange.
 type ArrayEntries map[int]interface{}
 
-func newArray(kind reflect.Kind, model interface{}, expectedEntries ArrayEntries) *tdArray {
+// ArrayRange allows to check a range of items in ArrayEntries. All
+// items from index From to index To (both included) are compared to
+// Expected. As for ArrayEntries keys, negative indexes refer to items
+// from the end:
+//
+//   ArrayEntries{
+//     0: 12,
+//     1: ArrayRange{From: 1, To: -1, Expected: Gt(12)},
+//   }
+//
+// checks that the first item is 12 and all following ones are
+// greater than 12, whatever the length of the slice is.
+//
+// When indexes depend on the slice length, the range can be empty.
+type ArrayRange struct {
+	From     int
+	To       int
+	Expected interface{}
+}
+
+// arrayEntry is an expected value for items from index "from" to
+// index "to", both included.
+type arrayEntry struct {
+	from, to int
+	expected reflect.Value
+	isRange  bool
+	name     string
+}
+
+// isFixed returns true if the indexes of "e" do not depend on the
+// length of data.
+func (e *arrayEntry) isFixed() bool {
+	return e.from >= 0 && e.to >= 0
+}
+
+// isFromEnd returns true if all indexes of "e" are relative to the
+// end of data.
+func (e *arrayEntry) isFromEnd() bool {
+	return e.from < 0 && e.to < 0
+}
+
+// alwaysOverlaps returns true if "e" and "o" overlap whatever the
+// length of data is. It can only be decided when all their indexes
+// have the same sign, other cases are checked at match time.
+func (e *arrayEntry) alwaysOverlaps(o *arrayEntry) bool {
+	if !(e.isFixed() && o.isFixed()) && !(e.isFromEnd() && o.isFromEnd()) {
+		return false
+	}
+	return e.from <= o.to && o.from <= e.to
+}
+
+// resolve returns the indexes of "e" for a data of length "length".
+func (e *arrayEntry) resolve(length int) (from, to int) {
+	from, to = e.from, e.to
+	if from < 0 {
+		from += length
+	}
+	if to < 0 {
+		to += length
+	}
+	return
+}
+
+func (e *arrayEntry) label() string {
+	if e.isRange {
+		return fmt.Sprintf("%d to %d", e.from, e.to)
+	}
+	return strconv.Itoa(e.from)
+}
+
+func newArray(kind arrayKind, model interface{}, expectedEntries ArrayEntries) *tdArray {
 	vmodel := reflect.ValueOf(model)
 
 	a := tdArray{
 		tdExpectedType: tdExpectedType{
 			Base: NewBase(4),
 		},
+		kind: kind,
 	}
 
-	switch vmodel.Kind() {
-	case reflect.Ptr:
-		if vmodel.Type().Elem().Kind() != kind {
-			break
+	modelKind := vmodel.Kind()
+	if modelKind == reflect.Ptr {
+		modelKind = vmodel.Type().Elem().Kind()
+	}
+	switch kind {
+	case arrayArray:
+		if modelKind != reflect.Array {
+			return nil
 		}
+	case arraySlice:
+		if modelKind != reflect.Slice {
+			return nil
+		}
+	default:
+		if modelKind != reflect.Array && modelKind != reflect.Slice {
+			return nil
+		}
+	}
 
+	if vmodel.Kind() == reflect.Ptr {
 		a.isPtr = true
 
 		if vmodel.IsNil() {
@@ -53,15 +159,11 @@ func newArray(kind reflect.Kind, model interface{}, expectedEntries ArrayEntries
 		}
 
 		vmodel = vmodel.Elem()
-		fallthrough
-
-	case kind:
-		a.expectedType = vmodel.Type()
-		a.populateExpectedEntries(expectedEntries, vmodel)
-		return &a
 	}
 
-	return nil
+	a.expectedType = vmodel.Type()
+	a.populateExpectedEntries(expectedEntries, vmodel)
+	return &a
 }
 
 // Array operator compares the contents of an array or a pointer on an
@@ -71,11 +173,13 @@ func newArray(kind reflect.Kind, model interface{}, expectedEntries ArrayEntries
 // "model" must be the same type as compared data.
 //
 // "expectedEntries" can be nil, if no zero entries are expected and
-// no TestDeep operator are involved.
+// no TestDeep operator are involved. Its negative indexes refer to
+// items from the end of the array, and ArrayRange values allow to
+// check several items at once.
 //
 // TypeBehind method returns the reflect.Type of "model".
 func Array(model interface{}, expectedEntries ArrayEntries) TestDeep {
-	a := newArray(reflect.Array, model, expectedEntries)
+	a := newArray(arrayArray, model, expectedEntries)
 	if a == nil {
 		panic("usage: Array(ARRAY|&ARRAY, EXPECTED_ENTRIES)")
 	}
@@ -89,96 +193,204 @@ func Array(model interface{}, expectedEntries ArrayEntries) TestDeep {
 // "model" must be the same type as compared data.
 //
 // "expectedEntries" can be nil, if no zero entries are expected and
-// no TestDeep operator are involved.
+// no TestDeep operator are involved. Its negative indexes refer to
+// items from the end of the slice, and ArrayRange values allow to
+// check several items at once:
+//
+//   Cmp(t, []int{1, 2, 3, 42},
+//     Slice([]int{}, ArrayEntries{
+//       0:  1,
+//       1:  ArrayRange{From: 1, To: -2, Expected: Between(2, 3)},
+//       -1: 42,
+//     })) // succeeds
+//
+// Items that are not expected by "model" nor "expectedEntries" must
+// be zero. When negative indexes are involved, the length of the
+// slice is not fixed, but two entries referring to the same item is
+// an error.
 //
 // TypeBehind method returns the reflect.Type of "model".
 func Slice(model interface{}, expectedEntries ArrayEntries) TestDeep {
-	a := newArray(reflect.Slice, model, expectedEntries)
+	a := newArray(arraySlice, model, expectedEntries)
 	if a == nil {
 		panic("usage: Slice(SLICE|&SLICE, EXPECTED_ENTRIES)")
 	}
 	return a
 }
 
-func (a *tdArray) populateExpectedEntries(expectedEntries ArrayEntries, expectedModel reflect.Value) {
-	var maxLength, numEntries int
-
-	maxIndex := -1
-	for index := range expectedEntries {
-		if index > maxIndex {
-			maxIndex = index
-		}
+// SuperSliceOf operator compares the contents of an array, a pointer
+// on an array, a slice or a pointer on a slice against the non-zero
+// values of "model" (if any) and the values of "expectedEntries". So
+// unlike Array and Slice operators, zero entries of "model", items
+// not listed and the length of data are ignored:
+//
+//   Cmp(t, []int{12, 14, 17, 42}, SuperSliceOf([]int{12}, ArrayEntries{
+//     2:  17,
+//     -1: 42,
+//   })) // succeeds
+//
+// "model" must be the same type as compared data.
+//
+// "expectedEntries" can be nil, if no TestDeep operator are
+// involved. Its negative indexes refer to items from the end of
+// data, and ArrayRange values allow to check several items at once.
+//
+// TypeBehind method returns the reflect.Type of "model".
+func SuperSliceOf(model interface{}, expectedEntries ArrayEntries) TestDeep {
+	a := newArray(arraySuperSlice, model, expectedEntries)
+	if a == nil {
+		panic("usage: SuperSliceOf(ARRAY|&ARRAY|SLICE|&SLICE, EXPECTED_ENTRIES)")
 	}
+	return a
+}
 
-	if a.expectedType.Kind() == reflect.Array {
-		maxLength = a.expectedType.Len()
+// parseEntries returns "expectedEntries" as a slice of arrayEntry,
+// sorted by index, negative ones last.
+func (a *tdArray) parseEntries(expectedEntries ArrayEntries) []arrayEntry {
+	elemType := a.expectedType.Elem()
 
-		if maxLength <= maxIndex {
-			panic(fmt.Sprintf(
-				"array length is %d, so cannot have #%d expected index",
-				maxLength,
-				maxIndex))
+	entries := make([]arrayEntry, 0, len(expectedEntries))
+	for index, expectedValue := range expectedEntries {
+		entry := arrayEntry{
+			from: index,
+			to:   index,
+			name: fmt.Sprintf("#%d", index),
 		}
-		numEntries = maxLength
-	} else {
-		maxLength = -1
 
-		numEntries = maxIndex + 1
+		if r, ok := expectedValue.(ArrayRange); ok {
+			if r.From != index {
+				panic(fmt.Sprintf(
+					"ArrayRange From field (%d) differs from its #%d index",
+					r.From,
+					index))
+			}
+			entry.to = r.To
+			entry.isRange = true
+			entry.name = fmt.Sprintf("#%d to #%d", r.From, r.To)
+			expectedValue = r.Expected
 
-		// If slice is non-nil
-		if expectedModel.IsValid() {
-			if numEntries < expectedModel.Len() {
-				numEntries = expectedModel.Len()
+			if (r.From < 0) == (r.To < 0) && r.From > r.To {
+				panic(fmt.Sprintf("range %s is reversed", entry.name))
 			}
 		}
-	}
-
-	a.expectedEntries = make([]reflect.Value, numEntries)
 
-	elemType := a.expectedType.Elem()
-	var vexpectedValue reflect.Value
-	for index, expectedValue := range expectedEntries {
 		if expectedValue == nil {
 			switch elemType.Kind() {
 			case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map,
 				reflect.Ptr, reflect.Slice:
-				vexpectedValue = reflect.Zero(elemType) // change to a typed nil
+				entry.expected = reflect.Zero(elemType) // change to a typed nil
 			default:
 				panic(fmt.Sprintf(
-					"expected value of #%d cannot be nil as items type is %s",
-					index,
+					"expected value of %s cannot be nil as items type is %s",
+					entry.name,
 					elemType))
 			}
 		} else {
-			vexpectedValue = reflect.ValueOf(expectedValue)
+			entry.expected = reflect.ValueOf(expectedValue)
 
 			if _, ok := expectedValue.(TestDeep); !ok {
-				if !vexpectedValue.Type().AssignableTo(elemType) {
+				if !entry.expected.Type().AssignableTo(elemType) {
 					panic(fmt.Sprintf(
-						"type %s of #%d expected value differs from %s contents (%s)",
-						vexpectedValue.Type(),
-						index,
-						util.TernStr(maxLength < 0, "slice", "array"),
+						"type %s of %s expected value differs from %s contents (%s)",
+						entry.expected.Type(),
+						entry.name,
+						util.TernStr(a.expectedType.Kind() == reflect.Slice, "slice", "array"),
 						elemType))
 				}
 			}
 		}
 
-		a.expectedEntries[index] = vexpectedValue
+		entries = append(entries, entry)
 	}
 
+	// Non-negative indexes first, then negative ones
+	sort.Slice(entries, func(i, j int) bool {
+		if (entries[i].from < 0) != (entries[j].from < 0) {
+			return entries[i].from >= 0
+		}
+		return entries[i].from < entries[j].from
+	})
+	return entries
+}
+
+func (a *tdArray) populateExpectedEntries(expectedEntries ArrayEntries, expectedModel reflect.Value) {
+	entries := a.parseEntries(expectedEntries)
+
+	// For arrays, all indexes can be resolved now
+	if a.expectedType.Kind() == reflect.Array {
+		length := a.expectedType.Len()
+		for i := range entries {
+			entry := &entries[i]
+			from, to := entry.resolve(length)
+			for _, index := range [...][2]int{{from, entry.from}, {to, entry.to}} {
+				if index[0] < 0 || index[0] >= length {
+					panic(fmt.Sprintf(
+						"array length is %d, so cannot have #%d expected index",
+						length,
+						index[1]))
+				}
+			}
+			if from > to {
+				panic(fmt.Sprintf("range %s is reversed", entry.name))
+			}
+			entry.from, entry.to = from, to
+		}
+	}
+
+	for i := range entries {
+		for j := i + 1; j < len(entries); j++ {
+			if entries[i].alwaysOverlaps(&entries[j]) {
+				panic(fmt.Sprintf("%s entry overlaps %s entry",
+					entries[i].name, entries[j].name))
+			}
+		}
+	}
+
+	var numEntries int
+	if a.expectedType.Kind() == reflect.Array {
+		numEntries = a.expectedType.Len()
+	} else {
+		for _, entry := range entries {
+			if entry.isFixed() && entry.to >= numEntries {
+				numEntries = entry.to + 1
+			}
+		}
+
+		// If slice is non-nil
+		if expectedModel.IsValid() && numEntries < expectedModel.Len() {
+			numEntries = expectedModel.Len()
+		}
+	}
+
+	a.expectedEntries = make([]reflect.Value, numEntries)
+	a.entryNames = make([]string, numEntries)
+
+	for _, entry := range entries {
+		if !entry.isFixed() {
+			a.relEntries = append(a.relEntries, entry)
+			continue
+		}
+		for index := entry.from; index <= entry.to; index++ {
+			a.expectedEntries[index] = entry.expected
+			a.entryNames[index] = entry.name
+		}
+	}
+
+	elemType := a.expectedType.Elem()
 	vzero := reflect.Zero(elemType)
+
 	// Check initialized entries in model
 	if expectedModel.IsValid() {
 		zero := vzero.Interface()
 		for index := expectedModel.Len() - 1; index >= 0; index-- {
 			ventry := expectedModel.Index(index)
+			isZero := reflect.DeepEqual(zero, ventry.Interface())
 
 			// Entry already expected
-			if _, ok := expectedEntries[index]; ok {
+			if a.entryNames[index] != "" {
 				// If non-zero entry, consider it as an error (= 2 expected
 				// values for the same item)
-				if !reflect.DeepEqual(zero, ventry.Interface()) {
+				if !isZero {
 					panic(fmt.Sprintf(
 						"non zero #%d entry in model already exists in expectedEntries",
 						index))
@@ -186,34 +398,96 @@ func (a *tdArray) populateExpectedEntries(expectedEntries ArrayEntries, expected
 				continue
 			}
 
+			// SuperSliceOf ignores zero entries of model
+			if isZero {
+				if a.kind == arraySuperSlice {
+					continue
+				}
+			} else {
+				a.entryNames[index] = fmt.Sprintf("model #%d", index)
+			}
 			a.expectedEntries[index] = ventry
 		}
-	} else if a.expectedType.Kind() == reflect.Slice {
-		return // nil slice
 	}
 
-	var index int
+	// Initialize missing expected items to zero
+	if a.kind != arraySuperSlice {
+		for index, expectedValue := range a.expectedEntries {
+			if !expectedValue.IsValid() {
+				a.expectedEntries[index] = vzero
+			}
+		}
+	}
+}
 
-	// Array case, all is OK
-	if maxLength >= 0 {
-		// Non-nil array => a.expectedEntries already fully initialized
-		if expectedModel.IsValid() {
-			return
+// resolveEntries returns the expected entries for a data of length
+// "gotLen", taking into account entries depending on this length. If
+// these entries cannot be resolved, false and an error are returned.
+func (a *tdArray) resolveEntries(ctx ctxerr.Context, gotLen int) ([]reflect.Value, bool, *ctxerr.Error) {
+	if len(a.relEntries) == 0 {
+		return a.expectedEntries, true, nil
+	}
+
+	numEntries := len(a.expectedEntries)
+	if numEntries < gotLen {
+		numEntries = gotLen
+	}
+	entries := make([]reflect.Value, numEntries)
+	copy(entries, a.expectedEntries)
+	names := make([]string, numEntries)
+	copy(names, a.entryNames)
+
+	for _, entry := range a.relEntries {
+		from, to := entry.resolve(gotLen)
+		if from > to { // empty range
+			continue
 		}
-		// nil array => a.expectedEntries must be initialized from index=0
-		// to numEntries - 1 below
-	} else {
-		// Non-nil slice => a.expectedEntries must be initialized from
-		// index=len(slice) to last entry index of expectedEntries
-		index = expectedModel.Len()
+
+		var index int
+		switch {
+		case from < 0:
+			index = entry.from
+		case to >= gotLen:
+			index = entry.to
+		default:
+			for index = from; index <= to; index++ {
+				if names[index] != "" {
+					if ctx.BooleanError {
+						return nil, false, ctxerr.BooleanError
+					}
+					return nil, false, ctx.CollectError(&ctxerr.Error{
+						Message: "overlapping entries",
+						Summary: ctxerr.NewSummary(fmt.Sprintf(
+							"%s and %s entries both refer to item #%d",
+							names[index], entry.name, index)),
+					})
+				}
+				entries[index] = entry.expected
+				names[index] = entry.name
+			}
+			continue
+		}
+
+		if ctx.BooleanError {
+			return nil, false, ctxerr.BooleanError
+		}
+		return nil, false, ctx.AddArrayIndex(index).CollectError(&ctxerr.Error{
+			Message:  "expected value out of range",
+			Got:      types.RawString("<non-existent value>"),
+			Expected: entry.expected,
+		})
 	}
 
-	// Slice case, initialize missing expected items to zero
-	for ; index < numEntries; index++ {
-		if _, ok := expectedEntries[index]; !ok {
-			a.expectedEntries[index] = vzero
+	// Initialize missing expected items to zero
+	if a.kind != arraySuperSlice {
+		vzero := reflect.Zero(a.expectedType.Elem())
+		for index, expectedValue := range entries {
+			if !expectedValue.IsValid() {
+				entries[index] = vzero
+			}
 		}
 	}
+	return entries, true, nil
 }
 
 func (a *tdArray) Match(ctx ctxerr.Context, got reflect.Value) (err *ctxerr.Error) {
@@ -228,7 +502,17 @@ func (a *tdArray) Match(ctx ctxerr.Context, got reflect.Value) (err *ctxerr.Erro
 	}
 
 	gotLen := got.Len()
-	for index, expectedValue := range a.expectedEntries {
+	expectedEntries, ok, err := a.resolveEntries(ctx, gotLen)
+	if !ok {
+		return err
+	}
+
+	for index, expectedValue := range expectedEntries {
+		// SuperSliceOf does not check items not listed
+		if !expectedValue.IsValid() {
+			continue
+		}
+
 		curCtx := ctx.AddArrayIndex(index)
 
 		if index >= gotLen {
@@ -248,13 +532,13 @@ func (a *tdArray) Match(ctx ctxerr.Context, got reflect.Value) (err *ctxerr.Erro
 		}
 	}
 
-	if gotLen > len(a.expectedEntries) {
+	if a.kind != arraySuperSlice && gotLen > len(expectedEntries) {
 		if ctx.BooleanError {
 			return ctxerr.BooleanError
 		}
-		return ctx.AddArrayIndex(len(a.expectedEntries)).CollectError(&ctxerr.Error{
+		return ctx.AddArrayIndex(len(expectedEntries)).CollectError(&ctxerr.Error{
 			Message:  "got value out of range",
-			Got:      got.Index(len(a.expectedEntries)),
+			Got:      got.Index(len(expectedEntries)),
 			Expected: types.RawString("<non-existent value>"),
 		})
 	}
@@ -263,19 +547,41 @@ func (a *tdArray) Match(ctx ctxerr.Context, got reflect.Value) (err *ctxerr.Erro
 }
 
 func (a *tdArray) String() string {
-	buf := bytes.NewBufferString(util.TernStr(a.expectedType.Kind() == reflect.Array,
-		"Array(", "Slice("))
+	var buf *bytes.Buffer
+	switch a.kind {
+	case arrayArray:
+		buf = bytes.NewBufferString("Array(")
+	case arraySlice:
+		buf = bytes.NewBufferString("Slice(")
+	default:
+		buf = bytes.NewBufferString("SuperSliceOf(")
+	}
 
 	buf.WriteString(a.expectedTypeStr())
 
-	if len(a.expectedEntries) == 0 {
+	empty := len(a.relEntries) == 0
+	for _, expectedValue := range a.expectedEntries {
+		if expectedValue.IsValid() {
+			empty = false
+			break
+		}
+	}
+
+	if empty {
 		buf.WriteString("{})")
 	} else {
 		buf.WriteString("{\n")
 
 		for index, expectedValue := range a.expectedEntries {
-			fmt.Fprintf(buf, "  %d: %s\n", // nolint: errcheck
-				index, util.ToString(expectedValue))
+			if expectedValue.IsValid() {
+				fmt.Fprintf(buf, "  %d: %s\n", // nolint: errcheck
+					index, util.ToString(expectedValue))
+			}
+		}
+
+		for _, entry := range a.relEntries {
+			fmt.Fprintf(buf, "  %s: %s\n", // nolint: errcheck
+				entry.label(), util.ToString(entry.expected))
 		}
 
 		buf.WriteString("})")
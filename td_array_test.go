@@ -147,6 +147,28 @@ func TestArray(t *testing.T) {
 			Expected: mustBe("6"),
 		})
 
+	//
+	// Negative indexes and ranges
+	checkOK(t, [5]int{1, 2, 3, 4, 5},
+		testdeep.Array([5]int{1, 2, 3}, testdeep.ArrayEntries{-1: 5, -2: 4}))
+	checkOK(t, [5]int{1, 2, 3, 4, 5},
+		testdeep.Array([5]int{}, testdeep.ArrayEntries{
+			0:  1,
+			1:  testdeep.ArrayRange{From: 1, To: -2, Expected: testdeep.Between(2, 4)},
+			-1: 5,
+		}))
+
+	checkError(t, [5]int{1, 2, 3, 4, 5},
+		testdeep.Array([5]int{}, testdeep.ArrayEntries{
+			0: testdeep.ArrayRange{From: 0, To: -1, Expected: testdeep.Lt(5)},
+		}),
+		expectedError{
+			Message:  mustBe("values differ"),
+			Path:     mustBe("DATA[4]"),
+			Got:      mustBe("5"),
+			Expected: mustBe("< 5"),
+		})
+
 	//
 	// Bad usage
 	test.CheckPanic(t, func() { testdeep.Array("test", nil) }, "usage: Array(")
@@ -157,6 +179,29 @@ func TestArray(t *testing.T) {
 	test.CheckPanic(t,
 		func() { testdeep.Array([1]int{}, testdeep.ArrayEntries{1: 34}) },
 		"array length is 1, so cannot have #1 expected index")
+	test.CheckPanic(t,
+		func() { testdeep.Array([1]int{}, testdeep.ArrayEntries{-2: 34}) },
+		"array length is 1, so cannot have #-2 expected index")
+	test.CheckPanic(t,
+		func() {
+			testdeep.Array([3]int{}, testdeep.ArrayEntries{
+				1: testdeep.ArrayRange{From: 1, To: 3, Expected: 1},
+			})
+		},
+		"array length is 3, so cannot have #3 expected index")
+	test.CheckPanic(t,
+		func() { testdeep.Array([3]int{}, testdeep.ArrayEntries{2: 1, -1: 2}) },
+		"#2 entry overlaps #-1 entry")
+	test.CheckPanic(t,
+		func() {
+			testdeep.Array([3]int{}, testdeep.ArrayEntries{
+				1: testdeep.ArrayRange{From: 1, To: -3, Expected: 1},
+			})
+		},
+		"range #1 to #-3 is reversed")
+	test.CheckPanic(t,
+		func() { testdeep.Array([3]int{0, 0, 12}, testdeep.ArrayEntries{-1: 21}) },
+		"non zero #2 entry in model already exists in expectedEntries")
 	test.CheckPanic(t,
 		func() { testdeep.Array([3]int{}, testdeep.ArrayEntries{1: nil}) },
 		"expected value of #1 cannot be nil as items type is int")
@@ -364,6 +409,85 @@ func TestSlice(t *testing.T) {
 			Expected: mustBe("<non-existent value>"),
 		})
 
+	//
+	// Negative indexes and ranges
+	checkOK(t, []int{1, 2, 3, 42},
+		testdeep.Slice([]int{}, testdeep.ArrayEntries{
+			0:  1,
+			1:  testdeep.ArrayRange{From: 1, To: -2, Expected: testdeep.Between(2, 3)},
+			-1: 42,
+		}))
+	checkOK(t, []int{1, 42},
+		testdeep.Slice([]int{}, testdeep.ArrayEntries{
+			0:  1,
+			1:  testdeep.ArrayRange{From: 1, To: -2, Expected: testdeep.Between(2, 3)},
+			-1: 42,
+		}))
+	checkOK(t, []int{1, 0, 0, 42},
+		testdeep.Slice([]int{1}, testdeep.ArrayEntries{-1: 42}))
+	checkOK(t, []int{4, 5, 6},
+		testdeep.Slice([]int{}, testdeep.ArrayEntries{
+			-3: testdeep.ArrayRange{From: -3, To: -1, Expected: testdeep.Gt(3)},
+		}))
+	one := 1
+	checkOK(t, []*int{nil, &one},
+		testdeep.Slice([]*int{}, testdeep.ArrayEntries{-2: nil, -1: &one}))
+
+	checkError(t, []int{1, 7, 42},
+		testdeep.Slice([]int{1}, testdeep.ArrayEntries{-1: 42}),
+		expectedError{
+			Message:  mustBe("values differ"),
+			Path:     mustBe("DATA[1]"),
+			Got:      mustBe("7"),
+			Expected: mustBe("0"),
+		})
+	checkError(t, []int{42},
+		testdeep.Slice([]int{}, testdeep.ArrayEntries{0: 1, -1: 42}),
+		expectedError{
+			Message: mustBe("overlapping entries"),
+			Path:    mustBe("DATA"),
+			Summary: mustContain("#0 and #-1 entries both refer to item #0"),
+		})
+	checkError(t, []int{1, 42},
+		testdeep.Slice([]int{1}, testdeep.ArrayEntries{
+			-2: testdeep.ArrayRange{From: -2, To: -1, Expected: testdeep.Gt(0)},
+		}),
+		expectedError{
+			Message: mustBe("overlapping entries"),
+			Path:    mustBe("DATA"),
+			Summary: mustContain("model #0 and #-2 to #-1 entries both refer to item #0"),
+		})
+	// Overlapping depends on the length when indexes signs differ
+	mixed := testdeep.Slice([]int{}, testdeep.ArrayEntries{
+		-3: testdeep.ArrayRange{From: -3, To: 5, Expected: 0},
+		-1: 42,
+	})
+	checkOK(t, []int{0, 0, 0, 0, 0, 0, 0, 0, 42}, mixed)
+	checkError(t, []int{0, 0, 0, 0, 0, 42}, mixed,
+		expectedError{
+			Message: mustBe("overlapping entries"),
+			Path:    mustBe("DATA"),
+			Summary: mustContain("#-3 to #5 and #-1 entries both refer to item #5"),
+		})
+	checkError(t, []int{42},
+		testdeep.Slice([]int{}, testdeep.ArrayEntries{-2: 1, -1: 42}),
+		expectedError{
+			Message:  mustBe("expected value out of range"),
+			Path:     mustBe("DATA[-2]"),
+			Got:      mustBe("<non-existent value>"),
+			Expected: mustBe("1"),
+		})
+	checkError(t, []int{1, 2},
+		testdeep.Slice([]int{}, testdeep.ArrayEntries{
+			0: testdeep.ArrayRange{From: 0, To: -1, Expected: testdeep.Lt(2)},
+		}),
+		expectedError{
+			Message:  mustBe("values differ"),
+			Path:     mustBe("DATA[1]"),
+			Got:      mustBe("2"),
+			Expected: mustBe("< 2"),
+		})
+
 	//
 	// nil cases
 	var (
@@ -385,6 +509,46 @@ func TestSlice(t *testing.T) {
 	test.CheckPanic(t,
 		func() { testdeep.Slice([]int{}, testdeep.ArrayEntries{1: "bad"}) },
 		"type string of #1 expected value differs from slice contents (int)")
+	test.CheckPanic(t,
+		func() { testdeep.Slice([]int{}, testdeep.ArrayEntries{-1: 1, -2: nil}) },
+		"expected value of #-2 cannot be nil as items type is int")
+	test.CheckPanic(t,
+		func() {
+			testdeep.Slice([]int{}, testdeep.ArrayEntries{
+				-3: testdeep.ArrayRange{From: -3, To: -1, Expected: "bad"},
+			})
+		},
+		"type string of #-3 to #-1 expected value differs from slice contents (int)")
+	test.CheckPanic(t,
+		func() {
+			testdeep.Slice([]int{}, testdeep.ArrayEntries{
+				-3: testdeep.ArrayRange{From: -3, To: -1, Expected: 1},
+				-1: 2,
+			})
+		},
+		"#-3 to #-1 entry overlaps #-1 entry")
+	test.CheckPanic(t,
+		func() {
+			testdeep.Slice([]int{}, testdeep.ArrayEntries{
+				1: testdeep.ArrayRange{From: 1, To: 3, Expected: 1},
+				3: 2,
+			})
+		},
+		"#1 to #3 entry overlaps #3 entry")
+	test.CheckPanic(t,
+		func() {
+			testdeep.Slice([]int{}, testdeep.ArrayEntries{
+				1: testdeep.ArrayRange{From: 2, To: 3, Expected: 1},
+			})
+		},
+		"ArrayRange From field (2) differs from its #1 index")
+	test.CheckPanic(t,
+		func() {
+			testdeep.Slice([]int{}, testdeep.ArrayEntries{
+				-1: testdeep.ArrayRange{From: -1, To: -2, Expected: 1},
+			})
+		},
+		"range #-1 to #-2 is reversed")
 	test.CheckPanic(t,
 		func() { testdeep.Slice([]int{12}, testdeep.ArrayEntries{0: 21}) },
 		"non zero #0 entry in model already exists in expectedEntries")
@@ -407,6 +571,17 @@ func TestSlice(t *testing.T) {
   2: 4
 })`)
 
+	test.EqualStr(t,
+		testdeep.Slice([]int{1}, testdeep.ArrayEntries{
+			2:  testdeep.ArrayRange{From: 2, To: -2, Expected: 3},
+			-1: 4,
+		}).String(),
+		`Slice([]int{
+  0: 1
+  2 to -2: 3
+  -1: 4
+})`)
+
 	test.EqualStr(t, testdeep.Slice(&MySlice{}, testdeep.ArrayEntries{}).String(),
 		`Slice(*testdeep_test.MySlice{})`)
 }
@@ -418,3 +593,105 @@ func TestSliceTypeBehind(t *testing.T) {
 	equalTypes(t, testdeep.Slice(MySlice{}, nil), MySlice{})
 	equalTypes(t, testdeep.Slice(&MySlice{}, nil), &MySlice{})
 }
+
+func TestSuperSliceOf(t *testing.T) {
+	type MyArray [5]int
+	type MySlice []int
+
+	checkOK(t, []int{12, 14, 17, 42},
+		testdeep.SuperSliceOf([]int{12}, testdeep.ArrayEntries{2: 17, -1: 42}))
+	checkOK(t, []int{12, 14, 17, 42},
+		testdeep.SuperSliceOf([]int{12, 0, 17}, nil))
+	checkOK(t, []int{12, 14, 17, 42}, testdeep.SuperSliceOf([]int{}, nil))
+	checkOK(t, []int{}, testdeep.SuperSliceOf([]int{}, nil))
+	checkOK(t, []int(nil), testdeep.SuperSliceOf([]int{0, 0}, nil))
+	checkOK(t, &MySlice{12, 14, 17, 42},
+		testdeep.SuperSliceOf(&MySlice{}, testdeep.ArrayEntries{
+			1: testdeep.ArrayRange{From: 1, To: -2, Expected: testdeep.Between(13, 18)},
+		}))
+	checkOK(t, &MySlice{12, 14, 17, 42},
+		testdeep.SuperSliceOf((*MySlice)(nil), testdeep.ArrayEntries{-4: 12}))
+	checkOK(t, MyArray{1, 2, 3, 4, 5},
+		testdeep.SuperSliceOf(MyArray{1}, testdeep.ArrayEntries{-1: 5}))
+	checkOK(t, &MyArray{1, 2, 3, 4, 5},
+		testdeep.SuperSliceOf(&MyArray{}, testdeep.ArrayEntries{2: 3}))
+
+	checkError(t, []int{12, 14, 17, 42},
+		testdeep.SuperSliceOf([]int{12}, testdeep.ArrayEntries{-1: 41}),
+		expectedError{
+			Message:  mustBe("values differ"),
+			Path:     mustBe("DATA[3]"),
+			Got:      mustBe("42"),
+			Expected: mustBe("41"),
+		})
+	checkError(t, []int{12, 14},
+		testdeep.SuperSliceOf([]int{12}, testdeep.ArrayEntries{3: 42}),
+		expectedError{
+			Message:  mustBe("expected value out of range"),
+			Path:     mustBe("DATA[3]"),
+			Got:      mustBe("<non-existent value>"),
+			Expected: mustBe("42"),
+		})
+	checkError(t, []int{12},
+		testdeep.SuperSliceOf([]int{12, 0, 3}, nil),
+		expectedError{
+			Message:  mustBe("expected value out of range"),
+			Path:     mustBe("DATA[2]"),
+			Got:      mustBe("<non-existent value>"),
+			Expected: mustBe("3"),
+		})
+	checkError(t, []int{12},
+		testdeep.SuperSliceOf([]int{12}, testdeep.ArrayEntries{-3: 42}),
+		expectedError{
+			Message:  mustBe("expected value out of range"),
+			Path:     mustBe("DATA[-3]"),
+			Got:      mustBe("<non-existent value>"),
+			Expected: mustBe("42"),
+		})
+	checkError(t, []int{12, 14},
+		testdeep.SuperSliceOf([]int{}, testdeep.ArrayEntries{1: 14, -1: 14}),
+		expectedError{
+			Message: mustBe("overlapping entries"),
+			Path:    mustBe("DATA"),
+			Summary: mustContain("#1 and #-1 entries both refer to item #1"),
+		})
+	checkError(t, MySlice{12}, testdeep.SuperSliceOf([]int{}, nil),
+		expectedError{
+			Message:  mustBe("type mismatch"),
+			Path:     mustBe("DATA"),
+			Got:      mustBe("testdeep_test.MySlice"),
+			Expected: mustBe("[]int"),
+		})
+
+	//
+	// Bad usage
+	test.CheckPanic(t, func() { testdeep.SuperSliceOf("test", nil) },
+		"usage: SuperSliceOf(")
+	test.CheckPanic(t, func() { testdeep.SuperSliceOf(&MyStruct{}, nil) },
+		"usage: SuperSliceOf(")
+	test.CheckPanic(t, func() { testdeep.SuperSliceOf(nil, nil) },
+		"usage: SuperSliceOf(")
+	test.CheckPanic(t,
+		func() { testdeep.SuperSliceOf([]int{12}, testdeep.ArrayEntries{0: 21}) },
+		"non zero #0 entry in model already exists in expectedEntries")
+
+	//
+	// String
+	test.EqualStr(t,
+		testdeep.SuperSliceOf(MySlice{0, 0, 4}, testdeep.ArrayEntries{1: 3, -1: 2}).String(),
+		`SuperSliceOf(testdeep_test.MySlice{
+  1: 3
+  2: 4
+  -1: 2
+})`)
+	test.EqualStr(t, testdeep.SuperSliceOf([]int{0}, nil).String(),
+		`SuperSliceOf([]int{})`)
+}
+
+func TestSuperSliceOfTypeBehind(t *testing.T) {
+	type MySlice []int
+
+	equalTypes(t, testdeep.SuperSliceOf([]int{}, nil), []int{})
+	equalTypes(t, testdeep.SuperSliceOf([3]int{}, nil), [3]int{})
+	equalTypes(t, testdeep.SuperSliceOf(&MySlice{}, nil), &MySlice{})
+}
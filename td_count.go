@@ -0,0 +1,167 @@
+// Copyright (c) 2019, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package testdeep
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/maxatome/go-testdeep/helpers/tdutil"
+	"github.com/maxatome/go-testdeep/internal/ctxerr"
+	"github.com/maxatome/go-testdeep/internal/types"
+	"github.com/maxatome/go-testdeep/internal/util"
+)
+
+type tdCount struct {
+	tdGrepBase
+}
+
+var _ TestDeep = &tdCount{}
+
+// Count is a smuggler operator. It counts items of an array, a
+// slice, a map (only values are filtered) or a string (runes are
+// filtered) matching "filter", then compares this count to
+// "expectedCount". A pointer to one of them is dereferenced
+// first. "filter" is handled as in Grep operator.
+//
+// "expectedCount" can be an int value as well as an other operator:
+//
+//   Cmp(t, []int{1, 15, 2, 18}, Count(Gt(10), 2))            // succeeds
+//   Cmp(t, []int{1, 15, 2, 18}, Count(Gt(10), Between(2, 5))) // succeeds
+//   Cmp(t, "Hello World!", Count('o', 2))                     // succeeds
+//   Cmp(t, map[string]int{"a": 1, "b": 15}, Count(Lt(10), 1)) // succeeds
+//
+// On failure, indexes (or keys for maps) of matching items are listed.
+func Count(filter interface{}, expectedCount interface{}) TestDeep {
+	const usage = "Count(" + grepFilterUsage + ", TESTDEEP_OPERATOR|INT)"
+
+	c := tdCount{
+		tdGrepBase: newGrepBase(usage, filter, expectedCount),
+	}
+	if !c.isTestDeeper &&
+		(!c.expectedValue.IsValid() || c.expectedValue.Type() != intType) {
+		panic("usage: " + usage)
+	}
+	return &c
+}
+
+func (c *tdCount) Match(ctx ctxerr.Context, got reflect.Value) *ctxerr.Error {
+	var (
+		matched []string
+		label   = "matched indexes"
+	)
+
+	if got.Kind() == reflect.Ptr && !got.IsNil() {
+		switch got.Elem().Kind() {
+		case reflect.Array, reflect.Slice, reflect.Map, reflect.String:
+			got = got.Elem()
+		}
+	}
+
+	switch got.Kind() {
+	case reflect.Array, reflect.Slice:
+		for idx, l := 0, got.Len(); idx < l; idx++ {
+			ok, err := c.matchItem(ctx.AddArrayIndex(idx), got.Index(idx))
+			if err != nil {
+				return err
+			}
+			if ok {
+				matched = append(matched, strconv.Itoa(idx))
+			}
+		}
+
+	case reflect.Map:
+		label = "matched keys"
+		for _, k := range tdutil.MapSortedKeys(got) {
+			ok, err := c.matchItem(ctx.AddMapKey(k), got.MapIndex(k))
+			if err != nil {
+				return err
+			}
+			if ok {
+				matched = append(matched, util.ToString(k))
+			}
+		}
+
+	case reflect.String:
+		idx := 0
+		for _, r := range got.String() {
+			ok, err := c.matchItem(ctx.AddArrayIndex(idx), reflect.ValueOf(r))
+			if err != nil {
+				return err
+			}
+			if ok {
+				matched = append(matched, strconv.Itoa(idx))
+			}
+			idx++
+		}
+
+	default:
+		if ctx.BooleanError {
+			return ctxerr.BooleanError
+		}
+		var gotType types.RawString
+		if got.IsValid() {
+			gotType = types.RawString(got.Type().String())
+		} else {
+			gotType = "nil"
+		}
+		return ctx.CollectError(&ctxerr.Error{
+			Message:  "bad type",
+			Got:      gotType,
+			Expected: types.RawString("Array, Map, Slice or string"),
+		})
+	}
+
+	// Use deepValueEqualFinal here instead of deepValueEqual as we
+	// want to know whether an error occurred or not, we do not want
+	// to accumulate it silently
+	origErr := deepValueEqualFinal(ctx.ResetErrors().AddFunctionCall("count"),
+		reflect.ValueOf(len(matched)), c.expectedValue)
+	if origErr == nil {
+		return nil
+	}
+	if ctx.BooleanError {
+		return ctxerr.BooleanError
+	}
+
+	matchedStr := "none"
+	if len(matched) > 0 {
+		matchedStr = strings.Join(matched, ", ")
+	}
+
+	err := ctxerr.Error{
+		Message: "bad count",
+		Summary: ctxerr.ErrorSummaryItems{
+			{
+				Label: "count",
+				Value: strconv.Itoa(len(matched)),
+			},
+			{
+				Label: "expected",
+				Value: util.ToString(c.expectedValue),
+			},
+			{
+				Label: label,
+				Value: matchedStr,
+			},
+		},
+	}
+	if c.isTestDeeper {
+		err.Origin = origErr
+	}
+	return ctx.CollectError(&err)
+}
+
+func (c *tdCount) String() string {
+	if c.isTestDeeper {
+		return "Count(" + c.filterString() + "): " +
+			c.expectedValue.Interface().(TestDeep).String()
+	}
+	return fmt.Sprintf("Count(%s)=%d", c.filterString(), c.expectedValue.Int())
+}
@@ -0,0 +1,132 @@
+// Copyright (c) 2019, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package testdeep_test
+
+import (
+	"testing"
+
+	"github.com/maxatome/go-testdeep"
+	"github.com/maxatome/go-testdeep/internal/test"
+)
+
+func TestCount(t *testing.T) {
+	checkOK(t, []int{1, 15, 2, 18}, testdeep.Count(testdeep.Gt(10), 2))
+	checkOK(t, [4]int{1, 15, 2, 18}, testdeep.Count(testdeep.Gt(10), testdeep.Between(2, 5)))
+	checkOK(t, []int{1, 15, 2, 18}, testdeep.Count(15, 1))
+	checkOK(t, []int{1, 15, 2, 18},
+		testdeep.Count(func(n int) bool { return n%2 == 0 }, 2))
+	checkOK(t, []int(nil), testdeep.Count(testdeep.Gt(10), 0))
+	checkOK(t, map[string]int{"a": 1, "b": 15, "c": 2}, testdeep.Count(testdeep.Lt(10), 2))
+	checkOK(t, "Hello World!", testdeep.Count('o', 2))
+	checkOK(t, "héhé", testdeep.Count('é', 2))
+	checkOK(t, "", testdeep.Count('é', 0))
+	checkOK(t, &[]int{1, 15, 2, 18}, testdeep.Count(testdeep.Gt(10), 2))
+	checkOK(t, &[4]int{1, 15, 2, 18}, testdeep.Count(testdeep.Gt(10), 2))
+	checkOK(t, &map[string]int{"a": 1, "b": 15}, testdeep.Count(testdeep.Lt(10), 1))
+	str := "Hello World!"
+	checkOK(t, &str, testdeep.Count('o', 2))
+
+	checkError(t, []int{1, 15, 2, 18}, testdeep.Count(testdeep.Gt(10), 3),
+		expectedError{
+			Message: mustBe("bad count"),
+			Path:    mustBe("DATA"),
+			Summary: mustContain("matched indexes: "),
+		})
+	checkError(t, []int{1, 15, 2, 18}, testdeep.Count(testdeep.Gt(10), 3),
+		expectedError{
+			Message: mustBe("bad count"),
+			Path:    mustBe("DATA"),
+			Summary: mustContain("1, 3"),
+		})
+
+	checkError(t, []int{1, 2}, testdeep.Count(testdeep.Gt(10), testdeep.Between(1, 3)),
+		expectedError{
+			Message: mustBe("bad count"),
+			Path:    mustBe("DATA"),
+			Summary: mustContain("none"),
+			Origin: &expectedError{
+				Message:  mustBe("values differ"),
+				Path:     mustBe("count(DATA)"),
+				Got:      mustBe("0"),
+				Expected: mustBe("1 ≤ got ≤ 3"),
+			},
+		})
+
+	checkError(t, map[string]int{"a": 1, "b": 15, "c": 2},
+		testdeep.Count(testdeep.Lt(10), 3),
+		expectedError{
+			Message: mustBe("bad count"),
+			Path:    mustBe("DATA"),
+			Summary: mustContain(`"a", "c"`),
+		})
+
+	checkError(t, "héhé", testdeep.Count('é', 3),
+		expectedError{
+			Message: mustBe("bad count"),
+			Path:    mustBe("DATA"),
+			Summary: mustContain("1, 3"),
+		})
+
+	checkError(t, []interface{}{1, "str"},
+		testdeep.Count(func(n int) bool { return true }, 1),
+		expectedError{
+			Message:  mustBe("incompatible parameter type"),
+			Path:     mustBe("DATA[1]"),
+			Got:      mustBe("string"),
+			Expected: mustBe("int"),
+		})
+
+	checkError(t, map[string]interface{}{"a": "str"},
+		testdeep.Count(func(n int) bool { return true }, 1),
+		expectedError{
+			Message:  mustBe("incompatible parameter type"),
+			Path:     mustBe(`DATA["a"]`),
+			Got:      mustBe("string"),
+			Expected: mustBe("int"),
+		})
+
+	checkError(t, 12, testdeep.Count(12, 1),
+		expectedError{
+			Message:  mustBe("bad type"),
+			Path:     mustBe("DATA"),
+			Got:      mustBe("int"),
+			Expected: mustBe("Array, Map, Slice or string"),
+		})
+
+	checkError(t, (*[]int)(nil), testdeep.Count(12, 1),
+		expectedError{
+			Message:  mustBe("bad type"),
+			Path:     mustBe("DATA"),
+			Got:      mustBe("*[]int"),
+			Expected: mustBe("Array, Map, Slice or string"),
+		})
+
+	checkError(t, nil, testdeep.Count(12, 1),
+		expectedError{
+			Message:  mustBe("bad type"),
+			Path:     mustBe("DATA"),
+			Got:      mustBe("nil"),
+			Expected: mustBe("Array, Map, Slice or string"),
+		})
+
+	//
+	// Bad usage
+	const usage = "Count(FILTER_FUNC|FILTER_TESTDEEP_OPERATOR|FILTER_VALUE, TESTDEEP_OPERATOR|INT)"
+	test.CheckPanic(t, func() { testdeep.Count(12, "12") }, "usage: "+usage)
+	test.CheckPanic(t, func() { testdeep.Count(12, nil) }, "usage: "+usage)
+	test.CheckPanic(t, func() { testdeep.Count(func() {}, 12) },
+		usage+": FILTER_FUNC must take only one argument and return bool")
+
+	//
+	// String
+	test.EqualStr(t, testdeep.Count(testdeep.Gt(10), 2).String(), "Count(> 10)=2")
+	test.EqualStr(t, testdeep.Count(12, testdeep.Gt(2)).String(), "Count(12): > 2")
+}
+
+func TestCountTypeBehind(t *testing.T) {
+	equalTypes(t, testdeep.Count(12, 1), nil)
+}
@@ -0,0 +1,301 @@
+// Copyright (c) 2019, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package testdeep
+
+import (
+	"reflect"
+
+	"github.com/maxatome/go-testdeep/internal/ctxerr"
+	"github.com/maxatome/go-testdeep/internal/dark"
+	"github.com/maxatome/go-testdeep/internal/types"
+	"github.com/maxatome/go-testdeep/internal/util"
+)
+
+const (
+	grepFilterUsage = "FILTER_FUNC|FILTER_TESTDEEP_OPERATOR|FILTER_VALUE"
+	grepUsage       = "(" + grepFilterUsage + ", TESTDEEP_OPERATOR|EXPECTED_VALUE)"
+)
+
+// tdGrepBase is the base of Grep, First and Last operators.
+type tdGrepBase struct {
+	tdSmugglerBase
+	filter  reflect.Value
+	argType reflect.Type // only set when filter is a function
+}
+
+// newGrepBase returns a new tdGrepBase. "usage" is used to build the
+// panic message if "filter" is a function with a bad signature.
+func newGrepBase(usage string, filter, expectedValue interface{}) (g tdGrepBase) {
+	g.tdSmugglerBase = newSmugglerBase(expectedValue, 5)
+	if !g.isTestDeeper {
+		g.expectedValue = reflect.ValueOf(expectedValue)
+	}
+
+	g.filter = reflect.ValueOf(filter)
+	if g.filter.Kind() == reflect.Func {
+		fnType := g.filter.Type()
+		if fnType.IsVariadic() || fnType.NumIn() != 1 ||
+			fnType.NumOut() != 1 || fnType.Out(0).Kind() != reflect.Bool {
+			panic(usage + ": FILTER_FUNC must take only one argument and return bool")
+		}
+		g.argType = fnType.In(0)
+	}
+	return
+}
+
+// matchItem returns true if "item" matches the filter. "ctx" is the
+// context of "item". If the filter is a function that cannot be
+// called with "item", false and an error are returned.
+func (g *tdGrepBase) matchItem(ctx ctxerr.Context, item reflect.Value) (bool, *ctxerr.Error) {
+	if g.argType == nil {
+		return deepValueEqualOK(item, g.filter), nil
+	}
+
+	ret, ok, err := callItemFunc(ctx, g.filter, item)
+	if !ok {
+		return false, err
+	}
+	return ret.Bool(), nil
+}
+
+// callItemFunc calls "fn", a function taking only one parameter and
+// returning only one value, with "item" and returns the result. "ctx"
+// is the context of "item". If "item" is not convertible to the
+// parameter type of "fn", false and an error are returned.
+func callItemFunc(ctx ctxerr.Context, fn, item reflect.Value) (reflect.Value, bool, *ctxerr.Error) {
+	argType := fn.Type().In(0)
+
+	if !item.CanInterface() {
+		if iface, ok := dark.GetInterface(item, true); ok {
+			item = reflect.ValueOf(iface)
+		}
+	}
+
+	// Resolve only one interface{} dereference
+	if item.Kind() == reflect.Interface && !item.Type().ConvertibleTo(argType) {
+		item = item.Elem()
+	}
+
+	if !item.IsValid() || !item.Type().ConvertibleTo(argType) {
+		if ctx.BooleanError {
+			return reflect.Value{}, false, ctxerr.BooleanError
+		}
+		err := ctxerr.Error{
+			Message:  "incompatible parameter type",
+			Expected: types.RawString(argType.String()),
+		}
+		if item.IsValid() {
+			err.Got = types.RawString(item.Type().String())
+		} else {
+			err.Got = types.RawString("nil")
+		}
+		return reflect.Value{}, false, ctx.CollectError(&err)
+	}
+
+	return fn.Call([]reflect.Value{item.Convert(argType)})[0], true, nil
+}
+
+// getArrayOrSlice returns the array or slice behind "got". If "got"
+// is neither an array, nor a slice nor a pointer on one of them, it
+// returns false and the error to return.
+func getArrayOrSlice(ctx ctxerr.Context, got reflect.Value) (reflect.Value, bool, *ctxerr.Error) {
+	if got.Kind() == reflect.Ptr && !got.IsNil() {
+		switch got.Elem().Kind() {
+		case reflect.Array, reflect.Slice:
+			return got.Elem(), true, nil
+		}
+	}
+
+	switch got.Kind() {
+	case reflect.Array, reflect.Slice:
+		return got, true, nil
+	}
+
+	if ctx.BooleanError {
+		return reflect.Value{}, false, ctxerr.BooleanError
+	}
+	var gotType types.RawString
+	if got.IsValid() {
+		gotType = types.RawString(got.Type().String())
+	} else {
+		gotType = "nil"
+	}
+	return reflect.Value{}, false, ctx.CollectError(&ctxerr.Error{
+		Message:  "bad type",
+		Got:      gotType,
+		Expected: types.RawString("Slice OR Array OR *Slice OR *Array"),
+	})
+}
+
+func (g *tdGrepBase) filterString() string {
+	if g.argType != nil {
+		return g.filter.Type().String()
+	}
+	return util.ToString(g.filter)
+}
+
+// notFound reports no item matched the filter.
+func (g *tdGrepBase) notFound(ctx ctxerr.Context) *ctxerr.Error {
+	if ctx.BooleanError {
+		return ctxerr.BooleanError
+	}
+	return ctx.CollectError(&ctxerr.Error{
+		Message: "item not found",
+		Summary: ctxerr.NewSummary("no item matches filter " + g.filterString()),
+	})
+}
+
+func (g *tdGrepBase) HandleInvalid() bool {
+	return true // Knows how to handle untyped nil values (aka. invalid values)
+}
+
+type tdGrep struct {
+	tdGrepBase
+}
+
+var _ TestDeep = &tdGrep{}
+
+// Grep is a smuggler operator. It takes an array, a slice or a
+// pointer on array/slice, keeps only items matching "filter", then
+// compares the resulting slice to "expectedValue".
+//
+// "filter" can be:
+//   - a function taking one parameter whose type must be convertible
+//     to the type of items, and returning a bool;
+//   - a TestDeep operator;
+//   - any other value, in this case items are kept if they are equal
+//     to it.
+//
+// The filtered slice is then compared as a whole:
+//
+//   Cmp(t, []int{1, 15, 2, 18},
+//     Grep(Gt(10), []int{15, 18})) // succeeds
+//   Cmp(t, orders,
+//     Grep(func(o Order) bool { return o.Status == "paid" },
+//       ArrayEach(Smuggle("Amount", Gt(10)))))
+//
+// The filtered slice has the same item type as the got array or
+// slice. Its path is suffixed by "<Grep>", as in "DATA<Grep>[2]".
+func Grep(filter interface{}, expectedValue interface{}) TestDeep {
+	return &tdGrep{
+		tdGrepBase: newGrepBase("Grep"+grepUsage, filter, expectedValue),
+	}
+}
+
+func (g *tdGrep) Match(ctx ctxerr.Context, got reflect.Value) *ctxerr.Error {
+	list, ok, err := getArrayOrSlice(ctx, got)
+	if !ok {
+		return err
+	}
+
+	filtered := reflect.MakeSlice(reflect.SliceOf(list.Type().Elem()), 0, list.Len())
+	for idx, l := 0, list.Len(); idx < l; idx++ {
+		item := list.Index(idx)
+		ok, err := g.matchItem(ctx.AddArrayIndex(idx), item)
+		if err != nil {
+			return err
+		}
+		if ok {
+			filtered = reflect.Append(filtered, item)
+		}
+	}
+
+	return deepValueEqual(ctx.AddCustomLevel("<Grep>"), filtered, g.expectedValue)
+}
+
+func (g *tdGrep) String() string {
+	return "Grep(" + g.filterString() + ")"
+}
+
+type tdFirst struct {
+	tdGrepBase
+}
+
+var _ TestDeep = &tdFirst{}
+
+// First is a smuggler operator. It takes an array, a slice or a
+// pointer on array/slice and compares its first item matching
+// "filter" to "expectedValue". "filter" is handled as in Grep
+// operator.
+//
+//   Cmp(t, orders,
+//     First(Smuggle("Status", "paid"), Smuggle("Amount", Gt(10))))
+//
+// It fails if no item matches "filter". The path of the matching
+// item is suffixed by "<First>", as in "DATA<First>".
+func First(filter interface{}, expectedValue interface{}) TestDeep {
+	return &tdFirst{
+		tdGrepBase: newGrepBase("First"+grepUsage, filter, expectedValue),
+	}
+}
+
+func (f *tdFirst) Match(ctx ctxerr.Context, got reflect.Value) *ctxerr.Error {
+	list, ok, err := getArrayOrSlice(ctx, got)
+	if !ok {
+		return err
+	}
+
+	for idx, l := 0, list.Len(); idx < l; idx++ {
+		item := list.Index(idx)
+		ok, err := f.matchItem(ctx.AddArrayIndex(idx), item)
+		if err != nil {
+			return err
+		}
+		if ok {
+			return deepValueEqual(ctx.AddCustomLevel("<First>"), item, f.expectedValue)
+		}
+	}
+	return f.notFound(ctx)
+}
+
+func (f *tdFirst) String() string {
+	return "First(" + f.filterString() + ")"
+}
+
+type tdLast struct {
+	tdGrepBase
+}
+
+var _ TestDeep = &tdLast{}
+
+// Last is a smuggler operator. It takes an array, a slice or a
+// pointer on array/slice and compares its last item matching
+// "filter" to "expectedValue". "filter" is handled as in Grep
+// operator.
+//
+//   Cmp(t, []int{1, 15, 2, 18, 3}, Last(Gt(10), 18)) // succeeds
+//
+// It fails if no item matches "filter". The path of the matching
+// item is suffixed by "<Last>", as in "DATA<Last>".
+func Last(filter interface{}, expectedValue interface{}) TestDeep {
+	return &tdLast{
+		tdGrepBase: newGrepBase("Last"+grepUsage, filter, expectedValue),
+	}
+}
+
+func (l *tdLast) Match(ctx ctxerr.Context, got reflect.Value) *ctxerr.Error {
+	list, ok, err := getArrayOrSlice(ctx, got)
+	if !ok {
+		return err
+	}
+
+	for idx := list.Len() - 1; idx >= 0; idx-- {
+		item := list.Index(idx)
+		ok, err := l.matchItem(ctx.AddArrayIndex(idx), item)
+		if err != nil {
+			return err
+		}
+		if ok {
+			return deepValueEqual(ctx.AddCustomLevel("<Last>"), item, l.expectedValue)
+		}
+	}
+	return l.notFound(ctx)
+}
+
+func (l *tdLast) String() string {
+	return "Last(" + l.filterString() + ")"
+}
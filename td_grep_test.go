@@ -0,0 +1,189 @@
+// Copyright (c) 2019, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package testdeep_test
+
+import (
+	"testing"
+
+	"github.com/maxatome/go-testdeep"
+	"github.com/maxatome/go-testdeep/internal/test"
+)
+
+type grepOrder struct {
+	Status string
+	Amount int
+}
+
+var grepOrders = []grepOrder{
+	{Status: "pending", Amount: 5},
+	{Status: "paid", Amount: 12},
+	{Status: "paid", Amount: 8},
+	{Status: "canceled", Amount: 20},
+}
+
+func isPaid(o grepOrder) bool { return o.Status == "paid" }
+
+func TestGrep(t *testing.T) {
+	checkOK(t, []int{1, 15, 2, 18}, testdeep.Grep(testdeep.Gt(10), []int{15, 18}))
+	checkOK(t, [4]int{1, 15, 2, 18}, testdeep.Grep(testdeep.Gt(10), []int{15, 18}))
+	checkOK(t, &[]int{1, 15, 2, 18}, testdeep.Grep(testdeep.Gt(10), []int{15, 18}))
+	checkOK(t, []int{1, 15, 2, 15}, testdeep.Grep(15, []int{15, 15}))
+	checkOK(t, []int{1, 2}, testdeep.Grep(testdeep.Gt(10), testdeep.Empty()))
+	checkOK(t, []int(nil), testdeep.Grep(testdeep.Gt(10), []int{}))
+
+	checkOK(t, grepOrders,
+		testdeep.Grep(isPaid, []grepOrder{
+			{Status: "paid", Amount: 12},
+			{Status: "paid", Amount: 8},
+		}))
+	checkOK(t, []interface{}{1, 3, 2},
+		testdeep.Grep(func(n int) bool { return n > 1 }, []interface{}{3, 2}))
+
+	checkError(t, grepOrders,
+		testdeep.Grep(isPaid, testdeep.ArrayEach(testdeep.Smuggle("Amount", testdeep.Gt(10)))),
+		expectedError{
+			Message:  mustBe("values differ"),
+			Path:     mustBe("DATA<Grep>[1].Amount"),
+			Got:      mustBe("8"),
+			Expected: mustBe("> 10"),
+		})
+
+	checkError(t, []interface{}{1, "foo", 2},
+		testdeep.Grep(func(n int) bool { return n > 1 }, []interface{}{2}),
+		expectedError{
+			Message:  mustBe("incompatible parameter type"),
+			Path:     mustBe("DATA[1]"),
+			Got:      mustBe("string"),
+			Expected: mustBe("int"),
+		})
+
+	checkError(t, []interface{}{nil},
+		testdeep.Grep(func(n int) bool { return n > 1 }, []interface{}{}),
+		expectedError{
+			Message:  mustBe("incompatible parameter type"),
+			Path:     mustBe("DATA[0]"),
+			Got:      mustBe("nil"),
+			Expected: mustBe("int"),
+		})
+
+	checkError(t, 12, testdeep.Grep(testdeep.Gt(10), []int{}),
+		expectedError{
+			Message:  mustBe("bad type"),
+			Path:     mustBe("DATA"),
+			Got:      mustBe("int"),
+			Expected: mustBe("Slice OR Array OR *Slice OR *Array"),
+		})
+
+	checkError(t, nil, testdeep.Grep(testdeep.Gt(10), []int{}),
+		expectedError{
+			Message:  mustBe("bad type"),
+			Path:     mustBe("DATA"),
+			Got:      mustBe("nil"),
+			Expected: mustBe("Slice OR Array OR *Slice OR *Array"),
+		})
+
+	checkError(t, (*[]int)(nil), testdeep.Grep(testdeep.Gt(10), []int{}),
+		expectedError{
+			Message:  mustBe("bad type"),
+			Path:     mustBe("DATA"),
+			Got:      mustBe("*[]int"),
+			Expected: mustBe("Slice OR Array OR *Slice OR *Array"),
+		})
+
+	//
+	// Bad usage
+	const usage = "Grep(FILTER_FUNC|FILTER_TESTDEEP_OPERATOR|FILTER_VALUE, TESTDEEP_OPERATOR|EXPECTED_VALUE): FILTER_FUNC must take only one argument and return bool"
+	test.CheckPanic(t, func() { testdeep.Grep(func() bool { return true }, 1) }, usage)
+	test.CheckPanic(t, func() { testdeep.Grep(func(a, b int) bool { return true }, 1) }, usage)
+	test.CheckPanic(t, func() { testdeep.Grep(func(a int) int { return 0 }, 1) }, usage)
+	test.CheckPanic(t, func() { testdeep.Grep(func(a ...int) bool { return true }, 1) }, usage)
+
+	//
+	// String
+	test.EqualStr(t, testdeep.Grep(testdeep.Gt(10), []int{}).String(), "Grep(> 10)")
+	test.EqualStr(t, testdeep.Grep(isPaid, []int{}).String(),
+		"Grep(func(testdeep_test.grepOrder) bool)")
+	test.EqualStr(t, testdeep.Grep(12, []int{}).String(), "Grep(12)")
+}
+
+func TestFirstLast(t *testing.T) {
+	checkOK(t, []int{1, 15, 2, 18}, testdeep.First(testdeep.Gt(10), 15))
+	checkOK(t, []int{1, 15, 2, 18}, testdeep.Last(testdeep.Gt(10), 18))
+	checkOK(t, &[4]int{1, 15, 2, 18}, testdeep.Last(testdeep.Lt(10), 2))
+
+	checkOK(t, grepOrders,
+		testdeep.First(isPaid, testdeep.Smuggle("Amount", testdeep.Gt(10))))
+	checkOK(t, grepOrders,
+		testdeep.Last(testdeep.Smuggle("Status", "paid"), grepOrder{Status: "paid", Amount: 8}))
+
+	checkError(t, grepOrders,
+		testdeep.Last(isPaid, testdeep.Smuggle("Amount", testdeep.Gt(10))),
+		expectedError{
+			Message:  mustBe("values differ"),
+			Path:     mustBe("DATA<Last>.Amount"),
+			Got:      mustBe("8"),
+			Expected: mustBe("> 10"),
+		})
+
+	checkError(t, []int{1, 15, 2, 18}, testdeep.First(testdeep.Gt(10), 18),
+		expectedError{
+			Message:  mustBe("values differ"),
+			Path:     mustBe("DATA<First>"),
+			Got:      mustBe("15"),
+			Expected: mustBe("18"),
+		})
+
+	checkError(t, []int{1, 2}, testdeep.First(testdeep.Gt(10), 18),
+		expectedError{
+			Message: mustBe("item not found"),
+			Path:    mustBe("DATA"),
+			Summary: mustContain("no item matches filter > 10"),
+		})
+
+	checkError(t, []int{}, testdeep.Last(12, 12),
+		expectedError{
+			Message: mustBe("item not found"),
+			Path:    mustBe("DATA"),
+			Summary: mustContain("no item matches filter 12"),
+		})
+
+	checkError(t, []interface{}{"foo"},
+		testdeep.Last(func(n int) bool { return n > 1 }, 12),
+		expectedError{
+			Message:  mustBe("incompatible parameter type"),
+			Path:     mustBe("DATA[0]"),
+			Got:      mustBe("string"),
+			Expected: mustBe("int"),
+		})
+
+	checkError(t, "str", testdeep.First(testdeep.Gt(10), 12),
+		expectedError{
+			Message:  mustBe("bad type"),
+			Path:     mustBe("DATA"),
+			Got:      mustBe("string"),
+			Expected: mustBe("Slice OR Array OR *Slice OR *Array"),
+		})
+
+	//
+	// Bad usage
+	test.CheckPanic(t, func() { testdeep.First(func() {}, 1) },
+		"First(FILTER_FUNC|FILTER_TESTDEEP_OPERATOR|FILTER_VALUE, TESTDEEP_OPERATOR|EXPECTED_VALUE): FILTER_FUNC must take only one argument and return bool")
+	test.CheckPanic(t, func() { testdeep.Last(func() {}, 1) },
+		"Last(FILTER_FUNC|FILTER_TESTDEEP_OPERATOR|FILTER_VALUE, TESTDEEP_OPERATOR|EXPECTED_VALUE): FILTER_FUNC must take only one argument and return bool")
+
+	//
+	// String
+	test.EqualStr(t, testdeep.First(testdeep.Gt(10), 1).String(), "First(> 10)")
+	test.EqualStr(t, testdeep.Last(isPaid, 1).String(),
+		"Last(func(testdeep_test.grepOrder) bool)")
+}
+
+func TestGrepTypeBehind(t *testing.T) {
+	equalTypes(t, testdeep.Grep(12, []int{}), nil)
+	equalTypes(t, testdeep.First(12, 12), nil)
+	equalTypes(t, testdeep.Last(12, 12), nil)
+}
@@ -0,0 +1,198 @@
+// Copyright (c) 2019, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package testdeep
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+
+	"github.com/maxatome/go-testdeep/internal/ctxerr"
+	"github.com/maxatome/go-testdeep/internal/dark"
+	"github.com/maxatome/go-testdeep/internal/jsonschema"
+)
+
+type tdJSONSchema struct {
+	BaseOKNil
+	schema *jsonschema.Schema
+	source string
+}
+
+var _ TestDeep = &tdJSONSchema{}
+
+// JSONSchema operator marshals data to JSON, then validates it
+// against the JSON Schema "schema". A subset of the draft 2020-12 is
+// handled, see below.
+//
+// "schema" can be:
+//   - a string containing a JSON Schema, if it starts with "{" (after
+//     optional spaces), or if it is "true" or "false";
+//   - a string containing the name of a file containing a JSON Schema;
+//   - a []byte containing a JSON Schema;
+//   - an io.Reader from which the JSON Schema is read.
+//
+// Data is marshaled using encoding/json package, so a
+// json.RawMessage is validated as is. A []byte is also considered as
+// a JSON document and validated as is, whereas a string is
+// marshaled, so validated as a JSON string:
+//
+//   Cmp(t, Person{Name: "Bob", Age: 42}, JSONSchema(`{
+//     "type": "object",
+//     "properties": {
+//       "name": {"type": "string", "minLength": 1},
+//       "age":  {"type": "integer", "minimum": 0}
+//     },
+//     "required": ["name"]
+//   }`)) // succeeds
+//
+//   Cmp(t, json.RawMessage(doc), JSONSchema("schemas/person.json"))
+//   Cmp(t, []byte(`{"name":"Bob"}`), JSONSchema("schemas/person.json"))
+//
+// "$ref" references are resolved in the schema itself (as in
+// "#/$defs/age" or "#anchor") and in other local files, relatively
+// to the file containing the reference, or to the current directory
+// if the schema does not come from a file.
+//
+// Handled keywords are: $ref, $anchor, type, enum, const,
+// multipleOf, maximum, exclusiveMaximum, minimum, exclusiveMinimum,
+// maxLength, minLength, pattern, format, prefixItems, items,
+// maxItems, minItems, uniqueItems, contains, maxContains,
+// minContains, properties, patternProperties, additionalProperties,
+// required, maxProperties, minProperties, dependentRequired,
+// dependentSchemas, propertyNames, allOf, anyOf, oneOf, not, if, then
+// and else. Other keywords are ignored. Only date-time, date, time,
+// email, ipv4, ipv6, uri and uuid formats are checked.
+//
+// Each violation is reported as a separate error, whose path is the
+// JSON pointer of the faulty value, as in "DATA/friends/0/age".
+//
+// JSONSchema panics if "schema" cannot be loaded.
+func JSONSchema(schema interface{}) TestDeep {
+	var (
+		s      *jsonschema.Schema
+		source string
+		err    error
+	)
+
+	switch sch := schema.(type) {
+	case string:
+		trimmed := strings.TrimSpace(sch)
+		if strings.HasPrefix(trimmed, "{") || trimmed == "true" || trimmed == "false" {
+			s, err = jsonschema.Parse([]byte(sch), jsonschema.Options{})
+			source = compactJSON([]byte(sch))
+		} else {
+			s, err = jsonschema.Load(sch, jsonschema.Options{})
+			source = fmt.Sprintf("%q", sch)
+		}
+
+	case []byte:
+		s, err = jsonschema.Parse(sch, jsonschema.Options{})
+		source = compactJSON(sch)
+
+	case io.Reader:
+		var b []byte
+		b, err = ioutil.ReadAll(sch)
+		if err == nil {
+			s, err = jsonschema.Parse(b, jsonschema.Options{})
+			source = compactJSON(b)
+		}
+
+	default:
+		panic("usage: JSONSchema(STRING_SCHEMA|STRING_FILENAME|[]byte|io.Reader)")
+	}
+
+	if err != nil {
+		panic("JSONSchema(): " + err.Error())
+	}
+
+	return &tdJSONSchema{
+		BaseOKNil: NewBaseOKNil(3),
+		schema:    s,
+		source:    source,
+	}
+}
+
+// compactJSON returns the compacted "b" JSON, truncated if too long.
+func compactJSON(b []byte) string {
+	var buf bytes.Buffer
+	if json.Compact(&buf, b) != nil {
+		buf.Reset()
+		buf.Write(bytes.TrimSpace(b))
+	}
+	if buf.Len() > 50 {
+		return buf.String()[:47] + "..."
+	}
+	return buf.String()
+}
+
+func (j *tdJSONSchema) Match(ctx ctxerr.Context, got reflect.Value) *ctxerr.Error {
+	gotIf, ok := dark.GetInterface(got, true)
+	if !ok {
+		if ctx.BooleanError {
+			return ctxerr.BooleanError
+		}
+		return ctx.CollectError(&ctxerr.Error{
+			Message: "cannot compare unexported field",
+			Summary: ctxerr.NewSummary("use JSONSchema() on surrounding struct instead"),
+		})
+	}
+
+	var (
+		value   interface{}
+		err     error
+		message string
+	)
+	// A []byte is a JSON document, as read from a file or a database
+	if b, isBytes := gotIf.([]byte); isBytes {
+		value, err = jsonschema.Decode(b)
+		message = "invalid JSON"
+	} else {
+		value, err = jsonschema.ToJSON(gotIf)
+		message = "json.Marshal failed"
+	}
+	if err != nil {
+		if ctx.BooleanError {
+			return ctxerr.BooleanError
+		}
+		return ctx.CollectError(&ctxerr.Error{
+			Message: message,
+			Summary: ctxerr.NewSummary(err.Error()),
+		})
+	}
+
+	violations := j.schema.Validate(value)
+	if len(violations) == 0 {
+		return nil
+	}
+	if ctx.BooleanError {
+		return ctxerr.BooleanError
+	}
+
+	for _, v := range violations {
+		vctx := ctx
+		if v.Path != "" {
+			vctx = ctx.AddCustomLevel(v.Path)
+		}
+		err := ctx.CollectError(&ctxerr.Error{
+			Context: vctx,
+			Message: fmt.Sprintf("violates %q JSON Schema keyword", v.Keyword),
+			Summary: ctxerr.NewSummary(v.Message),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (j *tdJSONSchema) String() string {
+	return "JSONSchema(" + j.source + ")"
+}
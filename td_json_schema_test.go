@@ -0,0 +1,184 @@
+// Copyright (c) 2019, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package testdeep_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/maxatome/go-testdeep"
+	"github.com/maxatome/go-testdeep/internal/ctxerr"
+	"github.com/maxatome/go-testdeep/internal/test"
+)
+
+const personSchema = `{
+  "type": "object",
+  "properties": {
+    "name": {"type": "string", "minLength": 1},
+    "age":  {"$ref": "#/$defs/age"}
+  },
+  "required": ["name"],
+  "$defs": {
+    "age": {"type": "integer", "minimum": 0}
+  }
+}`
+
+type jsonPerson struct {
+	Name string `json:"name,omitempty"`
+	Age  int    `json:"age"`
+}
+
+func TestJSONSchema(t *testing.T) {
+	checkOK(t, jsonPerson{Name: "Bob", Age: 42}, testdeep.JSONSchema(personSchema))
+	checkOK(t, map[string]interface{}{"name": "Bob"},
+		testdeep.JSONSchema([]byte(personSchema)))
+	checkOK(t, json.RawMessage(`{"name":"Bob","age":0}`),
+		testdeep.JSONSchema(strings.NewReader(personSchema)))
+	checkOK(t, []byte(`{"name":"Bob","age":0}`), testdeep.JSONSchema(personSchema))
+	checkOK(t, `{"name":"Bob"}`, testdeep.JSONSchema(`{"type":"string"}`))
+	checkOK(t, nil, testdeep.JSONSchema(`{"type":"null"}`))
+	checkOK(t, 12, testdeep.JSONSchema(`true`))
+
+	checkError(t, jsonPerson{Name: "Bob", Age: -1}, testdeep.JSONSchema(personSchema),
+		expectedError{
+			Message: mustBe(`violates "minimum" JSON Schema keyword`),
+			Path:    mustBe("DATA/age"),
+			Summary: mustContain("expected ≥ 0, got -1"),
+		})
+
+	checkError(t, 12, testdeep.JSONSchema(personSchema),
+		expectedError{
+			Message: mustBe(`violates "type" JSON Schema keyword`),
+			Path:    mustBe("DATA"),
+			Summary: mustContain("expected object, got integer"),
+		})
+
+	checkError(t, nil, testdeep.JSONSchema(`false`),
+		expectedError{
+			Message: mustBe(`violates "false" JSON Schema keyword`),
+			Path:    mustBe("DATA"),
+			Summary: mustContain("no value allowed"),
+		})
+
+	checkError(t, func() {}, testdeep.JSONSchema(`true`),
+		expectedError{
+			Message: mustBe("json.Marshal failed"),
+			Path:    mustBe("DATA"),
+			Summary: mustContain("unsupported type: func()"),
+		})
+
+	checkError(t, []byte(`{"name":`), testdeep.JSONSchema(personSchema),
+		expectedError{
+			Message: mustBe("invalid JSON"),
+			Path:    mustBe("DATA"),
+			Summary: mustContain("unexpected EOF"),
+		})
+
+	checkError(t, []byte(`{"name":"Bob","age":-1}`), testdeep.JSONSchema(personSchema),
+		expectedError{
+			Message: mustBe(`violates "minimum" JSON Schema keyword`),
+			Path:    mustBe("DATA/age"),
+			Summary: mustContain("expected ≥ 0, got -1"),
+		})
+
+	// Each violation is a separate error
+	err := testdeep.EqDeeplyError(
+		[]jsonPerson{{Name: "Bob", Age: 42}, {Age: -2}},
+		testdeep.JSONSchema(`{
+  "type": "array",
+  "items": {
+    "properties": {"age": {"type": "integer", "minimum": 0}},
+    "required": ["name"]
+  }
+}`))
+	if test.IsTrue(t, err != nil) {
+		var errors []string
+		for e := err.(*ctxerr.Error); e != nil; e = e.Next {
+			errors = append(errors, e.Context.Path.String()+": "+e.Message)
+		}
+		test.EqualStr(t, strings.Join(errors, "\n"),
+			`DATA/1: violates "required" JSON Schema keyword
+DATA/1/age: violates "minimum" JSON Schema keyword`)
+	}
+
+	//
+	// Schema in files, with references to other files
+	dir, err := ioutil.TempDir("", "testdeep")
+	if err != nil {
+		t.Fatalf("TempDir failed: %s", err)
+	}
+	defer os.RemoveAll(dir) // nolint: errcheck
+
+	for name, content := range map[string]string{
+		"person.json": `{
+  "type": "object",
+  "properties": {
+    "name":    {"type": "string"},
+    "friends": {"type": "array", "items": {"$ref": "friend.json"}}
+  }
+}`,
+		"friend.json": `{"$ref": "person.json", "required": ["name"]}`,
+	} {
+		err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+		if err != nil {
+			t.Fatalf("WriteFile failed: %s", err)
+		}
+	}
+
+	personFile := filepath.Join(dir, "person.json")
+	checkOK(t,
+		map[string]interface{}{
+			"name":    "Bob",
+			"friends": []interface{}{map[string]interface{}{"name": "Alice"}},
+		},
+		testdeep.JSONSchema(personFile))
+
+	checkError(t,
+		map[string]interface{}{
+			"friends": []interface{}{
+				map[string]interface{}{
+					"name":    "Alice",
+					"friends": []interface{}{map[string]interface{}{"name": 12}},
+				},
+			},
+		},
+		testdeep.JSONSchema(personFile),
+		expectedError{
+			Message: mustBe(`violates "type" JSON Schema keyword`),
+			Path:    mustBe("DATA/friends/0/friends/0/name"),
+			Summary: mustContain("expected string, got integer"),
+		})
+
+	//
+	// Bad usage
+	test.CheckPanic(t, func() { testdeep.JSONSchema(12) },
+		"usage: JSONSchema(STRING_SCHEMA|STRING_FILENAME|[]byte|io.Reader)")
+	test.CheckPanic(t, func() { testdeep.JSONSchema(`{"type":}`) },
+		"JSONSchema(): cannot parse JSON Schema: ")
+	test.CheckPanic(t, func() { testdeep.JSONSchema(`{"$ref":"#/unknown"}`) },
+		`JSONSchema(): cannot resolve $ref "#/unknown": `)
+	test.CheckPanic(t,
+		func() { testdeep.JSONSchema(filepath.Join(dir, "unknown.json")) },
+		"JSONSchema(): open ")
+
+	//
+	// String
+	test.EqualStr(t, testdeep.JSONSchema(" {\n  \"type\": \"string\"\n}").String(),
+		`JSONSchema({"type":"string"})`)
+	test.EqualStr(t, testdeep.JSONSchema(personSchema).String(),
+		`JSONSchema({"type":"object","properties":{"name":{"type":"...)`)
+	test.EqualStr(t, testdeep.JSONSchema(personFile).String(),
+		`JSONSchema("`+personFile+`")`)
+}
+
+func TestJSONSchemaTypeBehind(t *testing.T) {
+	equalTypes(t, testdeep.JSONSchema(`true`), nil)
+}
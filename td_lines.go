@@ -0,0 +1,90 @@
+// Copyright (c) 2019, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package testdeep
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/maxatome/go-testdeep/internal/ctxerr"
+	"github.com/maxatome/go-testdeep/internal/util"
+)
+
+type tdLines struct {
+	tdSmugglerBase
+}
+
+var _ TestDeep = &tdLines{}
+
+// Lines is a smuggler operator. It takes a string (or convertible),
+// error or fmt.Stringer interface (error interface is tested before
+// fmt.Stringer), splits it into lines and compares the resulting
+// []string to "val". The trailing new line, if any, does not produce
+// an empty last line and "\r\n" line endings are handled as "\n" ones.
+//
+// "val" can be a slice of strings:
+//   Lines([]string{"first line", "second line"})
+// as well as an other operator:
+//   Lines(Bag("second line", "first line"))
+//   Lines(ArrayEach(HasPrefix("INFO: ")))
+//
+// It is especially useful with CmpOutput and CmpLog functions.
+func Lines(val interface{}) TestDeep {
+	vval := reflect.ValueOf(val)
+	if vval.IsValid() {
+		l := tdLines{
+			tdSmugglerBase: newSmugglerBase(val),
+		}
+
+		if l.isTestDeeper {
+			return &l
+		}
+
+		if vval.Kind() == reflect.Slice &&
+			vval.Type().Elem().Kind() == reflect.String {
+			// Named slice or string types are converted to []string
+			lines := make([]string, vval.Len())
+			for i := range lines {
+				lines[i] = vval.Index(i).String()
+			}
+			l.expectedValue = reflect.ValueOf(lines)
+			return &l
+		}
+	}
+	panic("usage: Lines(TESTDEEP_OPERATOR|[]string)")
+}
+
+// splitLines splits "s" into lines, ignoring the trailing new line.
+func splitLines(s string) []string {
+	s = strings.TrimSuffix(s, "\n")
+	if s == "" {
+		return []string{}
+	}
+
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return lines
+}
+
+func (l *tdLines) Match(ctx ctxerr.Context, got reflect.Value) *ctxerr.Error {
+	str, err := getString(ctx, got)
+	if err != nil {
+		return err
+	}
+
+	return deepValueEqual(ctx.AddFunctionCall("lines"),
+		reflect.ValueOf(splitLines(str)), l.expectedValue)
+}
+
+func (l *tdLines) String() string {
+	if l.isTestDeeper {
+		return "lines: " + l.expectedValue.Interface().(TestDeep).String()
+	}
+	return "lines=" + util.ToString(l.expectedValue.Interface())
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2019, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package testdeep_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/maxatome/go-testdeep"
+	"github.com/maxatome/go-testdeep/internal/test"
+)
+
+func TestLines(t *testing.T) {
+	checkOK(t, "", testdeep.Lines([]string{}))
+	checkOK(t, "\n", testdeep.Lines([]string{}))
+	checkOK(t, "foo", testdeep.Lines([]string{"foo"}))
+	checkOK(t, "foo\n", testdeep.Lines([]string{"foo"}))
+	checkOK(t, "foo\n\nbar\n", testdeep.Lines([]string{"foo", "", "bar"}))
+	checkOK(t, "foo\r\nbar\r\n", testdeep.Lines([]string{"foo", "bar"}))
+
+	checkOK(t, "foo\nbar\n", testdeep.Lines(testdeep.Bag("bar", "foo")))
+	checkOK(t, "foo\nbar\n",
+		testdeep.Lines(testdeep.ArrayEach(testdeep.Len(3))))
+	checkOK(t, errors.New("foo\nbar"), testdeep.Lines([]string{"foo", "bar"}))
+	checkOK(t, bytes.NewBufferString("foo\nbar"),
+		testdeep.Lines([]string{"foo", "bar"}))
+
+	type MyLines []string
+	checkOK(t, "foo\nbar", testdeep.Lines(MyLines{"foo", "bar"}))
+	type MyLine string
+	checkOK(t, "foo\nbar", testdeep.Lines([]MyLine{"foo", "bar"}))
+
+	checkError(t, "foo\nbar\n", testdeep.Lines([]string{"foo", "baz"}),
+		expectedError{
+			Message:  mustBe("values differ"),
+			Path:     mustBe("lines(DATA)[1]"),
+			Got:      mustBe(`"bar"`),
+			Expected: mustBe(`"baz"`),
+		})
+
+	checkError(t, "foo\nbar\n", testdeep.Lines(testdeep.Len(1)),
+		expectedError{
+			Message:  mustBe("bad length"),
+			Path:     mustBe("lines(DATA)"),
+			Got:      mustBe("2"),
+			Expected: mustBe("1"),
+		})
+
+	checkError(t, 12, testdeep.Lines([]string{}),
+		expectedError{
+			Message:  mustBe("bad type"),
+			Path:     mustBe("DATA"),
+			Got:      mustBe("int"),
+			Expected: mustBe("string (convertible) OR fmt.Stringer OR error"),
+		})
+
+	checkError(t, nil, testdeep.Lines(testdeep.Empty()),
+		expectedError{
+			Message:  mustBe("values differ"),
+			Path:     mustBe("DATA"),
+			Got:      mustBe("nil"),
+			Expected: mustBe("lines: Empty()"),
+		})
+
+	//
+	// Bad usage
+	test.CheckPanic(t, func() { testdeep.Lines(nil) },
+		"usage: Lines(TESTDEEP_OPERATOR|[]string)")
+	test.CheckPanic(t, func() { testdeep.Lines("foo") },
+		"usage: Lines(TESTDEEP_OPERATOR|[]string)")
+	test.CheckPanic(t, func() { testdeep.Lines([]int{}) },
+		"usage: Lines(TESTDEEP_OPERATOR|[]string)")
+
+	//
+	// String
+	test.EqualStr(t, testdeep.Lines(testdeep.Empty()).String(), "lines: Empty()")
+	test.EqualStr(t, testdeep.Lines([]string{"foo"}).String(),
+		`lines=([]string) (len=1 cap=1) {
+ (string) (len=3) "foo"
+}`)
+}
+
+func TestLinesTypeBehind(t *testing.T) {
+	equalTypes(t, testdeep.Lines([]string{}), nil)
+}
@@ -10,6 +10,7 @@ import (
 	"bytes"
 	"reflect"
 
+	"github.com/maxatome/go-testdeep/internal/flat"
 	"github.com/maxatome/go-testdeep/internal/util"
 )
 
@@ -20,11 +21,7 @@ type tdList struct {
 
 func newList(items ...interface{}) (ret tdList) {
 	ret.BaseOKNil = NewBaseOKNil(4)
-	ret.items = make([]reflect.Value, len(items))
-
-	for idx, item := range items {
-		ret.items[idx] = reflect.ValueOf(item)
-	}
+	ret.items = flat.Values(items)
 	return
 }
 
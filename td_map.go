@@ -10,6 +10,7 @@ import (
 	"bytes"
 	"fmt"
 	"reflect"
+	"sort"
 
 	"github.com/maxatome/go-testdeep/helpers/tdutil"
 	"github.com/maxatome/go-testdeep/internal/ctxerr"
@@ -27,6 +28,7 @@ const (
 type tdMap struct {
 	tdExpectedType
 	expectedEntries []mapEntryInfo
+	opEntries       []mapEntryInfo // entries whose key is a TestDeep operator
 	kind            mapKind
 }
 
@@ -41,6 +43,14 @@ type mapEntryInfo struct {
 // is a map whose each key is the expected entry key and the
 // corresponding value the expected entry value (which can be a
 // TestDeep operator as well as a zero value.)
+//
+// A key can also be a TestDeep operator. In this case, it matches
+// all got keys it successfully compares against, and the values of
+// all these keys have to match the corresponding expected
+// value. Such an operator key must match at least one got key,
+// except for SubMapOf operator. Got keys matching an exact (non
+// operator) expected key are not compared against operator keys,
+// but a got key matched by several operator keys is an error.
 type MapEntries map[interface{}]interface{}
 
 func newMap(model interface{}, entries MapEntries, kind mapKind) *tdMap {
@@ -96,7 +106,8 @@ func (m *tdMap) populateExpectedEntries(entries MapEntries, expectedModel reflec
 
 	for key, expectedValue := range entries {
 		vkey := reflect.ValueOf(key)
-		if !vkey.Type().AssignableTo(keyType) {
+		_, isOpKey := key.(TestDeep)
+		if !isOpKey && !vkey.Type().AssignableTo(keyType) {
 			panic(fmt.Sprintf(
 				"expected key %s type mismatch: %s != model key type (%s)",
 				util.ToString(key),
@@ -130,10 +141,20 @@ func (m *tdMap) populateExpectedEntries(entries MapEntries, expectedModel reflec
 		}
 
 		entryInfo.key = vkey
+		if isOpKey {
+			m.opEntries = append(m.opEntries, entryInfo)
+			continue
+		}
 		m.expectedEntries = append(m.expectedEntries, entryInfo)
 		checkedEntries[vkey.Interface()] = true
 	}
 
+	// Operator keys are ordered by their string representation, so
+	// ambiguities and String() output do not depend on map iteration
+	sort.SliceStable(m.opEntries, func(i, j int) bool {
+		return util.ToString(m.opEntries[i].key) < util.ToString(m.opEntries[j].key)
+	})
+
 	// Check entries in model
 	if keysInModel == 0 {
 		return
@@ -164,6 +185,12 @@ func (m *tdMap) populateExpectedEntries(entries MapEntries, expectedModel reflec
 // During a match, all expected entries must be found and all data
 // entries must be expected to succeed.
 //
+// Keys of "expectedEntries" can be TestDeep operators, each one
+// matching one or several data keys (see MapEntries):
+//
+//   Cmp(t, map[string]int{"id": 42, "tmp-a": 1, "tmp-b": 2},
+//     Map(map[string]int{"id": 42}, MapEntries{Re("^tmp-"): Lt(3)})) // succeeds
+//
 // TypeBehind method returns the reflect.Type of "model".
 func Map(model interface{}, expectedEntries MapEntries) TestDeep {
 	return newMap(model, expectedEntries, allMap)
@@ -243,6 +270,15 @@ func (m *tdMap) Match(ctx ctxerr.Context, got reflect.Value) (err *ctxerr.Error)
 		foundKeys[entryInfo.key.Interface()] = true
 	}
 
+	if len(m.opEntries) > 0 {
+		var opNotFoundKeys []reflect.Value
+		opNotFoundKeys, err = m.matchOpEntries(ctx, got, foundKeys)
+		if err != nil {
+			return err
+		}
+		notFoundKeys = append(notFoundKeys, opNotFoundKeys...)
+	}
+
 	const errorMessage = "comparing hash keys of %%"
 
 	// For SuperMapOf we don't care about extra keys
@@ -312,6 +348,82 @@ func (m *tdMap) Match(ctx ctxerr.Context, got reflect.Value) (err *ctxerr.Error)
 	})
 }
 
+// matchOpEntries matches got keys not already in "foundKeys" against
+// operator keys, then the corresponding values against the expected
+// ones. Matched got keys are added to "foundKeys". The operator keys
+// that match no got key are returned.
+func (m *tdMap) matchOpEntries(ctx ctxerr.Context, got reflect.Value, foundKeys map[interface{}]bool) ([]reflect.Value, *ctxerr.Error) {
+	var (
+		notFoundKeys []reflect.Value
+		claimed      = make([]bool, len(m.opEntries))
+	)
+
+keys:
+	for _, k := range tdutil.MapSortedKeys(got) {
+		if foundKeys[k.Interface()] {
+			continue
+		}
+
+		entryIdx := -1
+		for idx, entryInfo := range m.opEntries {
+			if !deepValueEqualOK(k, entryInfo.key) {
+				continue
+			}
+
+			if entryIdx >= 0 {
+				if ctx.BooleanError {
+					return nil, ctxerr.BooleanError
+				}
+				err := ctx.CollectError(&ctxerr.Error{
+					Message: "ambiguous key",
+					Summary: ctxerr.ErrorSummaryItems{
+						{
+							Label: "got key",
+							Value: util.ToString(k),
+						},
+						{
+							Label: "matched by",
+							Value: util.ToString(m.opEntries[entryIdx].key),
+						},
+						{
+							Label: "and by",
+							Value: util.ToString(entryInfo.key),
+						},
+					},
+				})
+				if err != nil {
+					return nil, err
+				}
+
+				// Errors are accumulated, consider this key as handled
+				claimed[entryIdx] = true
+				claimed[idx] = true
+				foundKeys[k.Interface()] = true
+				continue keys
+			}
+			entryIdx = idx
+		}
+		if entryIdx < 0 {
+			continue
+		}
+
+		err := deepValueEqual(ctx.AddMapKey(k),
+			got.MapIndex(k), m.opEntries[entryIdx].expected)
+		if err != nil {
+			return nil, err
+		}
+		claimed[entryIdx] = true
+		foundKeys[k.Interface()] = true
+	}
+
+	for idx, entryInfo := range m.opEntries {
+		if !claimed[idx] {
+			notFoundKeys = append(notFoundKeys, entryInfo.key)
+		}
+	}
+	return notFoundKeys, nil
+}
+
 func (m *tdMap) String() string {
 	buf := &bytes.Buffer{}
 
@@ -322,15 +434,17 @@ func (m *tdMap) String() string {
 
 	buf.WriteString(m.expectedTypeStr())
 
-	if len(m.expectedEntries) == 0 {
+	if len(m.expectedEntries) == 0 && len(m.opEntries) == 0 {
 		buf.WriteString("{}")
 	} else {
 		buf.WriteString("{\n")
 
-		for _, entryInfo := range m.expectedEntries {
-			fmt.Fprintf(buf, "  %s: %s,\n", // nolint: errcheck
-				util.ToString(entryInfo.key),
-				util.ToString(entryInfo.expected))
+		for _, entries := range [...][]mapEntryInfo{m.expectedEntries, m.opEntries} {
+			for _, entryInfo := range entries {
+				fmt.Fprintf(buf, "  %s: %s,\n", // nolint: errcheck
+					util.ToString(entryInfo.key),
+					util.ToString(entryInfo.expected))
+			}
 		}
 
 		buf.WriteByte('}')
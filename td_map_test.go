@@ -356,6 +356,106 @@ func TestMap(t *testing.T) {
 })`)
 }
 
+func TestMapOperatorKeys(t *testing.T) {
+	gotMap := map[string]int{"id": 12, "tmp-a": 1, "tmp-b": 2, "tmp-c": 3}
+
+	checkOK(t, gotMap,
+		testdeep.Map(map[string]int{"id": 12}, testdeep.MapEntries{
+			testdeep.Re("^tmp-"): testdeep.Between(1, 3),
+		}))
+	checkOK(t, gotMap,
+		testdeep.Map(map[string]int{}, testdeep.MapEntries{
+			"id":                 12,
+			testdeep.Re("^tmp-"): testdeep.Between(1, 3),
+		}))
+	checkOK(t, gotMap,
+		testdeep.SuperMapOf(map[string]int{}, testdeep.MapEntries{
+			testdeep.Re("^tmp-[ab]"): testdeep.Lt(3),
+		}))
+	checkOK(t, gotMap,
+		testdeep.SubMapOf(map[string]int{"id": 12}, testdeep.MapEntries{
+			testdeep.Re("^tmp-"):   testdeep.Gt(0),
+			testdeep.Re("^other-"): 0,
+		}))
+
+	// Exact keys take precedence over operator keys
+	checkOK(t, gotMap,
+		testdeep.Map(map[string]int{"id": 12, "tmp-c": 3}, testdeep.MapEntries{
+			testdeep.Re("^tmp-"): testdeep.Lt(3),
+		}))
+
+	checkOK(t, map[int]string{1: "a", 12: "b", 15: "c"},
+		testdeep.Map(map[int]string{}, testdeep.MapEntries{
+			testdeep.Lt(10):  "a",
+			testdeep.Gte(10): testdeep.Re("^[bc]$"),
+		}))
+
+	checkError(t, gotMap,
+		testdeep.Map(map[string]int{"id": 12}, testdeep.MapEntries{
+			testdeep.Re("^tmp-"): testdeep.Lt(3),
+		}),
+		expectedError{
+			Message:  mustBe("values differ"),
+			Path:     mustBe(`DATA["tmp-c"]`),
+			Got:      mustBe("3"),
+			Expected: mustBe("< 3"),
+		})
+
+	checkError(t, gotMap,
+		testdeep.Map(map[string]int{"id": 12}, testdeep.MapEntries{
+			testdeep.Re("^tmp-[ab]"): testdeep.Lt(3),
+		}),
+		expectedError{
+			Message: mustBe("comparing hash keys of %%"),
+			Path:    mustBe("DATA"),
+			Summary: mustBe(`Extra key: ("tmp-c")`),
+		})
+
+	checkError(t, gotMap,
+		testdeep.SuperMapOf(map[string]int{"id": 12}, testdeep.MapEntries{
+			testdeep.Re("^other-"): 0,
+		}),
+		expectedError{
+			Message: mustBe("comparing hash keys of %%"),
+			Path:    mustBe("DATA"),
+			Summary: mustContain(`Missing key: (`),
+		})
+
+	checkError(t, gotMap,
+		testdeep.Map(map[string]int{}, testdeep.MapEntries{
+			testdeep.Re("^tmp-[ab]"): testdeep.Gt(0),
+			testdeep.Re("^tmp-[bc]"): testdeep.Gt(0),
+			"id":                     12,
+		}),
+		expectedError{
+			Message: mustBe("ambiguous key"),
+			Path:    mustBe("DATA"),
+			Summary: mustBe("   got key: \"tmp-b\"\nmatched by: ^tmp-[ab]\n    and by: ^tmp-[bc]"),
+		})
+
+	//
+	// Bad usage
+	test.CheckPanic(t,
+		func() {
+			testdeep.Map(map[string]int{}, testdeep.MapEntries{testdeep.Re("x"): "bad"})
+		},
+		"value type mismatch: string != model key type (int)")
+
+	//
+	// String
+	test.EqualStr(t,
+		testdeep.Map(map[string]int{}, testdeep.MapEntries{
+			"id":                 12,
+			testdeep.Re("^tmp-"): 1,
+			testdeep.Re("^old-"): 2,
+		}).String(),
+		`map[string]int{
+  "id": 12,
+  ^old-: 2,
+  ^tmp-: 1,
+}`)
+}
+
 func TestMapTypeBehind(t *testing.T) {
 	type MyMap map[string]int
 
@@ -0,0 +1,244 @@
+// Copyright (c) 2019, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package testdeep
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/maxatome/go-testdeep/internal/ctxerr"
+	"github.com/maxatome/go-testdeep/internal/types"
+	"github.com/maxatome/go-testdeep/internal/util"
+)
+
+// seqItems returns the items of "got": the items of an array or a
+// slice, or the runes of a string. If "got" is none of them, it
+// returns false and the error to return.
+func seqItems(ctx ctxerr.Context, got reflect.Value) ([]reflect.Value, bool, *ctxerr.Error) {
+	switch got.Kind() {
+	case reflect.Array, reflect.Slice:
+		items := make([]reflect.Value, got.Len())
+		for idx := range items {
+			items[idx] = got.Index(idx)
+		}
+		return items, true, nil
+
+	case reflect.String:
+		var items []reflect.Value
+		for _, r := range got.String() {
+			items = append(items, reflect.ValueOf(r))
+		}
+		return items, true, nil
+	}
+
+	if ctx.BooleanError {
+		return nil, false, ctxerr.BooleanError
+	}
+	var gotType types.RawString
+	if got.IsValid() {
+		gotType = types.RawString(got.Type().String())
+	} else {
+		gotType = "nil"
+	}
+	return nil, false, ctx.CollectError(&ctxerr.Error{
+		Message:  "bad type",
+		Got:      gotType,
+		Expected: types.RawString("Array, Slice or string"),
+	})
+}
+
+// seqExpected returns the expected items, untyped nil ones being
+// replaced by typed nils if "got" items can be nil.
+func seqExpected(got reflect.Value, expected []reflect.Value) []reflect.Value {
+	if got.Kind() == reflect.String {
+		return expected
+	}
+
+	switch elemType := got.Type().Elem(); elemType.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Interface,
+		reflect.Map, reflect.Ptr, reflect.Slice:
+		var items []reflect.Value
+		for idx, item := range expected {
+			if !item.IsValid() {
+				if items == nil {
+					items = make([]reflect.Value, len(expected))
+					copy(items, expected)
+				}
+				items[idx] = reflect.Zero(elemType)
+			}
+		}
+		if items != nil {
+			return items
+		}
+	}
+	return expected
+}
+
+// seqNotFound reports that the "expected" sequence was not
+// found. "indexes" contains the got indexes of the longest matched
+// prefix of "expected" and "stopped" explains where matching stopped.
+func seqNotFound(ctx ctxerr.Context, message string,
+	expected []reflect.Value, indexes []int, stopped string) *ctxerr.Error {
+	if ctx.BooleanError {
+		return ctxerr.BooleanError
+	}
+
+	matched := fmt.Sprintf("%d of %d items", len(indexes), len(expected))
+	if len(indexes) > 0 {
+		strIndexes := make([]string, len(indexes))
+		for i, idx := range indexes {
+			strIndexes[i] = strconv.Itoa(idx)
+		}
+		matched += " at indexes " + strings.Join(strIndexes, ", ")
+	}
+
+	return ctx.CollectError(&ctxerr.Error{
+		Message: message,
+		Summary: ctxerr.ErrorSummaryItems{
+			{
+				Label: "longest matched prefix",
+				Value: matched,
+			},
+			{
+				Label:       "stopped at",
+				Value:       fmt.Sprintf("item #%d %s", len(indexes), util.ToString(expected[len(indexes)])),
+				Explanation: stopped,
+			},
+		},
+	})
+}
+
+type tdSubsequence struct {
+	tdList
+}
+
+var _ TestDeep = &tdSubsequence{}
+
+// Subsequence operator compares the contents of an array, a slice or
+// a string (its runes) against "expectedItems". It succeeds if all
+// "expectedItems" are found in data in the same order, other items
+// possibly being interleaved. "expectedItems" can be operators:
+//
+//   Cmp(t, []string{"A", "B", "C", "D", "F"}, Subsequence("A", "C", "F")) // succeeds
+//   Cmp(t, []int{1, 15, 2, 18}, Subsequence(Gt(10), 18))                 // succeeds
+//   Cmp(t, "Hello World!", Subsequence('H', 'W', '!'))                   // succeeds
+//
+// On failure, the longest matched prefix of "expectedItems" is
+// reported, as well as the item that was not found.
+//
+// See also ContainsSeq operator for a contiguous sequence.
+func Subsequence(expectedItems ...interface{}) TestDeep {
+	return &tdSubsequence{
+		tdList: newList(expectedItems...),
+	}
+}
+
+func (s *tdSubsequence) Match(ctx ctxerr.Context, got reflect.Value) *ctxerr.Error {
+	items, ok, err := seqItems(ctx, got)
+	if !ok {
+		return err
+	}
+	expected := seqExpected(got, s.items)
+
+	// Matching each expected item against the first matching got item
+	// gives the longest matched prefix
+	indexes := make([]int, 0, len(expected))
+	idx := 0
+	for _, expectedItem := range expected {
+		for ; idx < len(items); idx++ {
+			if deepValueEqualOK(items[idx], expectedItem) {
+				break
+			}
+		}
+		if idx == len(items) {
+			break
+		}
+		indexes = append(indexes, idx)
+		idx++
+	}
+
+	if len(indexes) == len(expected) {
+		return nil
+	}
+
+	stopped := "not found"
+	if len(indexes) > 0 {
+		stopped = fmt.Sprintf("not found after index %d", indexes[len(indexes)-1])
+	}
+	return seqNotFound(ctx, "subsequence not found", expected, indexes, stopped)
+}
+
+type tdContainsSeq struct {
+	tdList
+}
+
+var _ TestDeep = &tdContainsSeq{}
+
+// ContainsSeq operator checks that an array, a slice or a string
+// (its runes) contains "expectedItems" as a contiguous
+// sequence. "expectedItems" can be operators:
+//
+//   Cmp(t, []string{"A", "B", "C", "D"}, ContainsSeq("B", "C")) // succeeds
+//   Cmp(t, []string{"A", "B", "C", "D"}, ContainsSeq("B", "D")) // fails
+//   Cmp(t, []int{1, 15, 2, 18}, ContainsSeq(Gt(10), Lt(10)))   // succeeds
+//   Cmp(t, "Hello World!", ContainsSeq('W', 'o'))              // succeeds
+//
+// On failure, the longest matched prefix of "expectedItems" is
+// reported, as well as where matching stopped.
+//
+// See also Subsequence operator for a non-contiguous sequence.
+func ContainsSeq(expectedItems ...interface{}) TestDeep {
+	return &tdContainsSeq{
+		tdList: newList(expectedItems...),
+	}
+}
+
+func (c *tdContainsSeq) Match(ctx ctxerr.Context, got reflect.Value) *ctxerr.Error {
+	items, ok, err := seqItems(ctx, got)
+	if !ok {
+		return err
+	}
+	expected := seqExpected(got, c.items)
+
+	if len(expected) == 0 {
+		return nil
+	}
+
+	bestStart, bestLen := 0, 0
+	for start := range items {
+		l := 0
+		for l < len(expected) && start+l < len(items) &&
+			deepValueEqualOK(items[start+l], expected[l]) {
+			l++
+		}
+		if l == len(expected) {
+			return nil
+		}
+		if l > bestLen {
+			bestStart, bestLen = start, l
+		}
+	}
+
+	indexes := make([]int, bestLen)
+	for i := range indexes {
+		indexes[i] = bestStart + i
+	}
+
+	var stopped string
+	switch next := bestStart + bestLen; {
+	case bestLen == 0:
+		stopped = "not found"
+	case next == len(items):
+		stopped = "end of data reached"
+	default:
+		stopped = fmt.Sprintf("does not match index %d: %s",
+			next, util.ToString(items[next]))
+	}
+	return seqNotFound(ctx, "sequence not found", expected, indexes, stopped)
+}
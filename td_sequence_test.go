@@ -0,0 +1,144 @@
+// Copyright (c) 2019, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package testdeep_test
+
+import (
+	"testing"
+
+	"github.com/maxatome/go-testdeep"
+	"github.com/maxatome/go-testdeep/internal/test"
+)
+
+func TestSubsequence(t *testing.T) {
+	events := []string{"A", "B", "C", "D", "E", "F"}
+
+	checkOK(t, events, testdeep.Subsequence("A", "C", "F"))
+	checkOK(t, events, testdeep.Subsequence("A", "B", "C", "D", "E", "F"))
+	checkOK(t, events, testdeep.Subsequence())
+	checkOK(t, [4]int{1, 15, 2, 18}, testdeep.Subsequence(testdeep.Gt(10), 18))
+	checkOK(t, []int{1, 15, 2, 18}, testdeep.Subsequence(15, testdeep.Lt(10)))
+	checkOK(t, "Hello World!", testdeep.Subsequence('H', 'W', '!'))
+	checkOK(t, []*int{nil, nil}, testdeep.Subsequence(nil, nil))
+	checkOK(t, []int(nil), testdeep.Subsequence())
+
+	checkError(t, events, testdeep.Subsequence("A", "C", "B"),
+		expectedError{
+			Message: mustBe("subsequence not found"),
+			Path:    mustBe("DATA"),
+			Summary: mustContain("2 of 3 items at indexes 0, 2"),
+		})
+	checkError(t, events, testdeep.Subsequence("A", "C", "B"),
+		expectedError{
+			Message: mustBe("subsequence not found"),
+			Path:    mustBe("DATA"),
+			Summary: mustContain(`item #2 "B"`),
+		})
+	checkError(t, events, testdeep.Subsequence("A", "C", "B"),
+		expectedError{
+			Message: mustBe("subsequence not found"),
+			Path:    mustBe("DATA"),
+			Summary: mustContain("not found after index 2"),
+		})
+
+	checkError(t, events, testdeep.Subsequence("Z"),
+		expectedError{
+			Message: mustBe("subsequence not found"),
+			Path:    mustBe("DATA"),
+			Summary: mustContain("0 of 1 items"),
+		})
+
+	checkError(t, []int{}, testdeep.Subsequence(testdeep.Gt(10)),
+		expectedError{
+			Message: mustBe("subsequence not found"),
+			Path:    mustBe("DATA"),
+			Summary: mustContain("item #0 > 10"),
+		})
+
+	checkError(t, 12, testdeep.Subsequence(12),
+		expectedError{
+			Message:  mustBe("bad type"),
+			Path:     mustBe("DATA"),
+			Got:      mustBe("int"),
+			Expected: mustBe("Array, Slice or string"),
+		})
+
+	checkError(t, nil, testdeep.Subsequence(12),
+		expectedError{
+			Message:  mustBe("bad type"),
+			Path:     mustBe("DATA"),
+			Got:      mustBe("nil"),
+			Expected: mustBe("Array, Slice or string"),
+		})
+
+	//
+	// String
+	test.EqualStr(t, testdeep.Subsequence(1).String(), "Subsequence(1)")
+	test.EqualStr(t, testdeep.Subsequence().String(), "Subsequence()")
+}
+
+func TestContainsSeq(t *testing.T) {
+	events := []string{"A", "B", "C", "D", "B", "E"}
+
+	checkOK(t, events, testdeep.ContainsSeq("B", "C"))
+	checkOK(t, events, testdeep.ContainsSeq("B", "E"))
+	checkOK(t, events, testdeep.ContainsSeq("A", "B", "C", "D", "B", "E"))
+	checkOK(t, events, testdeep.ContainsSeq())
+	checkOK(t, [4]int{1, 15, 2, 18}, testdeep.ContainsSeq(testdeep.Gt(10), testdeep.Lt(10)))
+	checkOK(t, "Hello World!", testdeep.ContainsSeq('W', 'o'))
+	checkOK(t, []interface{}{1, nil, 2}, testdeep.ContainsSeq(nil, 2))
+
+	checkError(t, events, testdeep.ContainsSeq("B", "C", "E"),
+		expectedError{
+			Message: mustBe("sequence not found"),
+			Path:    mustBe("DATA"),
+			Summary: mustContain("2 of 3 items at indexes 1, 2"),
+		})
+	checkError(t, events, testdeep.ContainsSeq("B", "C", "E"),
+		expectedError{
+			Message: mustBe("sequence not found"),
+			Path:    mustBe("DATA"),
+			Summary: mustContain(`does not match index 3: "D"`),
+		})
+
+	checkError(t, events, testdeep.ContainsSeq("B", "E", "F"),
+		expectedError{
+			Message: mustBe("sequence not found"),
+			Path:    mustBe("DATA"),
+			Summary: mustContain("end of data reached"),
+		})
+
+	checkError(t, events, testdeep.ContainsSeq("Z", "A"),
+		expectedError{
+			Message: mustBe("sequence not found"),
+			Path:    mustBe("DATA"),
+			Summary: mustContain("0 of 2 items"),
+		})
+
+	checkError(t, "Hello", testdeep.ContainsSeq('l', 'x'),
+		expectedError{
+			Message: mustBe("sequence not found"),
+			Path:    mustBe("DATA"),
+			Summary: mustContain("1 of 2 items at indexes 2"),
+		})
+
+	checkError(t, map[string]int{}, testdeep.ContainsSeq(12),
+		expectedError{
+			Message:  mustBe("bad type"),
+			Path:     mustBe("DATA"),
+			Got:      mustBe("map[string]int"),
+			Expected: mustBe("Array, Slice or string"),
+		})
+
+	//
+	// String
+	test.EqualStr(t, testdeep.ContainsSeq(1).String(), "ContainsSeq(1)")
+}
+
+func TestSequenceTypeBehind(t *testing.T) {
+	equalTypes(t, testdeep.Subsequence(1), nil)
+	equalTypes(t, testdeep.ContainsSeq(1), nil)
+}
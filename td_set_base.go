@@ -11,6 +11,7 @@ import (
 	"reflect"
 
 	"github.com/maxatome/go-testdeep/internal/ctxerr"
+	"github.com/maxatome/go-testdeep/internal/flat"
 	"github.com/maxatome/go-testdeep/internal/types"
 	"github.com/maxatome/go-testdeep/internal/util"
 )
@@ -41,9 +42,7 @@ func newSetBase(kind setKind, ignoreDups bool) tdSetBase {
 }
 
 func (s *tdSetBase) Add(items ...interface{}) {
-	for _, item := range items {
-		s.expectedItems = append(s.expectedItems, reflect.ValueOf(item))
-	}
+	s.expectedItems = append(s.expectedItems, flat.Values(items)...)
 }
 
 func (s *tdSetBase) Match(ctx ctxerr.Context, got reflect.Value) *ctxerr.Error {
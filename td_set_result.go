@@ -22,6 +22,7 @@ type tdSetResultKind uint8
 const (
 	itemsSetResult tdSetResultKind = iota
 	keysSetResult
+	filesSetResult
 )
 
 // Implements fmt.Stringer.
@@ -31,6 +32,8 @@ func (k tdSetResultKind) String() string {
 		return "item"
 	case keysSetResult:
 		return "key"
+	case filesSetResult:
+		return "file"
 	default:
 		return "?"
 	}
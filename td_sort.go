@@ -0,0 +1,369 @@
+// Copyright (c) 2019, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package testdeep
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/maxatome/go-testdeep/helpers/tdutil"
+	"github.com/maxatome/go-testdeep/internal/ctxerr"
+	"github.com/maxatome/go-testdeep/internal/dark"
+	"github.com/maxatome/go-testdeep/internal/types"
+	"github.com/maxatome/go-testdeep/internal/util"
+)
+
+// sortKey is one sorting criterion of Sorted and Sort operators.
+type sortKey struct {
+	path string // empty means the item itself
+	desc bool
+	fn   func(interface{}) (smuggleValue, error)
+}
+
+type sortKeys []sortKey
+
+// newSortKeys parses "keys" as passed to Sorted and Sort
+// operators. An empty "keys" means the items themselves in ascending
+// order.
+func newSortKeys(keys []string) (sortKeys, error) {
+	if len(keys) == 0 {
+		return sortKeys{{}}, nil
+	}
+
+	sk := make(sortKeys, len(keys))
+	for i, key := range keys {
+		if strings.HasPrefix(key, "-") {
+			sk[i].desc = true
+			key = key[1:]
+		}
+		if key != "" {
+			fn, err := buildStructFieldFn(key)
+			if err != nil {
+				return nil, err
+			}
+			sk[i].path = key
+			sk[i].fn = fn
+		}
+	}
+	return sk, nil
+}
+
+// values returns the values of "item" corresponding to each sort key.
+func (sk sortKeys) values(item reflect.Value) ([]reflect.Value, error) {
+	vals := make([]reflect.Value, len(sk))
+	for i, key := range sk {
+		if key.fn == nil {
+			vals[i] = item
+			continue
+		}
+		smv, err := key.fn(dark.MustGetInterface(item))
+		if err != nil {
+			return nil, err
+		}
+		vals[i] = smv.Value
+	}
+	return vals, nil
+}
+
+// cmp returns -1 if "a" must come before "b", 1 if "a" must come
+// after "b", or 0 if their order does not matter. "a" and "b" are
+// both returned by values method.
+func (sk sortKeys) cmp(a, b []reflect.Value) int {
+	for i, key := range sk {
+		vals := []reflect.Value{a[i], b[i]}
+		s := tdutil.SortableValues(vals)
+
+		var r int
+		if s.Less(0, 1) {
+			r = -1
+		} else if s.Less(1, 0) {
+			r = 1
+		} else {
+			continue
+		}
+
+		if key.desc {
+			return -r
+		}
+		return r
+	}
+	return 0
+}
+
+// allValues returns the sort key values of all items of "got". On
+// error, it also returns the index of the faulty item.
+func (sk sortKeys) allValues(got reflect.Value) ([][]reflect.Value, int, error) {
+	all := make([][]reflect.Value, got.Len())
+	for i := range all {
+		vals, err := sk.values(got.Index(i))
+		if err != nil {
+			return nil, i, err
+		}
+		all[i] = vals
+	}
+	return all, 0, nil
+}
+
+func (sk sortKeys) String() string {
+	if len(sk) == 1 && sk[0].path == "" {
+		if sk[0].desc {
+			return "descending order"
+		}
+		return "ascending order"
+	}
+
+	parts := make([]string, len(sk))
+	for i, key := range sk {
+		order := "ascending "
+		if key.desc {
+			order = "descending "
+		}
+		if key.path == "" {
+			parts[i] = order + "item"
+		} else {
+			parts[i] = order + key.path
+		}
+	}
+	return strings.Join(parts, ", then ")
+}
+
+// keysString returns the Go representation of original keys.
+func keysString(keys []string) string {
+	quoted := make([]string, len(keys))
+	for i, key := range keys {
+		quoted[i] = fmt.Sprintf("%q", key)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// checkSortable checks "got" is an array or a slice. If not, it
+// returns false and the error to return.
+func checkSortable(ctx ctxerr.Context, got reflect.Value) (bool, *ctxerr.Error) {
+	switch got.Kind() {
+	case reflect.Slice, reflect.Array:
+		return true, nil
+	}
+	if ctx.BooleanError {
+		return false, ctxerr.BooleanError
+	}
+	var gotKind types.RawString
+	if got.IsValid() {
+		gotKind = types.RawString(got.Kind().String())
+	} else {
+		gotKind = "nil"
+	}
+	return false, ctx.CollectError(&ctxerr.Error{
+		Message:  "bad kind",
+		Got:      gotKind,
+		Expected: types.RawString("slice OR array"),
+	})
+}
+
+// fieldError reports the error "err" that occurred when extracting
+// sort keys of item at index "idx".
+func fieldError(ctx ctxerr.Context, idx int, err error) *ctxerr.Error {
+	if ctx.BooleanError {
+		return ctxerr.BooleanError
+	}
+	return ctx.CollectError(&ctxerr.Error{
+		Context: ctx.AddArrayIndex(idx),
+		Message: "cannot get sort key",
+		Summary: ctxerr.NewSummary(err.Error()),
+	})
+}
+
+type tdSorted struct {
+	BaseOKNil
+	keys     []string
+	sortKeys sortKeys
+}
+
+var _ TestDeep = &tdSorted{}
+
+// Sorted operator checks that data, an array or a slice, is
+// sorted. Without "keys", items are compared themselves, in ascending
+// order, using the tdutil.SortableValues ordering rules.
+//
+// Each key is a fields-path through structs, as accepted by Smuggle
+// operator, optionally prefixed by "-" to check for a descending
+// order. The key "-" alone means the item itself in descending
+// order. When several keys are passed, next one is used only if
+// items are equal for the previous ones:
+//
+//   Cmp(t, []int{1, 2, 2, 5}, Sorted())    // succeeds
+//   Cmp(t, []int{5, 2, 2, 1}, Sorted("-")) // succeeds
+//   Cmp(t, persons, Sorted("-Age", "Name")) // older first, then by name
+//   Cmp(t, persons, Sorted("Address.City")) // using a fields-path
+//
+// On failure, the first out-of-order pair of items is reported.
+//
+// A nil slice, as []int(nil), is sorted. But an untyped nil is not a
+// slice, so it fails with a "bad kind" error.
+func Sorted(keys ...string) TestDeep {
+	sk, err := newSortKeys(keys)
+	if err != nil {
+		panic("Sorted(KEYS...): " + err.Error())
+	}
+	return &tdSorted{
+		BaseOKNil: NewBaseOKNil(3),
+		keys:      keys,
+		sortKeys:  sk,
+	}
+}
+
+func (s *tdSorted) Match(ctx ctxerr.Context, got reflect.Value) *ctxerr.Error {
+	if ok, err := checkSortable(ctx, got); !ok {
+		return err
+	}
+
+	all, idx, err := s.sortKeys.allValues(got)
+	if err != nil {
+		return fieldError(ctx, idx, err)
+	}
+
+	for i := 1; i < len(all); i++ {
+		if s.sortKeys.cmp(all[i-1], all[i]) > 0 {
+			if ctx.BooleanError {
+				return ctxerr.BooleanError
+			}
+			return ctx.CollectError(&ctxerr.Error{
+				Message: "items are not sorted",
+				Got: types.RawString(fmt.Sprintf("item #%d: %s\nitem #%d: %s",
+					i-1, util.ToString(got.Index(i-1)),
+					i, util.ToString(got.Index(i)))),
+				Expected: types.RawString(s.sortKeys.String()),
+			})
+		}
+	}
+	return nil
+}
+
+func (s *tdSorted) String() string {
+	return "Sorted(" + keysString(s.keys) + ")"
+}
+
+type tdSort struct {
+	tdSmugglerBase
+	keys     []string
+	sortKeys sortKeys
+}
+
+var _ TestDeep = &tdSort{}
+
+// Sort is a smuggler operator. It takes an array or a slice, sorts a
+// copy of it, then compares the sorted copy to "expectedValue". Data
+// is never modified.
+//
+// "keys" can be nil to sort items themselves in ascending order, a
+// string or a []string. Each key follows the same rules as in Sorted
+// operator: a fields-path through structs optionally prefixed by "-"
+// for a descending order, "-" alone meaning the item itself in
+// descending order. The sort is stable.
+//
+// "expectedValue" can be an array or a slice, as well as an other
+// operator:
+//
+//   Cmp(t, []int{3, 1, 2}, Sort(nil, []int{1, 2, 3}))      // succeeds
+//   Cmp(t, []int{3, 1, 2}, Sort("-", []int{3, 2, 1}))      // succeeds
+//   Cmp(t, persons, Sort("Name", ArrayEach(Isa(Person{})))) // succeeds
+//   Cmp(t, persons, Sort([]string{"-Age", "Name"}, expected))
+//
+// Items must be copyable, so structs with unexported fields, as well
+// as channels and functions cannot be sorted.
+func Sort(keys interface{}, expectedValue interface{}) TestDeep {
+	const usage = "Sort(nil|STRING|[]STRING, TESTDEEP_OPERATOR|SLICE|ARRAY)"
+
+	var strKeys []string
+	switch k := keys.(type) {
+	case nil:
+	case string:
+		strKeys = []string{k}
+	case []string:
+		strKeys = k
+	default:
+		panic("usage: " + usage)
+	}
+
+	s := tdSort{
+		tdSmugglerBase: newSmugglerBase(expectedValue),
+		keys:           strKeys,
+	}
+
+	if !s.isTestDeeper {
+		vval := reflect.ValueOf(expectedValue)
+		switch vval.Kind() {
+		case reflect.Slice, reflect.Array:
+			s.expectedValue = vval
+		default:
+			panic("usage: " + usage)
+		}
+	}
+
+	sk, err := newSortKeys(strKeys)
+	if err != nil {
+		panic(usage + ": " + err.Error())
+	}
+	s.sortKeys = sk
+	return &s
+}
+
+func (s *tdSort) Match(ctx ctxerr.Context, got reflect.Value) *ctxerr.Error {
+	if ok, err := checkSortable(ctx, got); !ok {
+		return err
+	}
+
+	all, idx, err := s.sortKeys.allValues(got)
+	if err != nil {
+		return fieldError(ctx, idx, err)
+	}
+
+	gotCopy, ok := dark.CopyValue(got)
+	if !ok {
+		if ctx.BooleanError {
+			return ctxerr.BooleanError
+		}
+		return ctx.CollectError(&ctxerr.Error{
+			Message: "cannot sort",
+			Summary: ctxerr.NewSummary(got.Type().String() + " items cannot be copied"),
+		})
+	}
+
+	order := make([]int, len(all))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return s.sortKeys.cmp(all[order[i]], all[order[j]]) < 0
+	})
+
+	var sorted reflect.Value
+	if gotCopy.Kind() == reflect.Array {
+		sorted = reflect.New(gotCopy.Type()).Elem()
+	} else if gotCopy.IsNil() {
+		sorted = gotCopy
+	} else {
+		sorted = reflect.MakeSlice(gotCopy.Type(), len(order), len(order))
+	}
+	for i, from := range order {
+		sorted.Index(i).Set(gotCopy.Index(from))
+	}
+
+	return deepValueEqual(ctx.AddFunctionCall("sort"), sorted, s.expectedValue)
+}
+
+func (s *tdSort) String() string {
+	prefix := "sort"
+	if len(s.keys) > 0 {
+		prefix += "(" + keysString(s.keys) + ")"
+	}
+	if s.isTestDeeper {
+		return prefix + ": " + s.expectedValue.Interface().(TestDeep).String()
+	}
+	return prefix + "=" + util.ToString(s.expectedValue.Interface())
+}
@@ -0,0 +1,194 @@
+// Copyright (c) 2019, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package testdeep_test
+
+import (
+	"testing"
+
+	"github.com/maxatome/go-testdeep"
+	"github.com/maxatome/go-testdeep/internal/test"
+)
+
+type sortPerson struct {
+	Name    string
+	Age     int
+	Address *sortAddress
+}
+
+type sortAddress struct {
+	City string
+}
+
+func TestSorted(t *testing.T) {
+	checkOK(t, []int{1, 2, 2, 5}, testdeep.Sorted())
+	checkOK(t, [4]int{5, 2, 2, 1}, testdeep.Sorted("-"))
+	checkOK(t, []string{"a", "b", "c"}, testdeep.Sorted())
+	checkOK(t, []int(nil), testdeep.Sorted())
+	checkOK(t, []int{}, testdeep.Sorted("-"))
+
+	persons := []sortPerson{
+		{Name: "Bob", Age: 42, Address: &sortAddress{City: "Paris"}},
+		{Name: "Alice", Age: 42, Address: &sortAddress{City: "London"}},
+		{Name: "Brian", Age: 24, Address: &sortAddress{City: "Rome"}},
+	}
+	checkOK(t, persons, testdeep.Sorted("-Age"))
+	checkOK(t, persons, testdeep.Sorted("-Age", "-Name"))
+	checkOK(t, []*sortPerson{&persons[1], &persons[0], &persons[2]},
+		testdeep.Sorted("Address.City"))
+	checkOK(t, []interface{}{persons[1], persons[2]}, testdeep.Sorted("Name"))
+
+	checkError(t, []int{1, 3, 2}, testdeep.Sorted(),
+		expectedError{
+			Message:  mustBe("items are not sorted"),
+			Path:     mustBe("DATA"),
+			Got:      mustBe("item #1: 3\nitem #2: 2"),
+			Expected: mustBe("ascending order"),
+		})
+
+	checkError(t, []int{1, 3, 2}, testdeep.Sorted("-"),
+		expectedError{
+			Message:  mustBe("items are not sorted"),
+			Path:     mustBe("DATA"),
+			Got:      mustContain("item #0: 1\nitem #1: 3"),
+			Expected: mustBe("descending order"),
+		})
+
+	checkError(t, persons, testdeep.Sorted("-Age", "Name"),
+		expectedError{
+			Message:  mustBe("items are not sorted"),
+			Path:     mustBe("DATA"),
+			Got:      mustContain(`item #0: (testdeep_test.sortPerson) {`),
+			Expected: mustBe("descending Age, then ascending Name"),
+		})
+
+	checkError(t, []sortPerson{{Name: "Bob"}, {Name: "Alice"}},
+		testdeep.Sorted("Address.City"),
+		expectedError{
+			Message: mustBe("cannot get sort key"),
+			Path:    mustBe("DATA[0]"),
+			Summary: mustContain("field `Address' is nil"),
+		})
+
+	checkError(t, []int{1, 2}, testdeep.Sorted("Name"),
+		expectedError{
+			Message: mustBe("cannot get sort key"),
+			Path:    mustBe("DATA[0]"),
+			Summary: mustContain("it is not a struct and should be"),
+		})
+
+	checkError(t, 12, testdeep.Sorted(),
+		expectedError{
+			Message:  mustBe("bad kind"),
+			Path:     mustBe("DATA"),
+			Got:      mustBe("int"),
+			Expected: mustBe("slice OR array"),
+		})
+
+	checkError(t, nil, testdeep.Sorted(),
+		expectedError{
+			Message:  mustBe("bad kind"),
+			Path:     mustBe("DATA"),
+			Got:      mustBe("nil"),
+			Expected: mustBe("slice OR array"),
+		})
+
+	//
+	// Bad usage
+	test.CheckPanic(t, func() { testdeep.Sorted("Bad-Field") },
+		"Sorted(KEYS...): bad field name `Bad-Field' in FIELDS_PATH")
+
+	//
+	// String
+	test.EqualStr(t, testdeep.Sorted().String(), "Sorted()")
+	test.EqualStr(t, testdeep.Sorted("-Age", "Name").String(),
+		`Sorted("-Age", "Name")`)
+}
+
+func TestSort(t *testing.T) {
+	checkOK(t, []int{3, 1, 2}, testdeep.Sort(nil, []int{1, 2, 3}))
+	checkOK(t, [3]int{3, 1, 2}, testdeep.Sort("-", [3]int{3, 2, 1}))
+	checkOK(t, []int{3, 1, 2}, testdeep.Sort(nil, testdeep.Sorted()))
+	checkOK(t, []int(nil), testdeep.Sort(nil, []int(nil)))
+	checkOK(t, []int{}, testdeep.Sort(nil, []int{}))
+
+	// got is not modified
+	got := []int{3, 1, 2}
+	checkOK(t, got, testdeep.Sort(nil, []int{1, 2, 3}))
+	test.EqualInt(t, got[0], 3)
+
+	persons := []sortPerson{
+		{Name: "Bob", Age: 42},
+		{Name: "Alice", Age: 42},
+		{Name: "Brian", Age: 24},
+	}
+	checkOK(t, persons, testdeep.Sort("Name", []sortPerson{
+		{Name: "Alice", Age: 42},
+		{Name: "Bob", Age: 42},
+		{Name: "Brian", Age: 24},
+	}))
+	// Stable sort
+	checkOK(t, persons, testdeep.Sort("-Age", []sortPerson{
+		{Name: "Bob", Age: 42},
+		{Name: "Alice", Age: 42},
+		{Name: "Brian", Age: 24},
+	}))
+	checkOK(t, persons, testdeep.Sort([]string{"-Age", "Name"},
+		testdeep.Smuggle(func(p []sortPerson) string {
+			return p[0].Name + p[1].Name + p[2].Name
+		}, "AliceBobBrian")))
+
+	checkError(t, []int{3, 1, 2}, testdeep.Sort(nil, []int{3, 2, 1}),
+		expectedError{
+			Message:  mustBe("values differ"),
+			Path:     mustBe("sort(DATA)[0]"),
+			Got:      mustBe("1"),
+			Expected: mustBe("3"),
+		})
+
+	checkError(t, []int{1, 2}, testdeep.Sort("Name", []int{1, 2}),
+		expectedError{
+			Message: mustBe("cannot get sort key"),
+			Path:    mustBe("DATA[0]"),
+			Summary: mustContain("it is not a struct and should be"),
+		})
+
+	checkError(t, []func(){nil}, testdeep.Sort(nil, testdeep.Ignore()),
+		expectedError{
+			Message: mustBe("cannot sort"),
+			Path:    mustBe("DATA"),
+			Summary: mustContain("[]func() items cannot be copied"),
+		})
+
+	checkError(t, "str", testdeep.Sort(nil, []int{}),
+		expectedError{
+			Message:  mustBe("bad kind"),
+			Path:     mustBe("DATA"),
+			Got:      mustBe("string"),
+			Expected: mustBe("slice OR array"),
+		})
+
+	//
+	// Bad usage
+	const usage = "Sort(nil|STRING|[]STRING, TESTDEEP_OPERATOR|SLICE|ARRAY)"
+	test.CheckPanic(t, func() { testdeep.Sort(12, []int{}) }, "usage: "+usage)
+	test.CheckPanic(t, func() { testdeep.Sort(nil, 12) }, "usage: "+usage)
+	test.CheckPanic(t, func() { testdeep.Sort(nil, nil) }, "usage: "+usage)
+	test.CheckPanic(t, func() { testdeep.Sort("Bad-Field", []int{}) },
+		usage+": bad field name `Bad-Field' in FIELDS_PATH")
+
+	//
+	// String
+	test.EqualStr(t, testdeep.Sort(nil, []int{1, 2}).String(),
+		"sort=([]int) (len=2 cap=2) {\n (int) 1,\n (int) 2\n}")
+	test.EqualStr(t, testdeep.Sort([]string{"-Age", "Name"}, testdeep.Ignore()).String(),
+		`sort("-Age", "Name"): Ignore()`)
+}
+
+func TestSortedTypeBehind(t *testing.T) {
+	equalTypes(t, testdeep.Sorted(), nil)
+	equalTypes(t, testdeep.Sort(nil, []int{}), nil)
+}
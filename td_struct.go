@@ -10,7 +10,9 @@ import (
 	"bytes"
 	"fmt"
 	"os"
+	"path"
 	"reflect"
+	"regexp"
 	"sort"
 
 	"github.com/maxatome/go-testdeep/internal/ctxerr"
@@ -21,6 +23,7 @@ import (
 type tdStruct struct {
 	tdExpectedType
 	expectedFields fieldInfoSlice
+	patterns       []fieldPattern
 }
 
 var _ TestDeep = &tdStruct{}
@@ -29,6 +32,14 @@ type fieldInfo struct {
 	name     string
 	expected reflect.Value
 	index    []int
+	pattern  bool // true if expected comes from a pattern
+}
+
+// fieldPattern is a StructFields key matching several fields.
+type fieldPattern struct {
+	key      string
+	expected interface{}
+	match    func(fieldName string) bool
 }
 
 type fieldInfoSlice []fieldInfo
@@ -41,8 +52,62 @@ func (e fieldInfoSlice) Swap(i, j int)      { e[i], e[j] = e[j], e[i] }
 // Struct. It is a map whose each key is the expected field name and
 // the corresponding value the expected field value (which can be a
 // TestDeep operator as well as a zero value.)
+//
+// A key can also be a pattern matching several field names:
+//   - "=GLOB": a shell glob pattern, as accepted by path.Match;
+//   - "~REGEXP": a regexp, as accepted by regexp.Compile.
+//
+// The corresponding expected value then applies to all matching
+// fields:
+//
+//   StructFields{
+//     "=*At":       Ignore(),
+//     "~^Internal": Zero(),
+//   }
+//
+// Fields explicitly named, in keys or by non-zero fields of the
+// model, take precedence over patterns. Patterns are tried in the
+// lexicographic order of their keys, so globs before regexps, and
+// the first one matching a field wins. Each pattern must match at
+// least one field.
 type StructFields map[string]interface{}
 
+// newFieldPattern returns the fieldPattern corresponding to "key" or
+// false if "key" is not a pattern.
+func newFieldPattern(key string, expected interface{}) (fieldPattern, bool) {
+	p := fieldPattern{
+		key:      key,
+		expected: expected,
+	}
+
+	if len(key) == 0 {
+		return p, false
+	}
+
+	switch key[0] {
+	case '=':
+		glob := key[1:]
+		if _, err := path.Match(glob, ""); err != nil {
+			panic(fmt.Sprintf("bad pattern `%s': %s", key, err))
+		}
+		p.match = func(fieldName string) bool {
+			ok, _ := path.Match(glob, fieldName)
+			return ok
+		}
+
+	case '~':
+		re, err := regexp.Compile(key[1:])
+		if err != nil {
+			panic(fmt.Sprintf("bad pattern `%s': %s", key, err))
+		}
+		p.match = re.MatchString
+
+	default:
+		return p, false
+	}
+	return p, true
+}
+
 func newStruct(model interface{}) (*tdStruct, reflect.Value) {
 	vmodel := reflect.ValueOf(model)
 
@@ -83,7 +148,8 @@ func newStruct(model interface{}) (*tdStruct, reflect.Value) {
 // "model" must be the same type as compared data.
 //
 // "expectedFields" can be nil, if no zero entries are expected and
-// no TestDeep operator are involved.
+// no TestDeep operator are involved. Its keys can also be glob or
+// regexp patterns matching several fields, see StructFields.
 //
 // During a match, all expected fields must be found to
 // succeed. Non-expected fields are ignored.
@@ -97,41 +163,20 @@ func Struct(model interface{}, expectedFields StructFields) TestDeep {
 
 	// Check that all given fields are available in model
 	stType := st.expectedType
-	var vexpectedValue reflect.Value
 	for fieldName, expectedValue := range expectedFields {
+		if pattern, ok := newFieldPattern(fieldName, expectedValue); ok {
+			st.patterns = append(st.patterns, pattern)
+			continue
+		}
+
 		field, found := stType.FieldByName(fieldName)
 		if !found {
 			panic(fmt.Sprintf("struct %s has no field `%s'", stType, fieldName))
 		}
 
-		if expectedValue == nil {
-			switch field.Type.Kind() {
-			case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map,
-				reflect.Ptr, reflect.Slice:
-				vexpectedValue = reflect.Zero(field.Type) // change to a typed nil
-			default:
-				panic(fmt.Sprintf(
-					"expected value of field %s cannot be nil as it is a %s",
-					fieldName,
-					field.Type))
-			}
-		} else {
-			vexpectedValue = reflect.ValueOf(expectedValue)
-
-			if _, ok := expectedValue.(TestDeep); !ok {
-				if !vexpectedValue.Type().AssignableTo(field.Type) {
-					panic(fmt.Sprintf(
-						"type %s of field expected value %s differs from struct one (%s)",
-						vexpectedValue.Type(),
-						fieldName,
-						field.Type))
-				}
-			}
-		}
-
 		st.expectedFields = append(st.expectedFields, fieldInfo{
 			name:     fieldName,
-			expected: vexpectedValue,
+			expected: fieldExpectedValue(field, fieldName, expectedValue),
 			index:    field.Index,
 		})
 		checkedFields[fieldName] = true
@@ -177,6 +222,43 @@ func Struct(model interface{}, expectedFields StructFields) TestDeep {
 					expected: vfield,
 					index:    field.Index,
 				})
+				checkedFields[fieldName] = true
+			}
+		}
+	}
+
+	// Apply patterns to remaining fields
+	if len(st.patterns) > 0 {
+		sort.Slice(st.patterns, func(i, j int) bool {
+			return st.patterns[i].key < st.patterns[j].key
+		})
+
+		for _, pattern := range st.patterns {
+			matched := false
+			for _, fieldName := range allFields {
+				field, _ := stType.FieldByName(fieldName)
+				if field.Anonymous || !pattern.match(fieldName) {
+					continue
+				}
+				matched = true
+
+				if checkedFields[fieldName] {
+					continue
+				}
+
+				st.expectedFields = append(st.expectedFields, fieldInfo{
+					name: fieldName,
+					expected: fieldExpectedValue(field,
+						fieldName+" (matched by "+pattern.key+")", pattern.expected),
+					index:   field.Index,
+					pattern: true,
+				})
+				checkedFields[fieldName] = true
+			}
+
+			if !matched {
+				panic(fmt.Sprintf("struct %s has no field matching `%s'",
+					stType, pattern.key))
 			}
 		}
 	}
@@ -186,6 +268,37 @@ func Struct(model interface{}, expectedFields StructFields) TestDeep {
 	return st
 }
 
+// fieldExpectedValue returns "expectedValue" as a reflect.Value
+// suitable to be compared to "field". It panics if it is not
+// possible. "fieldDesc" describes the field in panic messages.
+func fieldExpectedValue(field reflect.StructField, fieldDesc string, expectedValue interface{}) reflect.Value {
+	if expectedValue == nil {
+		switch field.Type.Kind() {
+		case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map,
+			reflect.Ptr, reflect.Slice:
+			return reflect.Zero(field.Type) // change to a typed nil
+		default:
+			panic(fmt.Sprintf(
+				"expected value of field %s cannot be nil as it is a %s",
+				fieldDesc,
+				field.Type))
+		}
+	}
+
+	vexpectedValue := reflect.ValueOf(expectedValue)
+
+	if _, ok := expectedValue.(TestDeep); !ok {
+		if !vexpectedValue.Type().AssignableTo(field.Type) {
+			panic(fmt.Sprintf(
+				"type %s of field expected value %s differs from struct one (%s)",
+				vexpectedValue.Type(),
+				fieldDesc,
+				field.Type))
+		}
+	}
+	return vexpectedValue
+}
+
 func (s *tdStruct) Match(ctx ctxerr.Context, got reflect.Value) (err *ctxerr.Error) {
 	err = s.checkPtr(ctx, &got, false)
 	if err != nil {
@@ -216,14 +329,22 @@ func (s *tdStruct) String() string {
 
 	buf.WriteString(s.expectedType.String())
 
-	if len(s.expectedFields) == 0 {
+	if len(s.expectedFields) == 0 && len(s.patterns) == 0 {
 		buf.WriteString("{})")
 	} else {
 		buf.WriteString("{\n")
 
 		for _, fieldInfo := range s.expectedFields {
-			fmt.Fprintf(buf, "  %s: %s\n", // nolint: errcheck
-				fieldInfo.name, util.ToString(fieldInfo.expected))
+			if !fieldInfo.pattern {
+				fmt.Fprintf(buf, "  %s: %s\n", // nolint: errcheck
+					fieldInfo.name, util.ToString(fieldInfo.expected))
+			}
+		}
+
+		// Patterns, in the order they are applied
+		for _, pattern := range s.patterns {
+			fmt.Fprintf(buf, "  %q: %s\n", // nolint: errcheck
+				pattern.key, util.ToString(pattern.expected))
 		}
 
 		buf.WriteString("})")
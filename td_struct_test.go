@@ -451,6 +451,113 @@ func TestStructPrivateFields(t *testing.T) {
 		})
 }
 
+func TestStructPatterns(t *testing.T) {
+	type Item struct {
+		ID            int
+		Name          string
+		CreatedAt     time.Time
+		UpdatedAt     time.Time
+		InternalCount int
+		InternalState string
+		internalNote  string
+	}
+
+	now := time.Now()
+	got := Item{
+		ID:        42,
+		Name:      "foo",
+		CreatedAt: now,
+		UpdatedAt: now.Add(time.Second),
+	}
+
+	checkOK(t, got,
+		testdeep.Struct(Item{ID: 42}, testdeep.StructFields{
+			"Name":       "foo",
+			"=*At":       testdeep.Gte(now),
+			"~^Internal": testdeep.Zero(),
+		}))
+
+	// Explicit names take precedence over patterns
+	checkOK(t, got,
+		testdeep.Struct(Item{}, testdeep.StructFields{
+			"UpdatedAt": testdeep.Gt(now),
+			"=*At":      now,
+		}))
+	// Globs are tried before regexps
+	checkOK(t, got,
+		testdeep.Struct(Item{ID: 42, Name: "foo"}, testdeep.StructFields{
+			"~.":   testdeep.Zero(),
+			"=*At": testdeep.NotZero(),
+		}))
+
+	// Unexported fields can match too
+	checkOK(t, Item{internalNote: "note"},
+		testdeep.Struct(Item{}, testdeep.StructFields{
+			"~^[a-z]": "note",
+		}))
+
+	checkError(t, got,
+		testdeep.Struct(Item{}, testdeep.StructFields{
+			"~^Internal": testdeep.NotZero(),
+		}),
+		expectedError{
+			Message: mustBe("zero value"),
+			Path:    mustBe("DATA.InternalCount"),
+		})
+
+	checkError(t, got,
+		testdeep.Struct(Item{}, testdeep.StructFields{
+			"=*At": now,
+		}),
+		expectedError{
+			Message: mustBe("values differ"),
+			Path:    mustContain("UpdatedAt"),
+		})
+
+	//
+	// Bad usage
+	test.CheckPanic(t,
+		func() {
+			testdeep.Struct(Item{}, testdeep.StructFields{"=Foo*": 0})
+		},
+		"struct testdeep_test.Item has no field matching `=Foo*'")
+	test.CheckPanic(t,
+		func() {
+			testdeep.Struct(Item{}, testdeep.StructFields{"=[": 0})
+		},
+		"bad pattern `=['")
+	test.CheckPanic(t,
+		func() {
+			testdeep.Struct(Item{}, testdeep.StructFields{"~(": 0})
+		},
+		"bad pattern `~('")
+	test.CheckPanic(t,
+		func() {
+			testdeep.Struct(Item{}, testdeep.StructFields{"=*At": "bad"})
+		},
+		"type string of field expected value CreatedAt (matched by =*At) differs from struct one (time.Time)")
+	test.CheckPanic(t,
+		func() {
+			testdeep.Struct(Item{}, testdeep.StructFields{"~^Internal": nil})
+		},
+		"expected value of field InternalCount (matched by ~^Internal) cannot be nil as it is a int")
+
+	//
+	// String
+	test.EqualStr(t,
+		testdeep.Struct(Item{ID: 42}, testdeep.StructFields{
+			"Name":       "foo",
+			"~^Internal": testdeep.Zero(),
+			"=*At":       testdeep.Ignore(),
+		}).String(),
+		`Struct(testdeep_test.Item{
+  ID: 42
+  Name: "foo"
+  "=*At": Ignore()
+  "~^Internal": Zero()
+})`)
+}
+
 func TestStructTypeBehind(t *testing.T) {
 	equalTypes(t, testdeep.Struct(MyStruct{}, nil), MyStruct{})
 	equalTypes(t, testdeep.Struct(&MyStruct{}, nil), &MyStruct{})
@@ -0,0 +1,228 @@
+// Copyright (c) 2019, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package testdeep
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/maxatome/go-testdeep/internal/ctxerr"
+	"github.com/maxatome/go-testdeep/internal/dark"
+	"github.com/maxatome/go-testdeep/internal/util"
+)
+
+type tdUnique struct {
+	BaseOKNil
+	path   string
+	pathFn func(interface{}) (smuggleValue, error)
+	keyFn  reflect.Value
+}
+
+var _ TestDeep = &tdUnique{}
+
+// Unique operator checks that the items of an array, a slice or a
+// pointer on array/slice are pairwise distinct.
+//
+// Without "keyFn", items are deeply compared. With it, their keys
+// are compared instead. "keyFn" can be:
+//   - a fields-path through structs, as accepted by Smuggle operator;
+//   - a function taking one parameter whose type must be convertible
+//     to the type of items, and returning the key of the item.
+//
+// For example:
+//
+//   Cmp(t, []int{1, 2, 3}, Unique())  // succeeds
+//   Cmp(t, []int{1, 2, 1}, Unique())  // fails
+//   Cmp(t, users, Unique("ID"))       // succeeds if all IDs differ
+//   Cmp(t, users, Unique(func(u User) string {
+//     return strings.ToLower(u.Email)
+//   }))
+//
+// On failure, each group of duplicates is reported with the indexes
+// of its items.
+//
+// When keys are hashable (booleans, numbers, strings, as well as
+// arrays and structs composed of them), duplicates are found using a
+// map. Otherwise keys are compared pairwise.
+func Unique(keyFn ...interface{}) TestDeep {
+	const usage = "Unique([FIELDS_PATH|FUNC])"
+
+	u := tdUnique{
+		BaseOKNil: NewBaseOKNil(3),
+	}
+
+	switch len(keyFn) {
+	case 0:
+	case 1:
+		switch fn := keyFn[0].(type) {
+		case string:
+			pathFn, err := buildStructFieldFn(fn)
+			if err != nil {
+				panic(usage + ": " + err.Error())
+			}
+			u.path = fn
+			u.pathFn = pathFn
+
+		default:
+			vfn := reflect.ValueOf(fn)
+			if vfn.Kind() != reflect.Func {
+				panic("usage: " + usage)
+			}
+			fnType := vfn.Type()
+			if fnType.IsVariadic() || fnType.NumIn() != 1 || fnType.NumOut() != 1 {
+				panic(usage + ": FUNC must take only one argument and return one value")
+			}
+			u.keyFn = vfn
+		}
+
+	default:
+		panic("usage: " + usage)
+	}
+	return &u
+}
+
+// isHashable returns true if values of type "t" can be used as map
+// keys and if map keys equality is the same as deep equality.
+func isHashable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Uintptr, reflect.Float32, reflect.Float64,
+		reflect.Complex64, reflect.Complex128, reflect.String:
+		return true
+
+	case reflect.Array:
+		return isHashable(t.Elem())
+
+	case reflect.Struct:
+		for i, n := 0, t.NumField(); i < n; i++ {
+			if !isHashable(t.Field(i).Type) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// key returns the key of "item". "ctx" is the context of "item". If
+// the key cannot be computed, false and an error are returned.
+func (u *tdUnique) key(ctx ctxerr.Context, item reflect.Value) (reflect.Value, bool, *ctxerr.Error) {
+	switch {
+	case u.pathFn != nil:
+		smv, err := u.pathFn(dark.MustGetInterface(item))
+		if err != nil {
+			if ctx.BooleanError {
+				return reflect.Value{}, false, ctxerr.BooleanError
+			}
+			return reflect.Value{}, false, ctx.CollectError(&ctxerr.Error{
+				Message: "cannot get key",
+				Summary: ctxerr.NewSummary(err.Error()),
+			})
+		}
+		return smv.Value, true, nil
+
+	case u.keyFn.IsValid():
+		return callItemFunc(ctx, u.keyFn, item)
+	}
+	return item, true, nil
+}
+
+func (u *tdUnique) Match(ctx ctxerr.Context, got reflect.Value) *ctxerr.Error {
+	list, ok, err := getArrayOrSlice(ctx, got)
+	if !ok {
+		return err
+	}
+
+	l := list.Len()
+	keys := make([]reflect.Value, l)
+	hashable := true
+	for idx := 0; idx < l; idx++ {
+		key, ok, err := u.key(ctx.AddArrayIndex(idx), list.Index(idx))
+		if !ok {
+			return err
+		}
+		keys[idx] = key
+		if hashable && (!key.IsValid() || !isHashable(key.Type())) {
+			hashable = false
+		}
+	}
+
+	// Each group contains the indexes of equal keys
+	var groups [][]int
+	if hashable {
+		seen := map[interface{}]int{}
+		for idx, key := range keys {
+			k := dark.MustGetInterface(key)
+			if groupIdx, ok := seen[k]; ok {
+				groups[groupIdx] = append(groups[groupIdx], idx)
+				continue
+			}
+			seen[k] = len(groups)
+			groups = append(groups, []int{idx})
+		}
+	} else {
+		grouped := make([]bool, l)
+		for i := 0; i < l; i++ {
+			if grouped[i] {
+				continue
+			}
+			group := []int{i}
+			for j := i + 1; j < l; j++ {
+				if !grouped[j] && deepValueEqualOK(keys[j], keys[i]) {
+					grouped[j] = true
+					group = append(group, j)
+				}
+			}
+			groups = append(groups, group)
+		}
+	}
+
+	var summary ctxerr.ErrorSummaryItems
+	for _, group := range groups {
+		if len(group) < 2 {
+			continue
+		}
+		if ctx.BooleanError {
+			return ctxerr.BooleanError
+		}
+
+		indexes := make([]string, len(group))
+		for i, idx := range group {
+			indexes[i] = strconv.Itoa(idx)
+		}
+		summary = append(summary, ctxerr.ErrorSummaryItem{
+			Label: "indexes " + strings.Join(indexes, ", "),
+			Value: util.ToString(keys[group[0]]),
+		})
+	}
+	if summary == nil {
+		return nil
+	}
+
+	message := "duplicate items"
+	if u.path != "" || u.keyFn.IsValid() {
+		message = "duplicate keys"
+	}
+	return ctx.CollectError(&ctxerr.Error{
+		Message: message,
+		Summary: summary,
+	})
+}
+
+func (u *tdUnique) String() string {
+	switch {
+	case u.path != "":
+		return fmt.Sprintf("Unique(%q)", u.path)
+	case u.keyFn.IsValid():
+		return "Unique(" + u.keyFn.Type().String() + ")"
+	}
+	return "Unique()"
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2019, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package testdeep_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/maxatome/go-testdeep"
+	"github.com/maxatome/go-testdeep/internal/test"
+)
+
+type uniqueUser struct {
+	ID    int
+	Email string
+	Tags  []string
+}
+
+func TestUnique(t *testing.T) {
+	checkOK(t, []int{1, 2, 3}, testdeep.Unique())
+	checkOK(t, [3]string{"a", "b", "c"}, testdeep.Unique())
+	checkOK(t, &[]int{1, 2, 3}, testdeep.Unique())
+	checkOK(t, []int(nil), testdeep.Unique())
+	checkOK(t, [][]int{{1}, {2}, {1, 2}}, testdeep.Unique())
+	checkOK(t, []interface{}{1, "1", []int{1}}, testdeep.Unique())
+
+	users := []uniqueUser{
+		{ID: 1, Email: "bob@example.com"},
+		{ID: 2, Email: "Alice@example.com"},
+		{ID: 3, Email: "alice@example.com"},
+	}
+	checkOK(t, users, testdeep.Unique("ID"))
+	checkOK(t, users, testdeep.Unique("Email"))
+	checkOK(t, users, testdeep.Unique(func(u uniqueUser) int { return u.ID }))
+
+	checkError(t, []int{1, 2, 1, 3, 2, 1}, testdeep.Unique(),
+		expectedError{
+			Message: mustBe("duplicate items"),
+			Path:    mustBe("DATA"),
+			Summary: mustContain("indexes 0, 2, 5: "),
+		})
+	checkError(t, []int{1, 2, 1, 3, 2, 1}, testdeep.Unique(),
+		expectedError{
+			Message: mustBe("duplicate items"),
+			Path:    mustBe("DATA"),
+			Summary: mustContain("indexes 1, 4: "),
+		})
+
+	// Not hashable
+	checkError(t, [][]int{{1}, {2}, {1}}, testdeep.Unique(),
+		expectedError{
+			Message: mustBe("duplicate items"),
+			Path:    mustBe("DATA"),
+			Summary: mustContain("indexes 0, 2: "),
+		})
+	checkError(t, []*int{new(int), new(int)}, testdeep.Unique(),
+		expectedError{
+			Message: mustBe("duplicate items"),
+			Path:    mustBe("DATA"),
+			Summary: mustContain("indexes 0, 1: "),
+		})
+
+	checkError(t, users,
+		testdeep.Unique(func(u uniqueUser) string { return strings.ToLower(u.Email) }),
+		expectedError{
+			Message: mustBe("duplicate keys"),
+			Path:    mustBe("DATA"),
+			Summary: mustContain(`"alice@example.com"`),
+		})
+
+	checkError(t, []uniqueUser{{ID: 1}, {ID: 2}, {ID: 1}}, testdeep.Unique("ID"),
+		expectedError{
+			Message: mustBe("duplicate keys"),
+			Path:    mustBe("DATA"),
+			Summary: mustContain("indexes 0, 2: "),
+		})
+
+	checkError(t, []int{1, 2}, testdeep.Unique("ID"),
+		expectedError{
+			Message: mustBe("cannot get key"),
+			Path:    mustBe("DATA[0]"),
+			Summary: mustContain("it is not a struct and should be"),
+		})
+
+	checkError(t, []interface{}{1, "str"}, testdeep.Unique(func(n int) int { return n }),
+		expectedError{
+			Message:  mustBe("incompatible parameter type"),
+			Path:     mustBe("DATA[1]"),
+			Got:      mustBe("string"),
+			Expected: mustBe("int"),
+		})
+
+	checkError(t, 12, testdeep.Unique(),
+		expectedError{
+			Message:  mustBe("bad type"),
+			Path:     mustBe("DATA"),
+			Got:      mustBe("int"),
+			Expected: mustBe("Slice OR Array OR *Slice OR *Array"),
+		})
+
+	//
+	// Bad usage
+	const usage = "Unique([FIELDS_PATH|FUNC])"
+	test.CheckPanic(t, func() { testdeep.Unique(12) }, "usage: "+usage)
+	test.CheckPanic(t, func() { testdeep.Unique("ID", "Email") }, "usage: "+usage)
+	test.CheckPanic(t, func() { testdeep.Unique("Bad-Field") },
+		usage+": bad field name `Bad-Field' in FIELDS_PATH")
+	test.CheckPanic(t, func() { testdeep.Unique(func(a, b int) int { return 0 }) },
+		usage+": FUNC must take only one argument and return one value")
+	test.CheckPanic(t, func() { testdeep.Unique(func(a int) {}) },
+		usage+": FUNC must take only one argument and return one value")
+
+	//
+	// String
+	test.EqualStr(t, testdeep.Unique().String(), "Unique()")
+	test.EqualStr(t, testdeep.Unique("ID").String(), `Unique("ID")`)
+	test.EqualStr(t, testdeep.Unique(func(u uniqueUser) int { return u.ID }).String(),
+		"Unique(func(testdeep_test.uniqueUser) int)")
+}
+
+func TestUniqueTypeBehind(t *testing.T) {
+	equalTypes(t, testdeep.Unique(), nil)
+}